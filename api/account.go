@@ -2,14 +2,34 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/banking"
 	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
 )
 
+// accountResponse adds the balance rendered with the decimals of the
+// account's currency, e.g. "12.34" for USD and "1234" for JPY
+type accountResponse struct {
+	db.Account
+	FormattedBalance          string `json:"formatted_balance"`
+	FormattedAvailableBalance string `json:"formatted_available_balance"`
+}
+
+func (server *Server) newAccountResponse(account db.Account) accountResponse {
+	return accountResponse{
+		Account:                   account,
+		FormattedBalance:          server.currencies.Format(account.Balance, account.Currency),
+		FormattedAvailableBalance: server.currencies.Format(account.AvailableBalance, account.Currency),
+	}
+}
+
 type createAccountRequest struct {
 	Name     string `json:"name" binding:"required"`
 	Currency string `json:"currency" binding:"required,currency"`
@@ -21,7 +41,8 @@ type createAccountRequest struct {
 //	@Param			account	body	createAccountRequest	true	"Create Account Request"
 //	@Produce		application/json
 //	@Tags			accounts
-//	@Success		200	{object}	db.Account
+//	@Success		200	{object}	accountResponse
+//	@Security		BearerAuth
 //	@Router			/acounts [post]
 func (server *Server) CreateAccount(ctx *gin.Context) {
 	var req createAccountRequest
@@ -30,6 +51,13 @@ func (server *Server) CreateAccount(ctx *gin.Context) {
 		return
 	}
 
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if req.Name != authPayload.Username {
+		err := errors.New("cannot create an account for another user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
 	arg := db.CreateAccountParams{
 		Name:    req.Name,
 		Currency: req.Currency,
@@ -49,7 +77,7 @@ func (server *Server) CreateAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, server.newAccountResponse(account))
 }
 
 type getAccountRequest struct {
@@ -62,7 +90,8 @@ type getAccountRequest struct {
 //	@Param			id	path	getAccountRequest	true	"Account ID"
 //	@Produce		application/json
 //	@Tags			accounts
-//	@Success		200	{object}	db.Account
+//	@Success		200	{object}	accountResponse
+//	@Security		BearerAuth
 //	@Router			/accounts/{id} [get]
 func (server *Server) GetAccount(ctx *gin.Context) {
 	var req getAccountRequest
@@ -71,31 +100,32 @@ func (server *Server) GetAccount(ctx *gin.Context) {
 		return
 	}
 
-	account, err := server.store.GetAccount(ctx, req.ID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	account, ok := server.ownedAccount(ctx, req.ID)
+	if !ok {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, server.newAccountResponse(account))
 }
 
 type getAccountsParams struct {
-	Page  int32 `form:"page" binding:"required,min=1"`
-	Size int32 `form:"size" binding:"required,min=5,max=10"`
+	Cursor string `form:"cursor"`
+	Size   int32  `form:"size" binding:"required,min=1,max=100"`
+}
+
+type listAccountsResponse struct {
+	Data       []accountResponse `json:"data"`
+	NextCursor *string           `json:"next_cursor"`
 }
 
 // GetAccounts		godoc
 //	@Summary		Get a list of accounts
-//	@Description	Get a list of accounts with pagination
+//	@Description	Get a list of the authenticated user's accounts oldest first, pass next_cursor as cursor to get the next page
 //	@Param			pagination	query	getAccountsParams	true	"Pagination"
 //	@Produce		application/json
 //	@Tags			accounts
-//	@Success		200	{object}	[]db.Account
+//	@Success		200	{object}	listAccountsResponse
+//	@Security		BearerAuth
 //	@Router			/accounts [get]
 func (server *Server) GetAccounts(ctx *gin.Context) {
 
@@ -105,9 +135,18 @@ func (server *Server) GetAccounts(ctx *gin.Context) {
 		return
 	}
 
+	cursor, err := util.DecodeCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	arg := db.GetAccountsParams{
-		Limit:  req.Size,
-		Offset: (req.Page - 1) * req.Page,
+		Name:           authPayload.Username,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+		Size:           req.Size + 1,
 	}
 
 	accounts, err := server.store.GetAccounts(ctx, arg)
@@ -116,65 +155,95 @@ func (server *Server) GetAccounts(ctx *gin.Context) {
 		return
 	}
 
-	fmt.Println(accounts)
+	var rsp listAccountsResponse
+	if len(accounts) > int(req.Size) {
+		accounts = accounts[:req.Size]
+		last := accounts[len(accounts)-1]
+		rsp.NextCursor = util.EncodeCursor(last.CreatedAt, last.ID)
+	}
 
-	ctx.JSON(http.StatusOK, accounts)
-}
+	rsp.Data = make([]accountResponse, len(accounts))
+	for i, account := range accounts {
+		rsp.Data[i] = server.newAccountResponse(account)
+	}
 
-type deleteAccountRequest struct {
-	ID int64 `uri:"id" binding:"required,min=1"`
+	ctx.JSON(http.StatusOK, rsp)
 }
 
+type listAllAccountsRequest struct {
+	Cursor string `form:"cursor"`
+	Size   int32  `form:"size" binding:"required,min=1,max=100"`
+	Owner  string `form:"owner" binding:"omitempty,alphanum"`
+	Status string `form:"status" binding:"omitempty,oneof=active frozen closed"`
+}
 
-// DeleteAccount		godoc
-//	@Summary		Delete an account by ID
-//	@Description	Delete an account by the specified ID
-//	@Param			id	path	deleteAccountRequest	true	"Account ID"
+// ListAllAccounts		godoc
+//	@Summary		List the accounts of every user
+//	@Description	List the accounts of every user oldest first, optionally only the accounts of one owner or in one status. Pass next_cursor as cursor to get the next page. Bankers and admins only
+//	@Param			accounts	query	listAllAccountsRequest	true	"List All Accounts Request"
 //	@Produce		application/json
-//	@Tags			accounts
-//	@Success		200	{object}	string
-//	@Router			/accounts/{id} [delete]
-func (server *Server) DeleteAccount(ctx *gin.Context) {
-	var req deleteAccountRequest
-	if err := ctx.ShouldBindUri(&req); err != nil {
+//	@Tags			admin
+//	@Success		200	{object}	listAccountsResponse
+//	@Security		BearerAuth
+//	@Router			/admin/accounts [get]
+func (server *Server) ListAllAccounts(ctx *gin.Context) {
+	var req listAllAccountsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	_, err := server.store.GetAccount(ctx, req.ID)
+	cursor, err := util.DecodeCursor(req.Cursor)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	err = server.store.DeleteAccount(ctx, req.ID)
+	arg := db.ListAllAccountsParams{
+		Name:           util.NullString(req.Owner),
+		Status:         util.NullString(req.Status),
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+		Size:           req.Size + 1,
+	}
+
+	accounts, err := server.store.ListAllAccounts(ctx, arg)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
+	var rsp listAccountsResponse
+	if len(accounts) > int(req.Size) {
+		accounts = accounts[:req.Size]
+		last := accounts[len(accounts)-1]
+		rsp.NextCursor = util.EncodeCursor(last.CreatedAt, last.ID)
+	}
 
+	rsp.Data = make([]accountResponse, len(accounts))
+	for i, account := range accounts {
+		rsp.Data[i] = server.newAccountResponse(account)
+	}
 
-	ctx.JSON(http.StatusOK, gin.H{"success": "Account deleted successfully!"})
+	ctx.JSON(http.StatusOK, rsp)
 }
 
 type depositRequest struct {
 	ID int64 `json:"id" binding:"required,min=1"`
 	Amount int64 `json:"amount" binding:"required,gt=0"`
+	Currency string `json:"currency" binding:"required,currency"`
 }
 
 
 // Deposit		godoc
 //	@Summary		Deposit money to an account
-//	@Description	Deposit money to an account by the specified ID
-//	@Param			account	body	depositRequest	true	"Deposit Request"
+//	@Description	Deposit money to an account by the specified ID, retries with the same Idempotency-Key replay the first response
+//	@Param			account			body	depositRequest	true	"Deposit Request"
+//	@Param			Idempotency-Key	header	string			false	"Idempotency Key"
 //	@Produce		application/json
 //	@Tags			accounts
-//	@Success		200	{object}	db.Account
+//	@Success		200	{object}	db.DepositTxResult
+//	@Security		BearerAuth
 //	@Router			/accounts/deposit [post]
 func (server *Server) Deposit(ctx *gin.Context) {
 	var req depositRequest
@@ -183,16 +252,168 @@ func (server *Server) Deposit(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.AddAccountBalanceParams{
-		ID: req.ID,
-		Amount: req.Amount,
+	idempotency, err := idempotencyParams(ctx, req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if server.replayIdempotentResponse(ctx, idempotency) {
+		return
+	}
+
+	account, ok := server.ownedAccount(ctx, req.ID)
+	if !ok {
+		return
+	}
+
+	if account.Currency != req.Currency {
+		err := fmt.Errorf("account %d has different currency", req.ID)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.DepositTxParams{
+		AccountID:   req.ID,
+		Amount:      req.Amount,
+		Idempotency: idempotency,
 	}
 
-	account, err := server.store.AddAccountBalance(ctx, arg)
+	result, err := server.store.DepositTx(ctx, arg)
 	if err != nil {
+		if isIdempotencyConflict(err) && server.replayIdempotentResponse(ctx, idempotency) {
+			return
+		}
+		if accountStateError(ctx, err) {
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
-}
\ No newline at end of file
+	ctx.JSON(http.StatusOK, result)
+}
+
+type withdrawRequest struct {
+	ID int64 `json:"id" binding:"required,min=1"`
+	Amount int64 `json:"amount" binding:"required,gt=0"`
+	Currency string `json:"currency" binding:"required,currency"`
+}
+
+// Withdraw		godoc
+//	@Summary		Withdraw money from an account
+//	@Description	Withdraw money from an account by the specified ID, retries with the same Idempotency-Key replay the first response. Fails with 422 and code insufficient_funds when the balance plus overdraft limit does not cover the amount
+//	@Param			account			body	withdrawRequest	true	"Withdraw Request"
+//	@Param			Idempotency-Key	header	string			false	"Idempotency Key"
+//	@Produce		application/json
+//	@Tags			accounts
+//	@Success		200	{object}	db.WithdrawTxResult
+//	@Security		BearerAuth
+//	@Router			/accounts/withdraw [post]
+func (server *Server) Withdraw(ctx *gin.Context) {
+	var req withdrawRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	idempotency, err := idempotencyParams(ctx, req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if server.replayIdempotentResponse(ctx, idempotency) {
+		return
+	}
+
+	account, ok := server.ownedAccount(ctx, req.ID)
+	if !ok {
+		return
+	}
+
+	if account.Currency != req.Currency {
+		err := fmt.Errorf("account %d has different currency", req.ID)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.WithdrawTxParams{
+		AccountID:   req.ID,
+		Amount:      req.Amount,
+		Idempotency: idempotency,
+	}
+
+	result, err := server.store.WithdrawTx(ctx, arg)
+	if err != nil {
+		if isIdempotencyConflict(err) && server.replayIdempotentResponse(ctx, idempotency) {
+			return
+		}
+		if accountStateError(ctx, err) {
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// ownedAccount loads the account and checks that it belongs to the
+// authenticated user, writing the error response when it does not
+func (server *Server) ownedAccount(ctx *gin.Context, accountID int64) (db.Account, bool) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	account, err := banking.OwnedAccount(ctx, server.store, authPayload.Username, accountID)
+	if err != nil {
+		bankingError(ctx, err)
+		return account, false
+	}
+
+	return account, true
+}
+
+// bankingError writes the response for the errors of the banking checks, a
+// missing row is not found and anything else is internal
+func bankingError(ctx *gin.Context, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	case errors.Is(err, banking.ErrAccountNotOwned),
+		errors.Is(err, banking.ErrTransferNotOwned),
+		errors.Is(err, banking.ErrQuoteNotOwned):
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+	case errors.Is(err, banking.ErrQuoteCurrencies):
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	case errors.Is(err, banking.ErrQuoteExpired):
+		ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(err, errCodeQuoteExpired))
+	case errors.Is(err, banking.ErrExchangeRateUnavailable):
+		ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(err, errCodeExchangeRateUnavailable))
+	default:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+}
+
+// accountStateError writes the 422 response for the errors returned when the
+// balance or status of an account does not allow the operation, it returns
+// false for any other error
+func accountStateError(ctx *gin.Context, err error) bool {
+	var code string
+	switch {
+	case errors.Is(err, db.ErrInsufficientFunds):
+		code = errCodeInsufficientFunds
+	case errors.Is(err, db.ErrAccountFrozen):
+		code = errCodeAccountFrozen
+	case errors.Is(err, db.ErrAccountClosed):
+		code = errCodeAccountClosed
+	case errors.Is(err, db.ErrNonZeroBalance):
+		code = errCodeNonZeroBalance
+	case errors.Is(err, db.ErrHeldBalance):
+		code = errCodeHeldBalance
+	default:
+		return false
+	}
+
+	ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(err, code))
+	return true
+}
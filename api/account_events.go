@@ -0,0 +1,84 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/stream"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultHeartbeatInterval is used when no SSE heartbeat is configured
+const defaultHeartbeatInterval = 15 * time.Second
+
+// AccountEvents streams the events the store transactions raise for an
+// account, see stream.Broker
+type AccountEvents interface {
+	Subscribe(accountID int64) (<-chan stream.Message, func())
+}
+
+type accountEventsUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// StreamAccountEvents godoc
+//	@Summary		Stream account events
+//	@Description	Server-sent events for an account, one account.balance_changed event with the updated account whenever a transfer, deposit or withdrawal touches it and account.frozen when it is frozen. The id and data of an event are the id and JSON of the outbox event. A ping event is sent every heartbeat interval, the stream ends when the access token expires and clients reconnect with a renewed one
+//	@Param			id	path	int	true	"Account ID"
+//	@Produce		text/event-stream
+//	@Tags			accounts
+//	@Success		200	{string}	string	"event stream"
+//	@Security		BearerAuth
+//	@Router			/accounts/{id}/events [get]
+func (server *Server) StreamAccountEvents(ctx *gin.Context) {
+	var uri accountEventsUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.ownedAccount(ctx, uri.ID); !ok {
+		return
+	}
+
+	messages, unsubscribe := server.events.Subscribe(uri.ID)
+	defer unsubscribe()
+
+	interval := server.config.SSEHeartbeatInterval
+	if interval <= 0 {
+		interval = defaultHeartbeatInterval
+	}
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+
+	// the connection is only as good as the token that opened it
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	expired := time.NewTimer(time.Until(authPayload.ExpiredAt))
+	defer expired.Stop()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-expired.C:
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(ctx.Writer, "id: %s\nevent: %s\ndata: %s\n\n", message.ID, message.Type, message.Data)
+		case now := <-heartbeat.C:
+			fmt.Fprintf(ctx.Writer, "event: ping\ndata: {\"time\":%q}\n\n", now.UTC().Format(time.RFC3339))
+		}
+		ctx.Writer.Flush()
+	}
+}
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/dbtest"
+	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/stream"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAccountEvents hands every subscriber the same channel
+type fakeAccountEvents struct {
+	messages   chan stream.Message
+	subscribed []int64
+}
+
+func (events *fakeAccountEvents) Subscribe(accountID int64) (<-chan stream.Message, func()) {
+	events.subscribed = append(events.subscribed, accountID)
+	return events.messages, func() {}
+}
+
+func TestStreamAccountEventsAPI(t *testing.T) {
+	user, _ := dbtest.RandomUser(t)
+	account := dbtest.RandomAccount(user.Username)
+
+	message := stream.Message{
+		ID:   "7b0a3c62-6b8e-4d4f-9a57-1d4f0bbf3a61",
+		Type: db.EventAccountBalanceChanged,
+		Data: json.RawMessage(fmt.Sprintf(`{"type":"account.balance_changed","aggregate_id":%d}`, account.ID)),
+	}
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		messages      []stream.Message
+		closeStream   bool
+		heartbeat     time.Duration
+		timeout       time.Duration
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, events *fakeAccountEvents)
+	}{
+		{
+			name:        "OK",
+			accountID:   account.ID,
+			messages:    []stream.Message{message},
+			closeStream: true,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, events *fakeAccountEvents) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/event-stream", recorder.Header().Get("Content-Type"))
+				require.Equal(t, []int64{account.ID}, events.subscribed)
+
+				expected := fmt.Sprintf("id: %s\nevent: %s\ndata: %s\n\n", message.ID, message.Type, message.Data)
+				require.Equal(t, expected, recorder.Body.String())
+			},
+		},
+		{
+			name:      "Heartbeat",
+			accountID: account.ID,
+			heartbeat: 10 * time.Millisecond,
+			timeout:   100 * time.Millisecond,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, events *fakeAccountEvents) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "event: ping\ndata: {\"time\":")
+			},
+		},
+		{
+			name:      "TokenExpired",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, 200*time.Millisecond)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, events *fakeAccountEvents) {
+				// the stream ends on its own before the request times out
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, recorder.Body.String())
+			},
+		},
+		{
+			name:      "Forbidden",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized_user", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, events *fakeAccountEvents) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Empty(t, events.subscribed)
+			},
+		},
+		{
+			name:      "NotFound",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, events *fakeAccountEvents) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Empty(t, events.subscribed)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, events *fakeAccountEvents) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidID",
+			accountID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, events *fakeAccountEvents) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			events := &fakeAccountEvents{messages: make(chan stream.Message, len(tc.messages))}
+			for _, message := range tc.messages {
+				events.messages <- message
+			}
+			if tc.closeStream {
+				close(events.messages)
+			}
+
+			server := newTestServer(t, store)
+			server.events = events
+			if tc.heartbeat > 0 {
+				server.config.SSEHeartbeatInterval = tc.heartbeat
+			}
+
+			// keep a broken stream from hanging the test
+			timeout := tc.timeout
+			if timeout == 0 {
+				timeout = 5 * time.Second
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			url := fmt.Sprintf("/api/v1/accounts/%d/events", tc.accountID)
+			request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, events)
+		})
+	}
+}
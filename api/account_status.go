@@ -0,0 +1,201 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	errCodeAccountFrozen  = "account_frozen"
+	errCodeAccountClosed  = "account_closed"
+	errCodeNonZeroBalance = "non_zero_balance"
+	errCodeHeldBalance    = "held_balance"
+)
+
+var errSweepToSameAccount = errors.New("cannot sweep an account into itself")
+
+type accountStatusUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type updateOverdraftLimitRequest struct {
+	OverdraftLimit *int64 `json:"overdraft_limit" binding:"required,min=0"`
+}
+
+type closeAccountRequest struct {
+	SweepToAccountID int64 `json:"sweep_to_account_id" binding:"omitempty,min=1"`
+}
+
+type closeAccountResponse struct {
+	Account accountResponse      `json:"account"`
+	Sweep   *db.TransferTxResult `json:"sweep,omitempty"`
+}
+
+// CloseAccount godoc
+//	@Summary		Close an account
+//	@Description	Close an account for good, the balance must be zero or is moved to sweep_to_account_id, which must use the same currency. Fails with 422 and code non_zero_balance, held_balance (pending transfers still hold money), account_frozen or account_closed when the account cannot be closed
+//	@Param			id		path	int					true	"Account ID"
+//	@Param			account	body	closeAccountRequest	false	"Close Account Request"
+//	@Produce		application/json
+//	@Tags			accounts
+//	@Success		200	{object}	closeAccountResponse
+//	@Security		BearerAuth
+//	@Router			/accounts/{id}/close [post]
+func (server *Server) CloseAccount(ctx *gin.Context) {
+	var uri accountStatusUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// the body is optional when there is nothing to sweep
+	var req closeAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.SweepToAccountID == uri.ID {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errSweepToSameAccount))
+		return
+	}
+
+	account, ok := server.ownedAccount(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	if req.SweepToAccountID != 0 {
+		if _, valid := server.validAccount(ctx, req.SweepToAccountID, account.Currency); !valid {
+			return
+		}
+	}
+
+	result, err := server.store.CloseAccountTx(ctx, db.CloseAccountTxParams{
+		AccountID:        uri.ID,
+		SweepToAccountID: req.SweepToAccountID,
+	})
+	if err != nil {
+		if accountStateError(ctx, err) {
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, closeAccountResponse{
+		Account: server.newAccountResponse(result.Account),
+		Sweep:   result.Sweep,
+	})
+}
+
+// FreezeAccount godoc
+//	@Summary		Freeze an account
+//	@Description	Stop all money movement in and out of an account until it is unfrozen, bankers and admins only. Fails with 422 and code account_closed for closed accounts
+//	@Param			id	path	int	true	"Account ID"
+//	@Produce		application/json
+//	@Tags			admin
+//	@Success		200	{object}	accountResponse
+//	@Security		BearerAuth
+//	@Router			/admin/accounts/{id}/freeze [post]
+func (server *Server) FreezeAccount(ctx *gin.Context) {
+	server.updateAccountStatus(ctx, db.AccountStatusFrozen)
+}
+
+// UnfreezeAccount godoc
+//	@Summary		Unfreeze an account
+//	@Description	Make a frozen account active again, bankers and admins only. Fails with 422 and code account_closed for closed accounts
+//	@Param			id	path	int	true	"Account ID"
+//	@Produce		application/json
+//	@Tags			admin
+//	@Success		200	{object}	accountResponse
+//	@Security		BearerAuth
+//	@Router			/admin/accounts/{id}/unfreeze [post]
+func (server *Server) UnfreezeAccount(ctx *gin.Context) {
+	server.updateAccountStatus(ctx, db.AccountStatusActive)
+}
+
+// updateAccountStatus moves an account between active and frozen, closed
+// accounts stay closed
+func (server *Server) updateAccountStatus(ctx *gin.Context, status string) {
+	var uri accountStatusUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.existingAccount(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	if account.Status == db.AccountStatusClosed {
+		accountStateError(ctx, db.ErrAccountClosed)
+		return
+	}
+
+	if account.Status != status {
+		var err error
+		account, err = server.store.UpdateAccountStatusTx(ctx, db.UpdateAccountStatusParams{
+			ID:     uri.ID,
+			Status: status,
+		})
+		if err != nil {
+			// closed after the check above
+			if accountStateError(ctx, err) {
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, server.newAccountResponse(account))
+}
+
+// UpdateOverdraftLimit godoc
+//	@Summary		Set the overdraft limit of an account
+//	@Description	Set how far below zero the available balance of an account may go, admins only, bankers can freeze accounts but not change their limit. Fails with 422 and code account_closed for closed accounts
+//	@Param			id		path	int							true	"Account ID"
+//	@Param			account	body	updateOverdraftLimitRequest	true	"Update Overdraft Limit Request"
+//	@Produce		application/json
+//	@Tags			admin
+//	@Success		200	{object}	accountResponse
+//	@Security		BearerAuth
+//	@Router			/admin/accounts/{id}/overdraft_limit [patch]
+func (server *Server) UpdateOverdraftLimit(ctx *gin.Context) {
+	var uri accountStatusUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateOverdraftLimitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.UpdateAccountOverdraftLimitTx(ctx, db.UpdateAccountOverdraftLimitParams{
+		ID:             uri.ID,
+		OverdraftLimit: *req.OverdraftLimit,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		if accountStateError(ctx, err) {
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.newAccountResponse(account))
+}
@@ -9,35 +9,34 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/dbtest"
 	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
 	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
 	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
 
-func randomAccount() db.Account  {
-	return db.Account {
-		ID: util.RandomInt(1, 1000),
-		Name: util.RandomName(),
-		Balance: util.RandomAmount(),
-		Currency: util.RandomCurrency(),
-	}
-}
-
 func TestCreateAccountAPI(t *testing.T) {
-	account := randomAccount()
+	user, _ := dbtest.RandomUser(t)
+	account := dbtest.RandomAccount(user.Username)
 
 	testCases := []struct {
 		name string
 		body string
+		setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
 			body: fmt.Sprintf(`{"name":"%s","currency":"%s"}`, account.Name, account.Currency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 
 				arg := db.CreateAccountParams{
@@ -58,8 +57,11 @@ func TestCreateAccountAPI(t *testing.T) {
 		{
 			name: "Internal Error",
 			body: fmt.Sprintf(`{"name":"%s","currency":"%s"}`, account.Name, account.Currency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
-				
+
 				arg := db.CreateAccountParams{
 					Name: account.Name,
 					Currency: account.Currency,
@@ -77,6 +79,9 @@ func TestCreateAccountAPI(t *testing.T) {
 		{
 			name: "Invalid Currency",
 			body: fmt.Sprintf(`{"name":"%s","currency":"%s"}`, account.Name, "invalid"),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					CreateAccount(gomock.Any(), gomock.Any()).
@@ -89,6 +94,9 @@ func TestCreateAccountAPI(t *testing.T) {
 		{
 			name: "Invalid Name",
 			body: fmt.Sprintf(`{"name":"%s","currency":"%s"}`, "", account.Currency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					CreateAccount(gomock.Any(), gomock.Any()).
@@ -98,6 +106,34 @@ func TestCreateAccountAPI(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name: "Unauthorized User",
+			body: fmt.Sprintf(`{"name":"%s","currency":"%s"}`, account.Name, account.Currency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized_user", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "No Authorization",
+			body: fmt.Sprintf(`{"name":"%s","currency":"%s"}`, account.Name, account.Currency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -109,13 +145,14 @@ func TestCreateAccountAPI(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
-			server := NewServer(store)
+			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
 
 			url := "/api/v1/accounts"
 			request, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(tc.body))
 			require.NoError(t, err)
 
+			tc.setupAuth(t, request, server.tokenMaker)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -123,17 +160,22 @@ func TestCreateAccountAPI(t *testing.T) {
 }
 
 func TestGetAccountAPI(t *testing.T) {
-	account := randomAccount()
+	user, _ := dbtest.RandomUser(t)
+	account := dbtest.RandomAccount(user.Username)
 
 	testCases := []struct {
 		name string
 		accountID int64
+		setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
 			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
@@ -145,9 +187,41 @@ func TestGetAccountAPI(t *testing.T) {
 				requireBodyMatchAccount(t, recorder.Body, account)
 			},
 		},
+		{
+			name: "Unauthorized User",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized_user", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "No Authorization",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 		{
 			name: "Not Found",
 			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
@@ -161,6 +235,9 @@ func TestGetAccountAPI(t *testing.T) {
 		{
 			name: "Internal Error",
 			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
@@ -174,6 +251,9 @@ func TestGetAccountAPI(t *testing.T) {
 		{
 			name: "Invalid ID",
 			accountID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Any()).
@@ -194,106 +274,199 @@ func TestGetAccountAPI(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
-			server := NewServer(store)
+			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
 
 			url := fmt.Sprintf("/api/v1/accounts/%d", tc.accountID)
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
 
+			tc.setupAuth(t, request, server.tokenMaker)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
 	}
 }
 
-func TestDeleteAccountAPI(t *testing.T) {
-	account := randomAccount()
+func TestGetAccountsAPI(t *testing.T) {
+	user, _ := dbtest.RandomUser(t)
+
+	n := 5
+	accounts := make([]db.Account, n)
+	for i := range accounts {
+		accounts[i] = dbtest.RandomAccount(user.Username)
+	}
+
+	cursorTime := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	type Query struct {
+		Cursor string
+		Size   int
+	}
 
 	testCases := []struct {
-		name string
-		accountID int64
-		buildStubs func(store *mockdb.MockStore)
-		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+		name          string
+		query         Query
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recoder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
-			accountID: account.ID,
+			query: Query{
+				Size: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.GetAccountsParams{
+					Name: user.Username,
+					Size: int32(n) + 1,
+				}
+
 				store.EXPECT().
-					DeleteAccount(gomock.Any(), gomock.Eq(account.ID)).
+					GetAccounts(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(nil).After(
-						store.EXPECT().
-							GetAccount(gomock.Any(), gomock.Eq(account.ID)).
-							Times(1).
-							Return(account, nil),
-					)
+					Return(accounts, nil)
 			},
-			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Len(t, accounts, n)
+				requireBodyMatchAccounts(t, recorder.Body, accounts, nil)
 			},
 		},
 		{
-			name: "Invalid ID",
-			accountID: 0,
+			name: "Next Cursor",
+			query: Query{
+				Size: n - 1,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.GetAccountsParams{
+					Name: user.Username,
+					Size: int32(n),
+				}
+
 				store.EXPECT().
-					DeleteAccount(gomock.Any(), gomock.Any()).
+					GetAccounts(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(accounts, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				last := accounts[n-2]
+				requireBodyMatchAccounts(t, recorder.Body, accounts[:n-1], util.EncodeCursor(last.CreatedAt, last.ID))
+			},
+		},
+		{
+			name: "With Cursor",
+			query: Query{
+				Cursor: *util.EncodeCursor(cursorTime, accounts[0].ID),
+				Size:   n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.GetAccountsParams{
+					Name:           user.Username,
+					AfterCreatedAt: cursorTime,
+					AfterID:        accounts[0].ID,
+					Size:           int32(n) + 1,
+				}
+
+				store.EXPECT().
+					GetAccounts(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(accounts[1:], nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccounts(t, recorder.Body, accounts[1:], nil)
+			},
+		},
+		{
+			name: "No Authorization",
+			query: Query{
+				Size: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccounts(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
-			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
 		{
 			name: "Internal Error",
-			accountID: account.ID,
+			query: Query{
+				Size: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.GetAccountsParams{
+					Name: user.Username,
+					Size: int32(n) + 1,
+				}
+
 				store.EXPECT().
-					DeleteAccount(gomock.Any(), gomock.Eq(account.ID)).
+					GetAccounts(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(sql.ErrConnDone).After(
-						store.EXPECT().
-							GetAccount(gomock.Any(), gomock.Eq(account.ID)).
-							Times(1).
-							Return(account, nil),
-					)
+					Return([]db.Account{}, sql.ErrConnDone)
+
 			},
-			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
 		{
-			name: "Not Found",
-			accountID: account.ID,
+			name: "Invalid Cursor",
+			query: Query{
+				Cursor: "not-a-cursor",
+				Size:   n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
-					Times(1).
-					Return(db.Account{}, sql.ErrNoRows)
+					GetAccounts(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
-			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
-			name: "Internal Error - GetAccount Error",
-			accountID: account.ID,
+			name: "Invalid Size",
+			query: Query{
+				Size: 0,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
-					Times(1).
-					Return(db.Account{}, sql.ErrConnDone)
+					GetAccounts(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
-			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 	}
 
 	for i := range testCases {
 		tc := testCases[i]
+
 		t.Run(tc.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
@@ -301,109 +474,288 @@ func TestDeleteAccountAPI(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
-			server := NewServer(store)
+			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
 
-			url := fmt.Sprintf("/api/v1/accounts/%d", tc.accountID)
-			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			url := "/api/v1/accounts"
+			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
 
+			q := request.URL.Query()
+			if tc.query.Cursor != "" {
+				q.Add("cursor", tc.query.Cursor)
+			}
+			q.Add("size", fmt.Sprintf("%d", tc.query.Size))
+			request.URL.RawQuery = q.Encode()
+
+			tc.setupAuth(t, request, server.tokenMaker)
 			server.router.ServeHTTP(recorder, request)
-			tc.checkResponse(t, recorder)
+			tc.checkResponse(recorder)
 		})
 	}
+
 }
 
-func TestGetAccountsAPI(t *testing.T) {
-	n := 5
-	accounts := make([]db.Account, n)
+func TestListAllAccountsAPI(t *testing.T) {
+	accounts := make([]db.Account, 3)
 	for i := range accounts {
-		accounts[i] = randomAccount()
-	}
-
-	type Query struct {
-		Page   int
-		Size   int
+		user, _ := dbtest.RandomUser(t)
+		accounts[i] = dbtest.RandomAccount(user.Username)
 	}
 
 	testCases := []struct {
 		name          string
-		query         Query
+		query         string
+		username      string
 		buildStubs    func(store *mockdb.MockStore)
-		checkResponse func(recoder *httptest.ResponseRecorder)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Banker",
+			query:    "size=3",
+			username: testBankerUsername,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAllAccountsParams{Size: 4}
+				store.EXPECT().ListAllAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accounts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccounts(t, recorder.Body, accounts, nil)
+			},
+		},
+		{
+			name:     "AdminFiltered",
+			query:    fmt.Sprintf("size=2&owner=%s&status=frozen", accounts[0].Name),
+			username: testAdminUsername,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAllAccountsParams{
+					Name:   sql.NullString{String: accounts[0].Name, Valid: true},
+					Status: sql.NullString{String: db.AccountStatusFrozen, Valid: true},
+					Size:   3,
+				}
+				store.EXPECT().ListAllAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accounts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				last := accounts[1]
+				requireBodyMatchAccounts(t, recorder.Body, accounts[:2], util.EncodeCursor(last.CreatedAt, last.ID))
+			},
+		},
+		{
+			name:     "Customer",
+			query:    "size=3",
+			username: accounts[0].Name,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAllAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidStatus",
+			query:    "size=3&status=deleted",
+			username: testBankerUsername,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAllAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			query:    "size=3",
+			username: testBankerUsername,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAllAccounts(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := "/api/v1/admin/accounts?" + tc.query
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDepositAPI(t *testing.T) {
+	user, _ := dbtest.RandomUser(t)
+	account := dbtest.RandomAccount(user.Username)
+	amount := util.RandomInt(1, 1000)
+	entry := createRandomEntry(account)
+	otherCurrency := util.USD
+	if account.Currency == util.USD {
+		otherCurrency = util.EUR
+	}
+
+	testCases := []struct {
+		name string
+		body string
+		setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
-			query: Query{
-				Page: 1,
-				Size: n,
+			body: fmt.Sprintf(`{"id":%d,"amount":%d,"currency":"%s"}`, account.ID, amount, account.Currency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.GetAccountsParams{
-					Limit: int32(n),
-					Offset: 0,
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				arg := db.DepositTxParams{
+					AccountID: account.ID,
+					Amount: amount,
 				}
 
 				store.EXPECT().
-					GetAccounts(gomock.Any(), gomock.Eq(arg)).
+					DepositTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(accounts, nil)
+					Return(db.DepositTxResult{Account: account, Entry: entry}, nil)
 			},
-			checkResponse: func(recorder *httptest.ResponseRecorder) {
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				require.Len(t, accounts, n)
-				requireBodyMatchAccounts(t, recorder.Body, accounts)
+				requireBodyMatchDepositResult(t, recorder.Body, db.DepositTxResult{Account: account, Entry: entry})
+			},
+		},
+		{
+			name: "Unauthorized User",
+			body: fmt.Sprintf(`{"id":%d,"amount":%d,"currency":"%s"}`, account.ID, amount, account.Currency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized_user", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
 			},
 		},
 		{
 			name: "Internal Error",
-			query: Query{
-				Page: 1,
-				Size: n,
+			body: fmt.Sprintf(`{"id":%d,"amount":%d,"currency":"%s"}`, account.ID, amount, account.Currency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.GetAccountsParams{
-					Limit: int32(n),
-					Offset: 0,
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				arg := db.DepositTxParams{
+					AccountID: account.ID,
+					Amount: amount,
 				}
 
 				store.EXPECT().
-					GetAccounts(gomock.Any(), gomock.Eq(arg)).
+					DepositTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return([]db.Account{}, sql.ErrConnDone)
-			
+					Return(db.DepositTxResult{}, sql.ErrConnDone)
 			},
-			checkResponse: func(recorder *httptest.ResponseRecorder) {
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
 		{
-			name: "Invalid Page",
-			query: Query{
-				Page: 0,
-				Size: n,
+			name: "Frozen Account",
+			body: fmt.Sprintf(`{"id":%d,"amount":%d,"currency":"%s"}`, account.ID, amount, account.Currency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccounts(gomock.Any(), gomock.Any()).
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DepositTxResult{}, db.ErrAccountFrozen)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireErrorCode(t, recorder.Body, errCodeAccountFrozen)
+			},
+		},
+		{
+			name: "Currency Mismatch",
+			body: fmt.Sprintf(`{"id":%d,"amount":%d,"currency":"%s"}`, account.ID, amount, otherCurrency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
-			checkResponse: func(recorder *httptest.ResponseRecorder) {
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
-			name: "Invalid Size",
-			query: Query{
-				Page: 1,
-				Size: 0,
+			name: "Invalid ID",
+			body: fmt.Sprintf(`{"id":%d,"amount":%d,"currency":"%s"}`, 0, amount, account.Currency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					GetAccounts(gomock.Any(), gomock.Any()).
+					DepositTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
-			checkResponse: func(recorder *httptest.ResponseRecorder) {
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Amount",
+			body: fmt.Sprintf(`{"id":%d,"amount":%d,"currency":"%s"}`, account.ID, -1, account.Currency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DepositTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
@@ -411,7 +763,6 @@ func TestGetAccountsAPI(t *testing.T) {
 
 	for i := range testCases {
 		tc := testCases[i]
-
 		t.Run(tc.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
@@ -419,78 +770,180 @@ func TestGetAccountsAPI(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
-			server := NewServer(store)
+			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
 
-			url := "/api/v1/accounts"
-			request, err := http.NewRequest(http.MethodGet, url, nil)
+			url := "/api/v1/accounts/deposit"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(tc.body))
 			require.NoError(t, err)
 
-			q := request.URL.Query()
-			q.Add("page", fmt.Sprintf("%d", tc.query.Page))
-			q.Add("size", fmt.Sprintf("%d", tc.query.Size))
-			request.URL.RawQuery = q.Encode()
-
+			tc.setupAuth(t, request, server.tokenMaker)
 			server.router.ServeHTTP(recorder, request)
-			tc.checkResponse(recorder)
+			tc.checkResponse(t, recorder)
 		})
 	}
-
 }
 
-func TestDepositAPI(t *testing.T) {
-	account := randomAccount()
-	amount := util.RandomAmount()
+
+func TestWithdrawAPI(t *testing.T) {
+	user, _ := dbtest.RandomUser(t)
+	account := dbtest.RandomAccount(user.Username)
+	amount := util.RandomInt(1, 1000)
+	entry := createRandomEntry(account)
+	otherCurrency := util.USD
+	if account.Currency == util.USD {
+		otherCurrency = util.EUR
+	}
 
 	testCases := []struct {
 		name string
 		body string
+		setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
-			body: fmt.Sprintf(`{"id":%d,"amount":%d}`, account.ID, amount),
+			body: fmt.Sprintf(`{"id":%d,"amount":%d,"currency":"%s"}`, account.ID, amount, account.Currency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.AddAccountBalanceParams{
-					ID: account.ID,
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				arg := db.WithdrawTxParams{
+					AccountID: account.ID,
 					Amount: amount,
 				}
 
 				store.EXPECT().
-					AddAccountBalance(gomock.Any(), gomock.Eq(arg)).
+					WithdrawTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(account, nil)
+					Return(db.WithdrawTxResult{Account: account, Entry: entry}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchAccount(t, recorder.Body, account)
+				requireBodyMatchWithdrawResult(t, recorder.Body, db.WithdrawTxResult{Account: account, Entry: entry})
+			},
+		},
+		{
+			name: "Unauthorized User",
+			body: fmt.Sprintf(`{"id":%d,"amount":%d,"currency":"%s"}`, account.ID, amount, account.Currency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized_user", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
 			},
 		},
 		{
 			name: "Internal Error",
-			body: fmt.Sprintf(`{"id":%d,"amount":%d}`, account.ID, amount),
+			body: fmt.Sprintf(`{"id":%d,"amount":%d,"currency":"%s"}`, account.ID, amount, account.Currency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.AddAccountBalanceParams{
-					ID: account.ID,
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				arg := db.WithdrawTxParams{
+					AccountID: account.ID,
 					Amount: amount,
 				}
 
 				store.EXPECT().
-					AddAccountBalance(gomock.Any(), gomock.Eq(arg)).
+					WithdrawTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(db.Account{}, sql.ErrConnDone)
+					Return(db.WithdrawTxResult{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "Insufficient Funds",
+			body: fmt.Sprintf(`{"id":%d,"amount":%d,"currency":"%s"}`, account.ID, amount, account.Currency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.WithdrawTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireErrorCode(t, recorder.Body, errCodeInsufficientFunds)
+			},
+		},
+		{
+			name: "Closed Account",
+			body: fmt.Sprintf(`{"id":%d,"amount":%d,"currency":"%s"}`, account.ID, amount, account.Currency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.WithdrawTxResult{}, db.ErrAccountClosed)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireErrorCode(t, recorder.Body, errCodeAccountClosed)
+			},
+		},
+		{
+			name: "Currency Mismatch",
+			body: fmt.Sprintf(`{"id":%d,"amount":%d,"currency":"%s"}`, account.ID, amount, otherCurrency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					WithdrawTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name: "Invalid ID",
-			body: fmt.Sprintf(`{"id":%d,"amount":%d}`, 0, amount),
+			body: fmt.Sprintf(`{"id":%d,"amount":%d,"currency":"%s"}`, 0, amount, account.Currency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					AddAccountBalance(gomock.Any(), gomock.Any()).
+					WithdrawTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -499,10 +952,13 @@ func TestDepositAPI(t *testing.T) {
 		},
 		{
 			name: "Invalid Amount",
-			body: fmt.Sprintf(`{"id":%d,"amount":%d}`, account.ID, -1),
+			body: fmt.Sprintf(`{"id":%d,"amount":%d,"currency":"%s"}`, account.ID, -1, account.Currency),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					AddAccountBalance(gomock.Any(), gomock.Any()).
+					WithdrawTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -520,13 +976,14 @@ func TestDepositAPI(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
-			server := NewServer(store)
+			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
 
-			url := "/api/v1/accounts/deposit"
+			url := "/api/v1/accounts/withdraw"
 			request, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(tc.body))
 			require.NoError(t, err)
 
+			tc.setupAuth(t, request, server.tokenMaker)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -545,12 +1002,43 @@ func requireBodyMatchAccount(t *testing.T, body *bytes.Buffer, account db.Accoun
 }
 
 
-func requireBodyMatchAccounts(t *testing.T, body *bytes.Buffer, accounts []db.Account) {
+func requireBodyMatchAccounts(t *testing.T, body *bytes.Buffer, accounts []db.Account, nextCursor *string) {
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
 
-	var gotAccounts []db.Account
-	err = json.Unmarshal(data, &gotAccounts)
+	var gotPage struct {
+		Data       []db.Account `json:"data"`
+		NextCursor *string      `json:"next_cursor"`
+	}
+	err = json.Unmarshal(data, &gotPage)
 	require.NoError(t, err)
-	require.Equal(t, accounts, gotAccounts)
-}
\ No newline at end of file
+	require.Equal(t, accounts, gotPage.Data)
+	require.Equal(t, nextCursor, gotPage.NextCursor)
+}
+
+func requireBodyMatchDepositResult(t *testing.T, body *bytes.Buffer, result db.DepositTxResult) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotResult db.DepositTxResult
+	err = json.Unmarshal(data, &gotResult)
+	require.NoError(t, err)
+	require.Equal(t, result, gotResult)
+}
+
+func requireBodyMatchWithdrawResult(t *testing.T, body *bytes.Buffer, result db.WithdrawTxResult) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotResult db.WithdrawTxResult
+	err = json.Unmarshal(data, &gotResult)
+	require.NoError(t, err)
+	require.Equal(t, result, gotResult)
+}
+
+func requireErrorCode(t *testing.T, body *bytes.Buffer, code string) {
+	var gotBody map[string]string
+	err := json.Unmarshal(body.Bytes(), &gotBody)
+	require.NoError(t, err)
+	require.Equal(t, code, gotBody["code"])
+}
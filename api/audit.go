@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditLog appends records to the hash-chained audit log, see
+// db.SQLStore.AuditTx
+type AuditLog interface {
+	AuditTx(ctx context.Context, record db.AuditRecord) error
+}
+
+// auditedMethods are the methods that change state, only their requests
+// are written to the audit log
+var auditedMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// auditRequest is the after snapshot of an audited request. The body is left
+// out on purpose, it can hold passwords and tokens, the store transactions
+// record the rows the request changed
+type auditRequest struct {
+	Status int               `json:"status"`
+	Params map[string]string `json:"params"`
+}
+
+// auditMiddleware tags the request context with the caller and the request
+// id so the store transactions record their changes as theirs, then appends
+// one row for the request itself once it is handled. It has to run after
+// authMiddleware to know the caller and after loggerMiddleware to know the
+// request id
+func (server *Server) auditMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetString(requestIDKey)
+
+		actor := db.AuditActorAnonymous
+		if payload, ok := ctx.Get(authorizationPayloadKey); ok {
+			actor = payload.(*token.Payload).Username
+		}
+
+		audit := db.AuditContext{Actor: actor, RequestID: requestID}
+		ctx.Request = ctx.Request.WithContext(db.WithAuditContext(ctx.Request.Context(), audit))
+
+		ctx.Next()
+
+		if !auditedMethods[ctx.Request.Method] {
+			return
+		}
+
+		var targetIDs []int64
+		params := make(map[string]string, len(ctx.Params))
+		for _, param := range ctx.Params {
+			params[param.Key] = param.Value
+			if id, err := strconv.ParseInt(param.Value, 10, 64); err == nil {
+				targetIDs = append(targetIDs, id)
+			}
+		}
+
+		record := db.AuditRecord{
+			Actor:     actor,
+			Action:    ctx.Request.Method + " " + ctx.FullPath(),
+			TargetIDs: targetIDs,
+			RequestID: requestID,
+			After:     auditRequest{Status: ctx.Writer.Status(), Params: params},
+		}
+
+		// the response is already written, a client that hung up must not
+		// keep the request out of the log
+		auditCtx := context.WithoutCancel(ctx.Request.Context())
+		if err := server.audit.AuditTx(auditCtx, record); err != nil {
+			db.LoggerFrom(auditCtx).Error("cannot write audit log", "error", err)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuditLog keeps the records instead of writing them
+type fakeAuditLog struct {
+	records []db.AuditRecord
+	err     error
+}
+
+func (audit *fakeAuditLog) AuditTx(ctx context.Context, record db.AuditRecord) error {
+	audit.records = append(audit.records, record)
+	return audit.err
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		requestID     string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		auditErr      error
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, audit *fakeAuditLog, seen db.AuditContext)
+	}{
+		{
+			name:      "OK",
+			method:    http.MethodPost,
+			url:       "/api/v1/audited/42",
+			requestID: "req-1",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "user", time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, audit *fakeAuditLog, seen db.AuditContext) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Equal(t, "req-1", recorder.Header().Get(requestIDHeader))
+				require.Equal(t, db.AuditContext{Actor: "user", RequestID: "req-1"}, seen)
+
+				require.Len(t, audit.records, 1)
+				record := audit.records[0]
+				require.Equal(t, "user", record.Actor)
+				require.Equal(t, "POST /api/v1/audited/:id", record.Action)
+				require.Equal(t, []int64{42}, record.TargetIDs)
+				require.Equal(t, "req-1", record.RequestID)
+				require.Equal(t, auditRequest{
+					Status: http.StatusCreated,
+					Params: map[string]string{"id": "42"},
+				}, record.After)
+			},
+		},
+		{
+			name:   "Anonymous",
+			method: http.MethodPost,
+			url:    "/api/v1/audited/abc",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, audit *fakeAuditLog, seen db.AuditContext) {
+				requestID := recorder.Header().Get(requestIDHeader)
+				require.NotEmpty(t, requestID)
+				require.Equal(t, db.AuditContext{Actor: db.AuditActorAnonymous, RequestID: requestID}, seen)
+
+				require.Len(t, audit.records, 1)
+				require.Equal(t, db.AuditActorAnonymous, audit.records[0].Actor)
+				require.Equal(t, requestID, audit.records[0].RequestID)
+				require.Empty(t, audit.records[0].TargetIDs)
+			},
+		},
+		{
+			name:      "RequestIDTooLong",
+			method:    http.MethodPost,
+			url:       "/api/v1/audited/1",
+			requestID: string(make([]byte, maxRequestIDLength+1)),
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, audit *fakeAuditLog, seen db.AuditContext) {
+				requestID := recorder.Header().Get(requestIDHeader)
+				require.Len(t, requestID, 36)
+				require.Equal(t, requestID, audit.records[0].RequestID)
+			},
+		},
+		{
+			name:      "ReadOnly",
+			method:    http.MethodGet,
+			url:       "/api/v1/audited/42",
+			requestID: "req-2",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, audit *fakeAuditLog, seen db.AuditContext) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "req-2", seen.RequestID)
+				require.Empty(t, audit.records)
+			},
+		},
+		{
+			name:     "AuditError",
+			method:   http.MethodDelete,
+			url:      "/api/v1/audited/42",
+			auditErr: errors.New("audit log is down"),
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, audit *fakeAuditLog, seen db.AuditContext) {
+				// the response is already out when the row is written
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+				require.Len(t, audit.records, 1)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := newTestServer(t, mockdb.NewMockStore(ctrl))
+			audit := &fakeAuditLog{err: tc.auditErr}
+			server.audit = audit
+
+			// the handler reads the audit context the way a store transaction does
+			var seen db.AuditContext
+			handler := func(ctx *gin.Context) {
+				seen = db.AuditContextFrom(ctx)
+				switch ctx.Request.Method {
+				case http.MethodPost:
+					ctx.Status(http.StatusCreated)
+				case http.MethodDelete:
+					ctx.Status(http.StatusNoContent)
+				default:
+					ctx.Status(http.StatusOK)
+				}
+			}
+			route := server.router.Group("/api/v1").Use(func(ctx *gin.Context) {
+				// authenticate only when a token was sent
+				if ctx.GetHeader(authorizationHeaderKey) != "" {
+					authMiddleware(server.tokenMaker)(ctx)
+				}
+			}, server.auditMiddleware())
+			route.Handle(tc.method, "/audited/:id", handler)
+
+			request, err := http.NewRequest(tc.method, tc.url, nil)
+			require.NoError(t, err)
+			if tc.requestID != "" {
+				request.Header.Set(requestIDHeader, tc.requestID)
+			}
+			if tc.setupAuth != nil {
+				tc.setupAuth(t, request, server.tokenMaker)
+			}
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, audit, seen)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// ListCurrencies godoc
+//	@Summary		List currencies
+//	@Description	List every currency in the registry including the disabled ones, admin only
+//	@Produce		application/json
+//	@Tags			admin
+//	@Success		200	{object}	[]db.Currency
+//	@Security		BearerAuth
+//	@Router			/admin/currencies [get]
+func (server *Server) ListCurrencies(ctx *gin.Context) {
+	currencies, err := server.store.ListCurrencies(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, currencies)
+}
+
+type createCurrencyRequest struct {
+	Code        string `json:"code" binding:"required,len=3,uppercase"`
+	NumericCode int32  `json:"numeric_code" binding:"required,min=1,max=999"`
+	Exponent    int32  `json:"exponent" binding:"min=0,max=4"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// CreateCurrency godoc
+//	@Summary		Add a currency
+//	@Description	Add an ISO 4217 currency to the registry, admin only
+//	@Param			currency	body	createCurrencyRequest	true	"Create Currency Request"
+//	@Produce		application/json
+//	@Tags			admin
+//	@Success		200	{object}	db.Currency
+//	@Security		BearerAuth
+//	@Router			/admin/currencies [post]
+func (server *Server) CreateCurrency(ctx *gin.Context) {
+	var req createCurrencyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateCurrencyParams{
+		Code:        req.Code,
+		NumericCode: req.NumericCode,
+		Exponent:    req.Exponent,
+		Enabled:     req.Enabled,
+	}
+
+	currency, err := server.store.CreateCurrency(ctx, arg)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.currencies.Invalidate()
+
+	ctx.JSON(http.StatusOK, currency)
+}
+
+type updateCurrencyUri struct {
+	Code string `uri:"code" binding:"required,len=3,uppercase"`
+}
+
+type updateCurrencyRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// UpdateCurrency godoc
+//	@Summary		Enable or disable a currency
+//	@Description	Enable or disable a currency without a redeploy, admin only
+//	@Param			code		path	string					true	"Currency Code"
+//	@Param			currency	body	updateCurrencyRequest	true	"Update Currency Request"
+//	@Produce		application/json
+//	@Tags			admin
+//	@Success		200	{object}	db.Currency
+//	@Security		BearerAuth
+//	@Router			/admin/currencies/{code} [patch]
+func (server *Server) UpdateCurrency(ctx *gin.Context) {
+	var uri updateCurrencyUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateCurrencyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.UpdateCurrencyEnabledParams{
+		Code:    uri.Code,
+		Enabled: *req.Enabled,
+	}
+
+	currency, err := server.store.UpdateCurrencyEnabled(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.currencies.Invalidate()
+
+	ctx.JSON(http.StatusOK, currency)
+}
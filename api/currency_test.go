@@ -0,0 +1,218 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/dbtest"
+	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateCurrencyAPI(t *testing.T) {
+	currency := db.Currency{Code: util.JPY, NumericCode: 392, Exponent: 0, Enabled: true}
+
+	testCases := []struct {
+		name          string
+		code          string
+		body          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rec *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			code:     util.JPY,
+			body:     `{"enabled": true}`,
+			username: testAdminUsername,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpdateCurrencyEnabledParams{Code: util.JPY, Enabled: true}
+				store.EXPECT().UpdateCurrencyEnabled(gomock.Any(), gomock.Eq(arg)).Times(1).Return(currency, nil)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			name:     "NotAdmin",
+			code:     util.JPY,
+			body:     `{"enabled": true}`,
+			username: "customer",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			name:     "MissingEnabled",
+			code:     util.JPY,
+			body:     `{}`,
+			username: testAdminUsername,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name:     "InvalidCode",
+			code:     "jpy",
+			body:     `{"enabled": true}`,
+			username: testAdminUsername,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			code:     "XXX",
+			body:     `{"enabled": true}`,
+			username: testAdminUsername,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).Times(1).Return(db.Currency{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, rec.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			rec := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/v1/admin/currencies/%s", tc.code)
+			req, err := http.NewRequest(http.MethodPatch, url, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(rec, req)
+			tc.checkResponse(t, rec)
+		})
+	}
+}
+
+func TestCreateCurrencyAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currency := db.Currency{Code: "CHF", NumericCode: 756, Exponent: 2, Enabled: true}
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateCurrency(gomock.Any(), gomock.Eq(db.CreateCurrencyParams{Code: "CHF", NumericCode: 756, Exponent: 2, Enabled: true})).
+		Times(1).
+		Return(currency, nil)
+
+	server := newTestServer(t, store)
+	rec := httptest.NewRecorder()
+
+	body := `{"code": "CHF", "numeric_code": 756, "exponent": 2, "enabled": true}`
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/admin/currencies", bytes.NewBufferString(body))
+	require.NoError(t, err)
+
+	addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, testAdminUsername, time.Minute)
+	server.router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestCurrencyRegistryValidation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user, _ := dbtest.RandomUser(t)
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{Name: user.Username, Currency: util.JPY}, nil)
+
+	server := newTestServer(t, store)
+
+	createAccount := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+
+		body := fmt.Sprintf(`{"name": "%s", "currency": "%s"}`, user.Username, util.JPY)
+		req, err := http.NewRequest(http.MethodPost, "/api/v1/accounts", bytes.NewBufferString(body))
+		require.NoError(t, err)
+
+		addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+		server.router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// JPY is seeded disabled
+	require.Equal(t, http.StatusBadRequest, createAccount().Code)
+
+	currencies := []util.Currency{{Code: util.JPY, NumericCode: 392, Exponent: 0, Enabled: true}}
+	dbtest.SetCurrencies(t, testCurrencies, currencies)
+
+	rec := createAccount()
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
+
+func TestFormattedAmounts(t *testing.T) {
+	testCases := []struct {
+		currency string
+		balance  int64
+		expected string
+	}{
+		{currency: util.USD, balance: 123456, expected: "1234.56"},
+		{currency: util.JPY, balance: 123456, expected: "123456"},
+		{currency: util.BHD, balance: 123456, expected: "123.456"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.currency, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			user, _ := dbtest.RandomUser(t)
+			account := dbtest.RandomAccount(user.Username)
+			account.Currency = tc.currency
+			account.Balance = tc.balance
+			account.AvailableBalance = tc.balance
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+			server := newTestServer(t, store)
+			rec := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/accounts/%d", account.ID), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var got accountResponse
+			err = json.Unmarshal(rec.Body.Bytes(), &got)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, got.FormattedBalance)
+			require.Equal(t, tc.expected, got.FormattedAvailableBalance)
+		})
+	}
+}
@@ -2,24 +2,45 @@ package api
 
 import (
 	"net/http"
+	"time"
 
 	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
 	"github.com/gin-gonic/gin"
 )
 
+// entryResponse adds the amount rendered with the decimals of the
+// account's currency
+type entryResponse struct {
+	db.Entry
+	FormattedAmount string `json:"formatted_amount"`
+}
+
 type getEntriesByAccountRequest struct {
-	Id   int64 `form:"id" binding:"required,min=1"`
-	Page int32 `form:"page" binding:"required,min=1"`
-	Size int32 `form:"size" binding:"required,min=1,max=10"`
+	Id             int64      `form:"id" binding:"required,min=1"`
+	Cursor         string     `form:"cursor"`
+	Size           int32      `form:"size" binding:"required,min=1,max=100"`
+	From           *time.Time `form:"from" swaggertype:"string" format:"date-time"`
+	To             *time.Time `form:"to" swaggertype:"string" format:"date-time"`
+	MinAmount      *int64     `form:"min_amount" binding:"omitempty,min=1"`
+	MaxAmount      *int64     `form:"max_amount" binding:"omitempty,min=1"`
+	Direction      string     `form:"direction" binding:"omitempty,oneof=credit debit"`
+	CounterpartyID *int64     `form:"counterparty_id" binding:"omitempty,min=1,nefield=Id"`
+}
+
+type listEntriesResponse struct {
+	Data       []entryResponse `json:"data"`
+	NextCursor *string         `json:"next_cursor"`
 }
 
 // GetEntriesByAccount godoc
 //	@Summary		Get a list of entries by account
-//	@Description	Get a list of entries by account with pagination
+//	@Description	Get a list of entries by account oldest first, pass next_cursor as cursor to get the next page. Entries can be filtered by created_at between from and to, the absolute amount between min_amount and max_amount, direction (credit or debit) and the counterparty account of the transfer that wrote them
 //	@Param			entries	query	getEntriesByAccountRequest	true	"Entries"
 //	@Produce		application/json
 //	@Tags			entries
-//	@Success		200	{object}	[]db.Entry
+//	@Success		200	{object}	listEntriesResponse
+//	@Security		BearerAuth
 //	@Router			/entry [get]
 func (server *Server) GetEntriesByAccount(ctx *gin.Context) {
 	var req getEntriesByAccountRequest
@@ -28,17 +49,55 @@ func (server *Server) GetEntriesByAccount(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.GetEntriesParams{
-		AccountID: req.Id,
-		Limit:     req.Size,
-		Offset:    (req.Page - 1) * req.Page,
+	cursor, err := util.DecodeCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := util.ValidateRange(req.From, req.To, req.MinAmount, req.MaxAmount); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
 	}
 
-	entries, err := server.store.GetEntries(ctx, arg)
+	account, ok := server.ownedAccount(ctx, req.Id)
+	if !ok {
+		return
+	}
+
+	arg := db.ListEntriesParams{
+		AccountID:      req.Id,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+		FromTime:       util.NullTime(req.From),
+		ToTime:         util.NullTime(req.To),
+		MinAmount:      util.NullInt64(req.MinAmount),
+		MaxAmount:      util.NullInt64(req.MaxAmount),
+		Direction:      util.NullString(req.Direction),
+		CounterpartyID: util.NullInt64(req.CounterpartyID),
+		Size:           req.Size + 1,
+	}
+
+	entries, err := server.store.ListEntries(ctx, arg)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, entries)
-}
\ No newline at end of file
+	var rsp listEntriesResponse
+	if len(entries) > int(req.Size) {
+		entries = entries[:req.Size]
+		last := entries[len(entries)-1]
+		rsp.NextCursor = util.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	rsp.Data = make([]entryResponse, len(entries))
+	for i, entry := range entries {
+		rsp.Data[i] = entryResponse{
+			Entry:           entry,
+			FormattedAmount: server.currencies.Format(entry.Amount, account.Currency),
+		}
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -9,9 +9,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/dbtest"
 	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
 	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
 	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
@@ -26,24 +29,29 @@ func createRandomEntry(account db.Account) db.Entry {
 }
 
 func TestGetEntriesByAccountAPI(t *testing.T) {
-	
+
 	n := 10
-	account := randomAccount()
+	user, _ := dbtest.RandomUser(t)
+	account := dbtest.RandomAccount(user.Username)
 
 	entries := make([]db.Entry, n)
 	for i := 0; i < n; i++ {
 		entries[i] = createRandomEntry(account)
 	}
 
+	cursorTime := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+
 	type Query struct {
 		Id int64
-		Page int32
+		Cursor string
 		Size int32
+		Filters string
 	}
 
 	testCases := []struct {
 		name string
 		query Query
+		setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, rec *httptest.ResponseRecorder)
 	}{
@@ -51,42 +59,189 @@ func TestGetEntriesByAccountAPI(t *testing.T) {
 			name: "OK",
 			query: Query{
 				Id: account.ID,
-				Page: 1,
 				Size: int32(n),
 			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.GetEntriesParams{
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				arg := db.ListEntriesParams{
 					AccountID: account.ID,
-					Limit: int32(n),
-					Offset: 0,
+					Size: int32(n) + 1,
 				}
 
 				store.EXPECT().
-					GetEntries(gomock.Any(), arg).
+					ListEntries(gomock.Any(), arg).
 					Times(1).
 					Return(entries, nil)
 			},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, rec.Code)
-				requireBodyMatchEntries(t, rec.Body, entries)
+				requireBodyMatchEntries(t, rec.Body, entries, nil)
+			},
+		},
+		{
+			name: "NextCursor",
+			query: Query{
+				Id: account.ID,
+				Size: int32(n - 1),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				arg := db.ListEntriesParams{
+					AccountID: account.ID,
+					Size: int32(n),
+				}
+
+				store.EXPECT().
+					ListEntries(gomock.Any(), arg).
+					Times(1).
+					Return(entries, nil)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+				last := entries[n-2]
+				requireBodyMatchEntries(t, rec.Body, entries[:n-1], util.EncodeCursor(last.CreatedAt, last.ID))
+			},
+		},
+		{
+			name: "WithCursor",
+			query: Query{
+				Id: account.ID,
+				Cursor: *util.EncodeCursor(cursorTime, entries[0].ID),
+				Size: int32(n),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				arg := db.ListEntriesParams{
+					AccountID: account.ID,
+					AfterCreatedAt: cursorTime,
+					AfterID: entries[0].ID,
+					Size: int32(n) + 1,
+				}
+
+				store.EXPECT().
+					ListEntries(gomock.Any(), arg).
+					Times(1).
+					Return(entries[1:], nil)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+				requireBodyMatchEntries(t, rec.Body, entries[1:], nil)
+			},
+		},
+		{
+			name: "Filters",
+			query: Query{
+				Id: account.ID,
+				Size: int32(n),
+				Filters: "&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z&min_amount=10&max_amount=500&direction=debit&counterparty_id=7",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				arg := db.ListEntriesParams{
+					AccountID: account.ID,
+					FromTime: sql.NullTime{Time: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+					ToTime: sql.NullTime{Time: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+					MinAmount: sql.NullInt64{Int64: 10, Valid: true},
+					MaxAmount: sql.NullInt64{Int64: 500, Valid: true},
+					Direction: sql.NullString{String: "debit", Valid: true},
+					CounterpartyID: sql.NullInt64{Int64: 7, Valid: true},
+					Size: int32(n) + 1,
+				}
+
+				store.EXPECT().
+					ListEntries(gomock.Any(), arg).
+					Times(1).
+					Return(entries, nil)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+				requireBodyMatchEntries(t, rec.Body, entries, nil)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			query: Query{
+				Id: account.ID,
+				Size: int32(n),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized_user", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			query: Query{
+				Id: account.ID,
+				Size: int32(n),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, rec.Code)
+			},
+		},
+		{
+			name: "AccountNotFound",
+			query: Query{
+				Id: account.ID,
+				Size: int32(n),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, rec.Code)
 			},
 		},
 		{
 			name: "InternalError",
 			query: Query{
 				Id: account.ID,
-				Page: 1,
 				Size: int32(n),
 			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.GetEntriesParams{
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				arg := db.ListEntriesParams{
 					AccountID: account.ID,
-					Limit: int32(n),
-					Offset: 0,
+					Size: int32(n) + 1,
 				}
 
 				store.EXPECT().
-					GetEntries(gomock.Any(), arg).
+					ListEntries(gomock.Any(), arg).
 					Times(1).
 					Return([]db.Entry{}, sql.ErrConnDone)
 			},
@@ -98,21 +253,26 @@ func TestGetEntriesByAccountAPI(t *testing.T) {
 			name: "InvalidID",
 			query: Query{
 				Id: 0,
-				Page: 1,
 				Size: int32(n),
 			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, rec.Code)
 			},
 		},
 		{
-			name: "InvalidPage",
+			name: "InvalidCursor",
 			query: Query{
 				Id: account.ID,
-				Page: 0,
+				Cursor: "not-a-cursor",
 				Size: int32(n),
 			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, rec.Code)
@@ -122,14 +282,84 @@ func TestGetEntriesByAccountAPI(t *testing.T) {
 			name: "InvalidSize",
 			query: Query{
 				Id: account.ID,
-				Page: 1,
 				Size: 0,
 			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, rec.Code)
 			},
 		},
+		{
+			name: "InvalidDirection",
+			query: Query{
+				Id: account.ID,
+				Size: int32(n),
+				Filters: "&direction=incoming",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name: "InvalidTimeRange",
+			query: Query{
+				Id: account.ID,
+				Size: int32(n),
+				Filters: "&from=2024-02-01T00:00:00Z&to=2024-01-01T00:00:00Z",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name: "InvalidAmountRange",
+			query: Query{
+				Id: account.ID,
+				Size: int32(n),
+				Filters: "&min_amount=500&max_amount=10",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name: "SameCounterparty",
+			query: Query{
+				Id: account.ID,
+				Size: int32(n),
+				Filters: fmt.Sprintf("&counterparty_id=%d", account.ID),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -141,13 +371,14 @@ func TestGetEntriesByAccountAPI(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
-			server := NewServer(store)
+			server := newTestServer(t, store)
 			rec := httptest.NewRecorder()
 
-			url := fmt.Sprintf("/api/v1/entry?id=%d&page=%d&size=%d", tc.query.Id, tc.query.Page, tc.query.Size)
+			url := fmt.Sprintf("/api/v1/entry?id=%d&cursor=%s&size=%d%s", tc.query.Id, tc.query.Cursor, tc.query.Size, tc.query.Filters)
 			req, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
 
+			tc.setupAuth(t, req, server.tokenMaker)
 			server.router.ServeHTTP(rec, req)
 			tc.checkResponse(t, rec)
 		})
@@ -156,12 +387,16 @@ func TestGetEntriesByAccountAPI(t *testing.T) {
 }
 
 
-func requireBodyMatchEntries(t *testing.T, body *bytes.Buffer, entries []db.Entry) {
+func requireBodyMatchEntries(t *testing.T, body *bytes.Buffer, entries []db.Entry, nextCursor *string) {
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
 
-	var gotEntries []db.Entry
-	err = json.Unmarshal(data, &gotEntries)
+	var gotPage struct {
+		Data       []db.Entry `json:"data"`
+		NextCursor *string    `json:"next_cursor"`
+	}
+	err = json.Unmarshal(data, &gotPage)
 	require.NoError(t, err)
-	require.Equal(t, entries, gotEntries)
-}
\ No newline at end of file
+	require.Equal(t, entries, gotPage.Data)
+	require.Equal(t, nextCursor, gotPage.NextCursor)
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/gin-gonic/gin"
+)
+
+type upsertExchangeRateRequest struct {
+	FromCurrency string `json:"from_currency" binding:"required,currency"`
+	ToCurrency   string `json:"to_currency" binding:"required,currency,nefield=FromCurrency"`
+	Rate         string `json:"rate" binding:"required"`
+	SpreadBps    int32  `json:"spread_bps" binding:"min=0,max=9999"`
+}
+
+// UpsertExchangeRate godoc
+//	@Summary		Set an exchange rate
+//	@Description	Create or replace the rate used to convert from one currency to another, admin only
+//	@Param			rate	body	upsertExchangeRateRequest	true	"Exchange Rate Request"
+//	@Produce		application/json
+//	@Tags			admin
+//	@Success		200	{object}	db.ExchangeRate
+//	@Security		BearerAuth
+//	@Router			/admin/exchange_rates [put]
+func (server *Server) UpsertExchangeRate(ctx *gin.Context) {
+	var req upsertExchangeRateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, err := util.ParseRate(req.Rate); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.UpsertExchangeRateParams{
+		FromCurrency: req.FromCurrency,
+		ToCurrency:   req.ToCurrency,
+		Rate:         req.Rate,
+		SpreadBps:    req.SpreadBps,
+	}
+
+	rate, err := server.store.UpsertExchangeRate(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rate)
+}
+
+// ListExchangeRates godoc
+//	@Summary		List exchange rates
+//	@Description	List every configured exchange rate, admin only
+//	@Produce		application/json
+//	@Tags			admin
+//	@Success		200	{object}	[]db.ExchangeRate
+//	@Security		BearerAuth
+//	@Router			/admin/exchange_rates [get]
+func (server *Server) ListExchangeRates(ctx *gin.Context) {
+	rates, err := server.store.ListExchangeRates(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rates)
+}
+
+type deleteExchangeRateRequest struct {
+	FromCurrency string `uri:"from" binding:"required,currency"`
+	ToCurrency   string `uri:"to" binding:"required,currency"`
+}
+
+// DeleteExchangeRate godoc
+//	@Summary		Delete an exchange rate
+//	@Description	Remove the rate between two currencies so transfers between them are rejected, admin only
+//	@Param			from	path	string	true	"From Currency"
+//	@Param			to		path	string	true	"To Currency"
+//	@Produce		application/json
+//	@Tags			admin
+//	@Success		200	{object}	string
+//	@Security		BearerAuth
+//	@Router			/admin/exchange_rates/{from}/{to} [delete]
+func (server *Server) DeleteExchangeRate(ctx *gin.Context) {
+	var req deleteExchangeRateRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.GetExchangeRateParams{
+		FromCurrency: req.FromCurrency,
+		ToCurrency:   req.ToCurrency,
+	}
+
+	if _, err := server.store.GetExchangeRate(ctx, arg); err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := server.store.DeleteExchangeRate(ctx, db.DeleteExchangeRateParams(arg)); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"success": "Exchange rate deleted successfully!"})
+}
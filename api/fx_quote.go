@@ -0,0 +1,126 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/banking"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	errCodeExchangeRateUnavailable = "exchange_rate_unavailable"
+	errCodeQuoteExpired            = "quote_expired"
+)
+
+type createFxQuoteRequest struct {
+	FromCurrency string `json:"from_currency" binding:"required,currency"`
+	ToCurrency   string `json:"to_currency" binding:"required,currency,nefield=FromCurrency"`
+	Amount       int64  `json:"amount" binding:"required,gt=0"`
+}
+
+type fxQuoteResponse struct {
+	ID                uuid.UUID `json:"id"`
+	FromCurrency      string    `json:"from_currency"`
+	ToCurrency        string    `json:"to_currency"`
+	Rate              string    `json:"rate"`
+	SpreadBps         int32     `json:"spread_bps"`
+	Amount            int64     `json:"amount"`
+	ToAmount          int64     `json:"to_amount"`
+	FormattedAmount   string    `json:"formatted_amount"`
+	FormattedToAmount string    `json:"formatted_to_amount"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// CreateFxQuote godoc
+//	@Summary		Quote a currency conversion
+//	@Description	Lock in the current exchange rate for a short time, pass the quote id as quote_id when creating the transfer
+//	@Param			quote	body	createFxQuoteRequest	true	"Create FX Quote Request"
+//	@Produce		application/json
+//	@Tags			fx
+//	@Success		200	{object}	fxQuoteResponse
+//	@Security		BearerAuth
+//	@Router			/fx/quotes [post]
+func (server *Server) CreateFxQuote(ctx *gin.Context) {
+	var req createFxQuoteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	rate, ok := server.currentExchangeRate(ctx, req.FromCurrency, req.ToCurrency)
+	if !ok {
+		return
+	}
+
+	fromExponent := server.currencies.Exponent(req.FromCurrency)
+	toExponent := server.currencies.Exponent(req.ToCurrency)
+
+	toAmount, err := util.ConvertAmount(req.Amount, rate.Rate, rate.SpreadBps, fromExponent, toExponent)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.CreateFxQuoteParams{
+		ID:           uuid.New(),
+		Username:     authPayload.Username,
+		FromCurrency: rate.FromCurrency,
+		ToCurrency:   rate.ToCurrency,
+		Rate:         rate.Rate,
+		SpreadBps:    rate.SpreadBps,
+		ExpiresAt:    time.Now().Add(server.config.FXQuoteDuration),
+	}
+
+	quote, err := server.store.CreateFxQuote(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, fxQuoteResponse{
+		ID:                quote.ID,
+		FromCurrency:      quote.FromCurrency,
+		ToCurrency:        quote.ToCurrency,
+		Rate:              quote.Rate,
+		SpreadBps:         quote.SpreadBps,
+		Amount:            req.Amount,
+		ToAmount:          toAmount,
+		FormattedAmount:   util.FormatAmount(req.Amount, fromExponent),
+		FormattedToAmount: util.FormatAmount(toAmount, toExponent),
+		ExpiresAt:         quote.ExpiresAt,
+	})
+}
+
+// currentExchangeRate loads the rate between two currencies, writing the
+// error response when there is none
+func (server *Server) currentExchangeRate(ctx *gin.Context, fromCurrency, toCurrency string) (db.ExchangeRate, bool) {
+	rate, err := banking.CurrentExchangeRate(ctx, server.store, fromCurrency, toCurrency)
+	if err != nil {
+		bankingError(ctx, err)
+		return rate, false
+	}
+
+	return rate, true
+}
+
+// quotedExchangeRate loads a quote created by the authenticated user for the
+// given currencies and checks it has not expired, writing the error response
+// when it cannot be used
+func (server *Server) quotedExchangeRate(ctx *gin.Context, quoteID uuid.UUID, fromCurrency, toCurrency string) (db.FxQuote, bool) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	quote, err := banking.QuotedExchangeRate(ctx, server.store, authPayload.Username, quoteID, fromCurrency, toCurrency)
+	if err != nil {
+		bankingError(ctx, err)
+		return quote, false
+	}
+
+	return quote, true
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
+)
+
+// idempotencyParams builds the idempotency params from the Idempotency-Key header,
+// it returns nil when the client did not send the header
+func idempotencyParams(ctx *gin.Context, req interface{}) (*db.IdempotencyParams, error) {
+	key := ctx.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		return nil, nil
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, fmt.Errorf("%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength)
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(ctx.Request.Method + " " + ctx.FullPath() + "\n"))
+	hash.Write(body)
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	return &db.IdempotencyParams{
+		Username:    authPayload.Username,
+		Key:         key,
+		RequestHash: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// replayIdempotentResponse writes the stored response when the key was already used
+// and reports whether the request has been handled
+func (server *Server) replayIdempotentResponse(ctx *gin.Context, arg *db.IdempotencyParams) bool {
+	if arg == nil {
+		return false
+	}
+
+	stored, err := server.store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		Username: arg.Username,
+		Key:      arg.Key,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return true
+	}
+
+	if stored.RequestHash != arg.RequestHash {
+		err := fmt.Errorf("%s was already used with a different request", idempotencyKeyHeader)
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return true
+	}
+
+	ctx.Data(http.StatusOK, "application/json; charset=utf-8", stored.ResponseBody)
+	return true
+}
+
+// isIdempotencyConflict reports whether a transaction failed because a concurrent
+// request stored a response under the same idempotency key first
+func isIdempotencyConflict(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code.Name() == "unique_violation" && pqErr.Constraint == "idempotency_keys_pkey"
+	}
+	return false
+}
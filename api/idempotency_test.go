@@ -0,0 +1,282 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/dbtest"
+	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+type eqIdempotencyKeyMatcher struct {
+	username string
+	key      string
+}
+
+func (e eqIdempotencyKeyMatcher) Matches(x interface{}) bool {
+	var arg *db.IdempotencyParams
+	switch params := x.(type) {
+	case db.TransferTxParams:
+		arg = params.Idempotency
+	case db.DepositTxParams:
+		arg = params.Idempotency
+	case db.WithdrawTxParams:
+		arg = params.Idempotency
+	default:
+		return false
+	}
+
+	return arg != nil && arg.Username == e.username && arg.Key == e.key && arg.RequestHash != ""
+}
+
+func (e eqIdempotencyKeyMatcher) String() string {
+	return fmt.Sprintf("has idempotency key %s for user %s", e.key, e.username)
+}
+
+func EqIdempotencyKey(username, key string) gomock.Matcher {
+	return eqIdempotencyKeyMatcher{username, key}
+}
+
+func TestCreateTransferIdempotencyAPI(t *testing.T) {
+	user1, _ := dbtest.RandomUser(t)
+	user2, _ := dbtest.RandomUser(t)
+
+	account1 := dbtest.RandomAccount(user1.Username)
+	account2 := dbtest.RandomAccount(user2.Username)
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+
+	key := util.RandomString(16)
+	body := fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account2.ID)
+	otherBody := fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 20, "currency": "USD"}`, account1.ID, account2.ID)
+
+	result := db.TransferTxResult{
+		Transfer:    dbtest.RandomTransfer(account1, account2),
+		FromAccount: account1,
+		ToAccount:   account2,
+	}
+
+	// the first request stores its response so the hash can be reused by the replay cases
+	server, storedKey := runFirstTransfer(t, user1.Username, key, body, account1, account2, result)
+
+	testCases := []struct {
+		name          string
+		body          string
+		key           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rec *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Replay",
+			body: body,
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(storedKey, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+				requireBodyMatchTransferResult(t, rec.Body, result)
+			},
+		},
+		{
+			name: "MismatchedBody",
+			body: otherBody,
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(storedKey, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, rec.Code)
+			},
+		},
+		{
+			name: "ConcurrentDuplicate",
+			body: body,
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, sql.ErrNoRows),
+					store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(storedKey, nil),
+				)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), EqIdempotencyKey(user1.Username, key)).
+					Times(1).
+					Return(db.TransferTxResult{}, &pq.Error{Code: "23505", Constraint: "idempotency_keys_pkey"})
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+				requireBodyMatchTransferResult(t, rec.Body, result)
+			},
+		},
+		{
+			name: "KeyTooLong",
+			body: body,
+			key:  util.RandomString(maxIdempotencyKeyLength + 1),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name: "GetIdempotencyKeyError",
+			body: body,
+			key:  key,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, sql.ErrConnDone)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, rec.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			server.store = store
+
+			rec := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/transfers", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			req.Header.Set(idempotencyKeyHeader, tc.key)
+
+			addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			server.router.ServeHTTP(rec, req)
+			tc.checkResponse(t, rec)
+		})
+	}
+}
+
+// runFirstTransfer sends the first request for the key and captures what the
+// store was asked to persist, so later cases can replay it
+func runFirstTransfer(
+	t *testing.T,
+	username string,
+	key string,
+	body string,
+	account1 db.Account,
+	account2 db.Account,
+	result db.TransferTxResult,
+) (*Server, db.IdempotencyKey) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var stored db.IdempotencyKey
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, sql.ErrNoRows)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+	store.EXPECT().
+		TransferTx(gomock.Any(), EqIdempotencyKey(username, key)).
+		Times(1).
+		DoAndReturn(func(_ interface{}, arg db.TransferTxParams) (db.TransferTxResult, error) {
+			data, err := json.Marshal(result)
+			require.NoError(t, err)
+
+			stored = db.IdempotencyKey{
+				Username:     arg.Idempotency.Username,
+				Key:          arg.Idempotency.Key,
+				RequestHash:  arg.Idempotency.RequestHash,
+				ResponseBody: data,
+			}
+			return result, nil
+		})
+
+	server := newTestServer(t, store)
+	rec := httptest.NewRecorder()
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/transfers", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	req.Header.Set(idempotencyKeyHeader, key)
+
+	addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, username, time.Minute)
+	server.router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	requireBodyMatchTransferResult(t, rec.Body, result)
+
+	return server, stored
+}
+
+func TestDepositIdempotencyAPI(t *testing.T) {
+	user, _ := dbtest.RandomUser(t)
+	account := dbtest.RandomAccount(user.Username)
+	key := util.RandomString(16)
+
+	result := db.DepositTxResult{Account: account, Entry: createRandomEntry(account)}
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	var stored db.IdempotencyKey
+	gomock.InOrder(
+		store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, sql.ErrNoRows),
+		store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+			func(_ interface{}, _ db.GetIdempotencyKeyParams) (db.IdempotencyKey, error) {
+				return stored, nil
+			},
+		),
+	)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	store.EXPECT().
+		DepositTx(gomock.Any(), EqIdempotencyKey(user.Username, key)).
+		Times(1).
+		DoAndReturn(func(_ interface{}, arg db.DepositTxParams) (db.DepositTxResult, error) {
+			stored = db.IdempotencyKey{
+				Username:     arg.Idempotency.Username,
+				Key:          arg.Idempotency.Key,
+				RequestHash:  arg.Idempotency.RequestHash,
+				ResponseBody: data,
+			}
+			return result, nil
+		})
+
+	// the retry must replay the first response without depositing again
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+
+		body := fmt.Sprintf(`{"id":%d,"amount":%d,"currency":"%s"}`, account.ID, 10, account.Currency)
+		req, err := http.NewRequest(http.MethodPost, "/api/v1/accounts/deposit", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set(idempotencyKeyHeader, key)
+
+		addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+		server.router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		requireBodyMatchDepositResult(t, rec.Body, result)
+	}
+}
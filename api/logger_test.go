@@ -0,0 +1,160 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/dbtest"
+	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// logLines decodes the JSON lines written to buf
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(buf)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestLoggerMiddleware(t *testing.T) {
+	user, _ := dbtest.RandomUser(t)
+	account := dbtest.RandomAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		url           string
+		requestID     string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, lines []map[string]interface{})
+	}{
+		{
+			name:      "OK",
+			url:       fmt.Sprintf("/api/v1/accounts/%d", account.ID),
+			requestID: "req-1",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, id int64) (db.Account, error) {
+						// the store gets the request logger through the context
+						db.LoggerFrom(ctx).Info("store", "account", account, "password", "secret")
+						return account, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, lines []map[string]interface{}) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "req-1", recorder.Header().Get(requestIDHeader))
+
+				require.Len(t, lines, 2)
+				store, request := lines[0], lines[1]
+
+				require.Equal(t, "req-1", store["request_id"])
+				require.Equal(t, util.Redacted, store["password"])
+				require.Equal(t, util.Redacted, store["account"].(map[string]interface{})["balance"])
+
+				require.Equal(t, "INFO", request["level"])
+				require.Equal(t, "request", request["msg"])
+				require.Equal(t, "req-1", request["request_id"])
+				require.Equal(t, http.MethodGet, request["method"])
+				require.Equal(t, "/api/v1/accounts/:id", request["route"])
+				require.Equal(t, float64(http.StatusOK), request["status"])
+				require.Equal(t, user.Username, request["user"])
+				require.Contains(t, request, "latency")
+			},
+		},
+		{
+			name: "AssignRequestID",
+			url:  fmt.Sprintf("/api/v1/accounts/%d", account.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, lines []map[string]interface{}) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+				requestID := recorder.Header().Get(requestIDHeader)
+				require.Len(t, requestID, 36)
+
+				require.Len(t, lines, 1)
+				require.Equal(t, "WARN", lines[0]["level"])
+				require.Equal(t, requestID, lines[0]["request_id"])
+				require.NotContains(t, lines[0], "user")
+			},
+		},
+		{
+			name: "Panic",
+			url:  "/panic",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, lines []map[string]interface{}) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.JSONEq(t, `{"error":"internal server error"}`, recorder.Body.String())
+
+				require.Len(t, lines, 2)
+				require.Equal(t, "ERROR", lines[0]["level"])
+				require.Equal(t, "boom", lines[0]["panic"])
+				require.Contains(t, lines[0]["stack"], "logger_test.go")
+
+				require.Equal(t, "ERROR", lines[1]["level"])
+				require.Equal(t, "/panic", lines[1]["route"])
+				require.Equal(t, float64(http.StatusInternalServerError), lines[1]["status"])
+				require.Equal(t, lines[0]["request_id"], lines[1]["request_id"])
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			var buf bytes.Buffer
+			server := newTestServer(t, store)
+			server.logger = util.NewLogger(&buf, slog.LevelInfo)
+			server.router.GET("/panic", func(ctx *gin.Context) {
+				panic("boom")
+			})
+
+			request, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+			if tc.requestID != "" {
+				request.Header.Set(requestIDHeader, tc.requestID)
+			}
+			tc.setupAuth(t, request, server.tokenMaker)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, logLines(t, &buf))
+		})
+	}
+}
@@ -1,13 +1,61 @@
 package api
 
 import (
+	"io"
+	"log/slog"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/dbtest"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/stream"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
 )
 
+const (
+	testAdminUsername  = "admin"
+	testBankerUsername = "banker"
+)
+
+// testRole is the role addAuthorization puts in the tokens of a test user,
+// everyone but the admin and banker test users is a customer
+func testRole(username string) string {
+	switch username {
+	case testAdminUsername:
+		return util.AdminRole
+	case testBankerUsername:
+		return util.BankerRole
+	}
+	return util.CustomerRole
+}
+
+// testCurrencies is the registry of every test server, gin keeps the
+// currency validation registered by the first server that bound a request so
+// the servers of one process must share their registry
+var testCurrencies = dbtest.NewCurrencyRegistry()
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := util.Config{
+		TokenSymmetricKey:    util.RandomString(32),
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+		FXQuoteDuration:      30 * time.Second,
+		HoldTTL:              time.Hour,
+		SSEHeartbeatInterval: time.Minute,
+	}
+
+	server, err := NewServer(config, store, testCurrencies, stream.NewBroker(), slog.New(slog.NewJSONHandler(io.Discard, nil)), prometheus.NewRegistry())
+	require.NoError(t, err)
+	server.audit = &fakeAuditLog{}
+
+	return server
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 	os.Exit(m.Run())
-}
\ No newline at end of file
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/dbtest"
+	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user, _ := dbtest.RandomUser(t)
+	account := dbtest.RandomAccount(user.Username)
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(2).Return(account, nil)
+
+	// newTestServer hands every server a registry of its own
+	server := newTestServer(t, store)
+
+	serve := func(url string, authorize bool) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		if authorize {
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+		}
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	url := fmt.Sprintf("/api/v1/accounts/%d", account.ID)
+	serve(url, true)
+	serve(url, true)
+	serve(url, false)
+	serve("/no/such/route/1", false)
+
+	route := "/api/v1/accounts/:id"
+	require.Equal(t, 2.0, testutil.ToFloat64(server.metrics.requests.WithLabelValues(http.MethodGet, route, "200")))
+	require.Equal(t, 1.0, testutil.ToFloat64(server.metrics.requests.WithLabelValues(http.MethodGet, route, "401")))
+	require.Equal(t, 1.0, testutil.ToFloat64(server.metrics.requests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")))
+
+	// one histogram per method, route and status seen so far
+	count, err := testutil.GatherAndCount(server.registry, "http_request_duration_seconds")
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+
+	recorder := serve("/metrics", false)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	expected := fmt.Sprintf(`http_requests_total{method="GET",route=%q,status="200"} 2`, route)
+	require.Contains(t, recorder.Body.String(), expected)
+}
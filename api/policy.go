@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/gin-gonic/gin"
+)
+
+// permission is something only some roles can do, routes ask for one with
+// authorizeMiddleware instead of checking roles themselves
+type permission string
+
+const (
+	permListAllAccounts   permission = "accounts:list_all"
+	permFreezeAccounts    permission = "accounts:freeze"
+	permManageOverdraft   permission = "accounts:manage_overdraft"
+	permListUserTransfers permission = "transfers:list_any_user"
+	permManageRoles       permission = "users:manage_roles"
+	permManageRates       permission = "exchange_rates:manage"
+	permManageCurrencies  permission = "currencies:manage"
+	permReconcile         permission = "reconciliation:run"
+	permRevokeAnySession  permission = "sessions:revoke_any"
+)
+
+// rolePolicy is the only place that says which roles have a permission.
+// Customers have none of them, they can only reach their own accounts
+var rolePolicy = map[permission][]string{
+	permListAllAccounts:   {util.BankerRole, util.AdminRole},
+	permFreezeAccounts:    {util.BankerRole, util.AdminRole},
+	permManageOverdraft:   {util.AdminRole},
+	permListUserTransfers: {util.BankerRole, util.AdminRole},
+	permManageRoles:       {util.AdminRole},
+	permManageRates:       {util.AdminRole},
+	permManageCurrencies:  {util.AdminRole},
+	permReconcile:         {util.AdminRole},
+	permRevokeAnySession:  {util.BankerRole, util.AdminRole},
+}
+
+var errPermissionDenied = errors.New("your role is not allowed to access this resource")
+
+// hasPermission reports whether the policy grants the permission to the role
+func hasPermission(role string, perm permission) bool {
+	for _, allowed := range rolePolicy[perm] {
+		if role == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizeMiddleware only lets through users whose token role has the
+// permission, it must run after authMiddleware
+func authorizeMiddleware(perm permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		if !hasPermission(authPayload.Role, perm) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errPermissionDenied))
+			return
+		}
+
+		ctx.Next()
+	}
+}
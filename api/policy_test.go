@@ -0,0 +1,107 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizeMiddleware(t *testing.T) {
+	testCases := []struct {
+		name       string
+		username   string
+		permission permission
+		status     int
+	}{
+		{
+			name:       "AdminManagesRoles",
+			username:   testAdminUsername,
+			permission: permManageRoles,
+			status:     http.StatusOK,
+		},
+		{
+			name:       "BankerFreezes",
+			username:   testBankerUsername,
+			permission: permFreezeAccounts,
+			status:     http.StatusOK,
+		},
+		{
+			name:       "AdminFreezes",
+			username:   testAdminUsername,
+			permission: permFreezeAccounts,
+			status:     http.StatusOK,
+		},
+		{
+			name:       "AdminManagesOverdraft",
+			username:   testAdminUsername,
+			permission: permManageOverdraft,
+			status:     http.StatusOK,
+		},
+		{
+			name:       "BankerManagesOverdraft",
+			username:   testBankerUsername,
+			permission: permManageOverdraft,
+			status:     http.StatusForbidden,
+		},
+		{
+			name:       "BankerManagesRoles",
+			username:   testBankerUsername,
+			permission: permManageRoles,
+			status:     http.StatusForbidden,
+		},
+		{
+			name:       "BankerRevokesSessions",
+			username:   testBankerUsername,
+			permission: permRevokeAnySession,
+			status:     http.StatusOK,
+		},
+		{
+			name:       "CustomerRevokesAnySession",
+			username:   "customer",
+			permission: permRevokeAnySession,
+			status:     http.StatusForbidden,
+		},
+		{
+			name:       "CustomerFreezes",
+			username:   "customer",
+			permission: permFreezeAccounts,
+			status:     http.StatusForbidden,
+		},
+		{
+			name:       "UnknownPermission",
+			username:   testAdminUsername,
+			permission: permission("unknown"),
+			status:     http.StatusForbidden,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil)
+
+			authPath := "/authorize"
+			server.router.GET(
+				authPath,
+				authMiddleware(server.tokenMaker),
+				authorizeMiddleware(tc.permission),
+				func(ctx *gin.Context) {
+					ctx.JSON(http.StatusOK, gin.H{})
+				},
+			)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, authPath, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/reconcile"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/gin-gonic/gin"
+)
+
+type reconciliationRunResponse struct {
+	ID              int64                `json:"id"`
+	TriggeredBy     string               `json:"triggered_by"`
+	Adjust          bool                 `json:"adjust"`
+	Status          string               `json:"status"`
+	AccountsChecked int64                `json:"accounts_checked"`
+	MismatchCount   int64                `json:"mismatch_count"`
+	Mismatches      []reconcile.Mismatch `json:"mismatches"`
+	Error           string               `json:"error,omitempty"`
+	StartedAt       time.Time            `json:"started_at"`
+	FinishedAt      time.Time            `json:"finished_at"`
+}
+
+func newReconciliationRunResponse(run db.ReconciliationRun) (reconciliationRunResponse, error) {
+	response := reconciliationRunResponse{
+		ID:              run.ID,
+		TriggeredBy:     run.TriggeredBy,
+		Adjust:          run.Adjust,
+		Status:          run.Status,
+		AccountsChecked: run.AccountsChecked,
+		MismatchCount:   run.MismatchCount,
+		Mismatches:      []reconcile.Mismatch{},
+		Error:           run.Error,
+		StartedAt:       run.StartedAt,
+		FinishedAt:      run.FinishedAt,
+	}
+
+	if len(run.Mismatches) > 0 {
+		if err := json.Unmarshal(run.Mismatches, &response.Mismatches); err != nil {
+			return reconciliationRunResponse{}, err
+		}
+	}
+
+	return response, nil
+}
+
+type createReconciliationRunRequest struct {
+	Adjust bool `json:"adjust"`
+}
+
+// CreateReconciliationRun godoc
+//	@Summary		Run a reconciliation
+//	@Description	Check every account balance against the sum of its entries and store the run, admin only. With adjust set a correcting entry is written for every mismatch. The run is stored even when it fails
+//	@Param			run	body	createReconciliationRunRequest	false	"Reconciliation Run Request"
+//	@Produce		application/json
+//	@Tags			admin
+//	@Success		201	{object}	reconciliationRunResponse
+//	@Security		BearerAuth
+//	@Router			/admin/reconciliation_runs [post]
+func (server *Server) CreateReconciliationRun(ctx *gin.Context) {
+	var req createReconciliationRunRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	result, err := reconcile.Run(ctx, server.store, reconcile.Options{
+		Adjust:      req.Adjust,
+		TriggeredBy: authPayload.Username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response, err := newReconciliationRunResponse(result.Run)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+type listReconciliationRunsRequest struct {
+	Size int32 `form:"size" binding:"required,min=1,max=100"`
+}
+
+// ListReconciliationRuns godoc
+//	@Summary		List reconciliation runs
+//	@Description	List the latest reconciliation runs, newest first, admin only
+//	@Param			size	query	int	true	"Page Size"
+//	@Produce		application/json
+//	@Tags			admin
+//	@Success		200	{object}	[]reconciliationRunResponse
+//	@Security		BearerAuth
+//	@Router			/admin/reconciliation_runs [get]
+func (server *Server) ListReconciliationRuns(ctx *gin.Context) {
+	var req listReconciliationRunsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	runs, err := server.store.ListReconciliationRuns(ctx, req.Size)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]reconciliationRunResponse, len(runs))
+	for i, run := range runs {
+		response[i], err = newReconciliationRunResponse(run)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+type getReconciliationRunRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// GetReconciliationRun godoc
+//	@Summary		Get a reconciliation run
+//	@Description	Get a reconciliation run with the account ids and deltas of its mismatches, admin only
+//	@Param			id	path	int	true	"Run ID"
+//	@Produce		application/json
+//	@Tags			admin
+//	@Success		200	{object}	reconciliationRunResponse
+//	@Security		BearerAuth
+//	@Router			/admin/reconciliation_runs/{id} [get]
+func (server *Server) GetReconciliationRun(ctx *gin.Context) {
+	var req getReconciliationRunRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	run, err := server.store.GetReconciliationRun(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response, err := newReconciliationRunResponse(run)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
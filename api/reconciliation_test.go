@@ -0,0 +1,380 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/reconcile"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func randomReconciliationRun(t *testing.T) db.ReconciliationRun {
+	mismatches, err := json.Marshal([]reconcile.Mismatch{
+		{AccountID: 3, Balance: 100, EntriesTotal: 70, Delta: 30},
+	})
+	require.NoError(t, err)
+
+	return db.ReconciliationRun{
+		ID:              1,
+		TriggeredBy:     testAdminUsername,
+		Status:          reconcile.StatusSucceeded,
+		AccountsChecked: 10,
+		MismatchCount:   1,
+		Mismatches:      mismatches,
+		StartedAt:       time.Now().UTC().Truncate(time.Second),
+		FinishedAt:      time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func TestCreateReconciliationRunAPI(t *testing.T) {
+	totals := []db.ListAccountEntryTotalsRow{
+		{ID: 1, Balance: 100, EntriesTotal: 100},
+		{ID: 3, Balance: 100, EntriesTotal: 70},
+	}
+
+	testCases := []struct {
+		name          string
+		body          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rec *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, testAdminUsername, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountEntryTotals(gomock.Any(), gomock.Any()).Times(1).Return(totals, nil)
+				store.EXPECT().AdjustEntriesTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					CreateReconciliationRun(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateReconciliationRunParams) (db.ReconciliationRun, error) {
+						require.Equal(t, testAdminUsername, arg.TriggeredBy)
+						require.False(t, arg.Adjust)
+						return db.ReconciliationRun{
+							ID:              1,
+							TriggeredBy:     arg.TriggeredBy,
+							Status:          arg.Status,
+							AccountsChecked: arg.AccountsChecked,
+							MismatchCount:   arg.MismatchCount,
+							Mismatches:      arg.Mismatches,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, rec.Code)
+
+				var body reconciliationRunResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				require.Equal(t, reconcile.StatusSucceeded, body.Status)
+				require.Equal(t, int64(2), body.AccountsChecked)
+				require.Equal(t, []reconcile.Mismatch{{AccountID: 3, Balance: 100, EntriesTotal: 70, Delta: 30}}, body.Mismatches)
+			},
+		},
+		{
+			name: "Adjust",
+			body: `{"adjust": true}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, testAdminUsername, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountEntryTotals(gomock.Any(), gomock.Any()).Times(1).Return(totals, nil)
+				store.EXPECT().
+					AdjustEntriesTx(gomock.Any(), gomock.Eq(int64(3))).
+					Times(1).
+					Return(db.AdjustEntriesTxResult{Delta: 30, Entry: &db.Entry{ID: 9, AccountID: 3, Amount: 30}}, nil)
+				store.EXPECT().
+					CreateReconciliationRun(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateReconciliationRunParams) (db.ReconciliationRun, error) {
+						require.True(t, arg.Adjust)
+						return db.ReconciliationRun{ID: 1, Adjust: arg.Adjust, Status: arg.Status, Mismatches: arg.Mismatches}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, rec.Code)
+
+				var body reconciliationRunResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				require.True(t, body.Adjust)
+				require.Len(t, body.Mismatches, 1)
+				require.Equal(t, int64(9), *body.Mismatches[0].AdjustmentEntryID)
+			},
+		},
+		{
+			name: "NotAdmin",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "customer", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountEntryTotals(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateReconciliationRun(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountEntryTotals(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, rec.Code)
+			},
+		},
+		{
+			name: "ScanError",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, testAdminUsername, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountEntryTotals(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+				store.EXPECT().
+					CreateReconciliationRun(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReconciliationRun{ID: 1, Status: reconcile.StatusFailed}, nil)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, rec.Code)
+			},
+		},
+		{
+			name: "InvalidBody",
+			body: `{"adjust": "yes"}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, testAdminUsername, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountEntryTotals(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			rec := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/admin/reconciliation_runs", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenMaker)
+			server.router.ServeHTTP(rec, req)
+			tc.checkResponse(t, rec)
+		})
+	}
+}
+
+func TestListReconciliationRunsAPI(t *testing.T) {
+	runs := []db.ReconciliationRun{randomReconciliationRun(t), randomReconciliationRun(t)}
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rec *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, testAdminUsername, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListReconciliationRuns(gomock.Any(), gomock.Eq(int32(5))).Times(1).Return(runs, nil)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+
+				var body []reconciliationRunResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				require.Len(t, body, len(runs))
+				require.Equal(t, int64(3), body[0].Mismatches[0].AccountID)
+				require.Equal(t, int64(30), body[0].Mismatches[0].Delta)
+			},
+		},
+		{
+			name:  "NotAdmin",
+			query: "size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "customer", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListReconciliationRuns(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			name:  "InvalidSize",
+			query: "size=1000",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, testAdminUsername, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListReconciliationRuns(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, testAdminUsername, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListReconciliationRuns(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, rec.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			rec := httptest.NewRecorder()
+
+			url := "/api/v1/admin/reconciliation_runs?" + tc.query
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenMaker)
+			server.router.ServeHTTP(rec, req)
+			tc.checkResponse(t, rec)
+		})
+	}
+}
+
+func TestGetReconciliationRunAPI(t *testing.T) {
+	run := randomReconciliationRun(t)
+
+	testCases := []struct {
+		name          string
+		runID         int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rec *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			runID: run.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, testAdminUsername, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetReconciliationRun(gomock.Any(), gomock.Eq(run.ID)).Times(1).Return(run, nil)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+
+				var body reconciliationRunResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				require.Equal(t, run.ID, body.ID)
+				require.Equal(t, run.StartedAt, body.StartedAt)
+				require.Equal(t, []reconcile.Mismatch{{AccountID: 3, Balance: 100, EntriesTotal: 70, Delta: 30}}, body.Mismatches)
+			},
+		},
+		{
+			name:  "NotFound",
+			runID: run.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, testAdminUsername, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetReconciliationRun(gomock.Any(), gomock.Eq(run.ID)).Times(1).Return(db.ReconciliationRun{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, rec.Code)
+			},
+		},
+		{
+			name:  "NotAdmin",
+			runID: run.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "customer", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetReconciliationRun(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			name:  "InvalidID",
+			runID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, testAdminUsername, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetReconciliationRun(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			rec := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/v1/admin/reconciliation_runs/%d", tc.runID)
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenMaker)
+			server.router.ServeHTTP(rec, req)
+			tc.checkResponse(t, rec)
+		})
+	}
+}
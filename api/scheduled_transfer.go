@@ -0,0 +1,400 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/banking"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/gin-gonic/gin"
+)
+
+const errCodeScheduledTransferFinished = "scheduled_transfer_finished"
+
+var (
+	errStartInPast               = errors.New("start_at must not be in the past")
+	errEndBeforeStart            = errors.New("end_at must be after start_at")
+	errEndBeforeNextRun          = errors.New("end_at must not be before next_run_at")
+	errScheduledTransferNotOwned = errors.New("scheduled transfer doesn't belong to the authenticated user")
+	errScheduledTransferFinished = errors.New("scheduled transfer is completed or cancelled")
+	errNothingToUpdate           = errors.New("at least one of amount, end_at or status is required")
+)
+
+type createScheduledTransferRequest struct {
+	FromAccountID int64      `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64      `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	Amount        int64      `json:"amount" binding:"required,gt=0"`
+	Currency      string     `json:"currency" binding:"required,currency"`
+	IntervalUnit  string     `json:"interval_unit" binding:"required,oneof=day week month"`
+	IntervalCount int32      `json:"interval_count" binding:"omitempty,min=1,max=365"`
+	StartAt       time.Time  `json:"start_at" binding:"required"`
+	EndAt         *time.Time `json:"end_at"`
+}
+
+type listScheduledTransfersResponse struct {
+	Data       []db.ScheduledTransfer `json:"data"`
+	NextCursor *string                `json:"next_cursor"`
+}
+
+type listScheduledTransferRunsResponse struct {
+	Data       []db.ScheduledTransferRun `json:"data"`
+	NextCursor *string                   `json:"next_cursor"`
+}
+
+// CreateScheduledTransfer godoc
+//	@Summary		Create a scheduled transfer
+//	@Description	Schedule a transfer that repeats every interval_count days, weeks or months from start_at until end_at or until it is cancelled. Both accounts must use the currency, runs that fail are recorded and the schedule moves on
+//	@Param			scheduled_transfer	body	createScheduledTransferRequest	true	"Create Scheduled Transfer Request"
+//	@Produce		application/json
+//	@Tags			scheduled transfers
+//	@Success		201	{object}	db.ScheduledTransfer
+//	@Security		BearerAuth
+//	@Router			/scheduled-transfers [post]
+func (server *Server) CreateScheduledTransfer(ctx *gin.Context) {
+	var req createScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.IntervalCount == 0 {
+		req.IntervalCount = 1
+	}
+
+	if req.StartAt.Before(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errStartInPast))
+		return
+	}
+
+	if req.EndAt != nil && !req.EndAt.After(req.StartAt) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errEndBeforeStart))
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if fromAccount.Name != authPayload.Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(banking.ErrAccountNotOwned))
+		return
+	}
+
+	if _, valid := server.validAccount(ctx, req.ToAccountID, req.Currency); !valid {
+		return
+	}
+
+	arg := db.CreateScheduledTransferParams{
+		Owner:         authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		IntervalUnit:  req.IntervalUnit,
+		IntervalCount: req.IntervalCount,
+		StartAt:       req.StartAt,
+		EndAt:         req.EndAt,
+	}
+
+	scheduled, err := server.store.CreateScheduledTransfer(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, scheduled)
+}
+
+type listScheduledTransfersRequest struct {
+	Cursor string `form:"cursor"`
+	Size   int32  `form:"size" binding:"required,min=1,max=100"`
+}
+
+// ListScheduledTransfers godoc
+//	@Summary		List scheduled transfers
+//	@Description	List the scheduled transfers of the logged in user oldest first, pass next_cursor as cursor to get the next page
+//	@Param			cursor	query	string	false	"Cursor"
+//	@Param			size	query	int		true	"Page Size"
+//	@Produce		application/json
+//	@Tags			scheduled transfers
+//	@Success		200	{object}	listScheduledTransfersResponse
+//	@Security		BearerAuth
+//	@Router			/scheduled-transfers [get]
+func (server *Server) ListScheduledTransfers(ctx *gin.Context) {
+	var req listScheduledTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	cursor, err := util.DecodeCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	scheduled, err := server.store.ListScheduledTransfers(ctx, db.ListScheduledTransfersParams{
+		Owner:          authPayload.Username,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+		Size:           req.Size + 1,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := listScheduledTransfersResponse{Data: scheduled}
+	if len(scheduled) > int(req.Size) {
+		rsp.Data = scheduled[:req.Size]
+		last := rsp.Data[len(rsp.Data)-1]
+		rsp.NextCursor = util.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type scheduledTransferUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// GetScheduledTransfer godoc
+//	@Summary		Get a scheduled transfer
+//	@Param			id	path	int	true	"Scheduled Transfer ID"
+//	@Produce		application/json
+//	@Tags			scheduled transfers
+//	@Success		200	{object}	db.ScheduledTransfer
+//	@Security		BearerAuth
+//	@Router			/scheduled-transfers/{id} [get]
+func (server *Server) GetScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, ok := server.ownedScheduledTransfer(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduled)
+}
+
+type updateScheduledTransferRequest struct {
+	Amount *int64     `json:"amount" binding:"omitempty,gt=0"`
+	EndAt  *time.Time `json:"end_at"`
+	Status string     `json:"status" binding:"omitempty,oneof=active paused"`
+}
+
+// UpdateScheduledTransfer godoc
+//	@Summary		Update a scheduled transfer
+//	@Description	Change the amount or end_at of a scheduled transfer, or pause and resume it with status. end_at must not be before next_run_at. A resumed transfer runs once for the occurrences it missed. Fails with 422 and code scheduled_transfer_finished once it is completed or cancelled
+//	@Param			id					path	int								true	"Scheduled Transfer ID"
+//	@Param			scheduled_transfer	body	updateScheduledTransferRequest	true	"Update Scheduled Transfer Request"
+//	@Produce		application/json
+//	@Tags			scheduled transfers
+//	@Success		200	{object}	db.ScheduledTransfer
+//	@Security		BearerAuth
+//	@Router			/scheduled-transfers/{id} [patch]
+func (server *Server) UpdateScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Amount == nil && req.EndAt == nil && req.Status == "" {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errNothingToUpdate))
+		return
+	}
+
+	scheduled, ok := server.ownedScheduledTransfer(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	if scheduledTransferFinished(ctx, scheduled) {
+		return
+	}
+
+	if req.EndAt != nil && !req.EndAt.After(scheduled.StartAt) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errEndBeforeStart))
+		return
+	}
+
+	// the worker runs next_run_at before it looks at end_at again
+	if req.EndAt != nil && req.EndAt.Before(scheduled.NextRunAt) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errEndBeforeNextRun))
+		return
+	}
+
+	scheduled, err := server.store.UpdateScheduledTransfer(ctx, db.UpdateScheduledTransferParams{
+		ID:     uri.ID,
+		Amount: util.NullInt64(req.Amount),
+		EndAt:  req.EndAt,
+		Status: util.NullString(req.Status),
+	})
+	if err == sql.ErrNoRows {
+		// finished by the worker or a cancel since it was read
+		ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errScheduledTransferFinished, errCodeScheduledTransferFinished))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduled)
+}
+
+// CancelScheduledTransfer godoc
+//	@Summary		Cancel a scheduled transfer
+//	@Description	Stop a scheduled transfer for good, its runs are kept. Fails with 422 and code scheduled_transfer_finished when it is already completed or cancelled
+//	@Param			id	path	int	true	"Scheduled Transfer ID"
+//	@Produce		application/json
+//	@Tags			scheduled transfers
+//	@Success		200	{object}	db.ScheduledTransfer
+//	@Security		BearerAuth
+//	@Router			/scheduled-transfers/{id} [delete]
+func (server *Server) CancelScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, ok := server.ownedScheduledTransfer(ctx, uri.ID)
+	if !ok {
+		return
+	}
+
+	if scheduledTransferFinished(ctx, scheduled) {
+		return
+	}
+
+	scheduled, err := server.store.UpdateScheduledTransfer(ctx, db.UpdateScheduledTransferParams{
+		ID:     uri.ID,
+		Status: util.NullString(db.ScheduledTransferCancelled),
+	})
+	if err == sql.ErrNoRows {
+		// finished by the worker or a cancel since it was read
+		ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errScheduledTransferFinished, errCodeScheduledTransferFinished))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduled)
+}
+
+type listScheduledTransferRunsRequest struct {
+	Cursor string `form:"cursor"`
+	Size   int32  `form:"size" binding:"required,min=1,max=100"`
+}
+
+// ListScheduledTransferRuns godoc
+//	@Summary		List the runs of a scheduled transfer
+//	@Description	List every execution of a scheduled transfer oldest first with the transfer it made or the error it failed with, pass next_cursor as cursor to get the next page
+//	@Param			id		path	int		true	"Scheduled Transfer ID"
+//	@Param			cursor	query	string	false	"Cursor"
+//	@Param			size	query	int		true	"Page Size"
+//	@Produce		application/json
+//	@Tags			scheduled transfers
+//	@Success		200	{object}	listScheduledTransferRunsResponse
+//	@Security		BearerAuth
+//	@Router			/scheduled-transfers/{id}/runs [get]
+func (server *Server) ListScheduledTransferRuns(ctx *gin.Context) {
+	var uri scheduledTransferUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listScheduledTransferRunsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	cursor, err := util.DecodeCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.ownedScheduledTransfer(ctx, uri.ID); !ok {
+		return
+	}
+
+	runs, err := server.store.ListScheduledTransferRuns(ctx, db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: uri.ID,
+		AfterCreatedAt:      cursor.CreatedAt,
+		AfterID:             cursor.ID,
+		Size:                req.Size + 1,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := listScheduledTransferRunsResponse{Data: runs}
+	if len(runs) > int(req.Size) {
+		rsp.Data = runs[:req.Size]
+		last := rsp.Data[len(rsp.Data)-1]
+		rsp.NextCursor = util.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// ownedScheduledTransfer loads the scheduled transfer and checks that it
+// belongs to the authenticated user, writing the error response when it does
+// not
+func (server *Server) ownedScheduledTransfer(ctx *gin.Context, id int64) (db.ScheduledTransfer, bool) {
+	scheduled, err := server.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return scheduled, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return scheduled, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if scheduled.Owner != authPayload.Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(errScheduledTransferNotOwned))
+		return scheduled, false
+	}
+
+	return scheduled, true
+}
+
+// scheduledTransferFinished writes the 422 response for scheduled transfers
+// that can no longer change
+func scheduledTransferFinished(ctx *gin.Context, scheduled db.ScheduledTransfer) bool {
+	switch scheduled.Status {
+	case db.ScheduledTransferCompleted, db.ScheduledTransferCancelled:
+		ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errScheduledTransferFinished, errCodeScheduledTransferFinished))
+		return true
+	default:
+		return false
+	}
+}
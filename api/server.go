@@ -1,58 +1,156 @@
 package api
 
 import (
+	"fmt"
+	"log/slog"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/banking"
 	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
 	docs "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/docs"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/prometheus/client_golang/prometheus"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 type Server struct {
-	store db.Store
-	router *gin.Engine
+	config     util.Config
+	store      db.Store
+	tokenMaker token.Maker
+	currencies *util.CurrencyRegistry
+	router     *gin.Engine
+	events     AccountEvents
+	audit      AuditLog
+	logger     *slog.Logger
+	registry   *prometheus.Registry
+	metrics    *httpMetrics
 }
 
-func NewServer(store db.Store) *Server {
+// NewServer registers the HTTP metrics with registry and serves everything
+// registered there on /metrics
+func NewServer(config util.Config, store db.Store, currencies *util.CurrencyRegistry, events AccountEvents, logger *slog.Logger, registry *prometheus.Registry) (*Server, error) {
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
 
 	docs.SwaggerInfo.BasePath = "/api/v1"
 
-	server := &Server{store: store}
-	router := gin.Default()
+	server := &Server{
+		config:     config,
+		store:      store,
+		tokenMaker: tokenMaker,
+		currencies: currencies,
+		events:     events,
+		audit:      store,
+		logger:     logger,
+		registry:   registry,
+		metrics:    newHTTPMetrics(registry),
+	}
+	// gin shares one validator engine and keeps the funcs a struct was
+	// first validated with, so every server of a process must be given the
+	// same registry
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		banking.RegisterValidations(v, server.currencies)
+	}
+
+	server.setupRouter()
+
+	return server, nil
+}
+
+func (server *Server) setupRouter() {
+	router := gin.New()
+	router.Use(server.loggerMiddleware(), server.metricsMiddleware(), server.recoveryMiddleware())
+	// handlers pass the gin context to the store, it has to carry the
+	// audit context auditMiddleware puts on the request
+	router.ContextWithFallback = true
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+	router.GET("/metrics", server.metricsHandler())
 
-	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-		v.RegisterValidation("currency", validCurrency)
+	v1 := router.Group("/api/v1")
+	publicRoutes := v1.Group("/").Use(server.auditMiddleware())
+	{
+		//user
+		publicRoutes.POST("/users/register", server.CreateUser)
+		publicRoutes.POST("/users/login", server.LoginUser)
+
+		//token
+		publicRoutes.POST("/tokens/renew_access", server.RenewAccessToken)
 	}
-	
 
-	v1 := router.Group("/api/v1")		
+	authRoutes := v1.Group("/").Use(authMiddleware(server.tokenMaker), server.auditMiddleware())
 	{
-		v1.POST("/accounts", server.CreateAccount)
-		v1.GET("/accounts/:id", server.GetAccount)
-		v1.GET("/accounts", server.GetAccounts)
-		v1.DELETE("/accounts/:id", server.DeleteAccount)
-		v1.POST("/accounts/deposit", server.Deposit)
+		authRoutes.POST("/accounts", server.CreateAccount)
+		authRoutes.GET("/accounts/:id", server.GetAccount)
+		authRoutes.GET("/accounts", server.GetAccounts)
+		authRoutes.GET("/accounts/:id/statement", server.GetStatement)
+		authRoutes.GET("/accounts/:id/events", server.StreamAccountEvents)
+		authRoutes.POST("/accounts/:id/close", server.CloseAccount)
+		authRoutes.POST("/accounts/deposit", server.Deposit)
+		authRoutes.POST("/accounts/withdraw", server.Withdraw)
 
 		//transfer
-		v1.POST("/transfers", server.CreateTransfer)
-		v1.GET("/transfers", server.GetTransfersByAccount)
-		v1.GET("/transfers/:id", server.GetTransferById)
+		authRoutes.POST("/transfers", server.CreateTransfer)
+		authRoutes.GET("/transfers", server.GetTransfersByAccount)
+		authRoutes.GET("/transfers/:id", server.GetTransferById)
+		authRoutes.POST("/transfers/:id/reverse", server.ReverseTransfer)
+		authRoutes.POST("/transfers/:id/capture", server.CaptureTransfer)
+		authRoutes.POST("/transfers/:id/void", server.VoidTransfer)
 
 		//entry
-		v1.GET("/entry", server.GetEntriesByAccount)
+		authRoutes.GET("/entry", server.GetEntriesByAccount)
 
-		//user
-		v1.POST("/users/register", server.CreateUser)
+		//session
+		authRoutes.DELETE("/sessions/:id", server.RevokeSession)
+
+		//fx
+		authRoutes.POST("/fx/quotes", server.CreateFxQuote)
+
+		//scheduled transfer
+		authRoutes.POST("/scheduled-transfers", server.CreateScheduledTransfer)
+		authRoutes.GET("/scheduled-transfers", server.ListScheduledTransfers)
+		authRoutes.GET("/scheduled-transfers/:id", server.GetScheduledTransfer)
+		authRoutes.PATCH("/scheduled-transfers/:id", server.UpdateScheduledTransfer)
+		authRoutes.DELETE("/scheduled-transfers/:id", server.CancelScheduledTransfer)
+		authRoutes.GET("/scheduled-transfers/:id/runs", server.ListScheduledTransferRuns)
+
+		//webhook
+		authRoutes.POST("/webhooks", server.CreateWebhook)
+		authRoutes.GET("/webhooks", server.ListWebhooks)
+		authRoutes.DELETE("/webhooks/:id", server.DeleteWebhook)
+		authRoutes.GET("/webhooks/:id/deliveries", server.ListWebhookDeliveries)
 	}
 
-	
+	// which roles can reach each admin route is decided by rolePolicy
+	adminRoutes := v1.Group("/admin").Use(authMiddleware(server.tokenMaker), server.auditMiddleware())
+	{
+		adminRoutes.PUT("/exchange_rates", authorizeMiddleware(permManageRates), server.UpsertExchangeRate)
+		adminRoutes.GET("/exchange_rates", authorizeMiddleware(permManageRates), server.ListExchangeRates)
+		adminRoutes.DELETE("/exchange_rates/:from/:to", authorizeMiddleware(permManageRates), server.DeleteExchangeRate)
+
+		adminRoutes.GET("/currencies", authorizeMiddleware(permManageCurrencies), server.ListCurrencies)
+		adminRoutes.POST("/currencies", authorizeMiddleware(permManageCurrencies), server.CreateCurrency)
+		adminRoutes.PATCH("/currencies/:code", authorizeMiddleware(permManageCurrencies), server.UpdateCurrency)
+
+		adminRoutes.GET("/accounts", authorizeMiddleware(permListAllAccounts), server.ListAllAccounts)
+		adminRoutes.POST("/accounts/:id/freeze", authorizeMiddleware(permFreezeAccounts), server.FreezeAccount)
+		adminRoutes.POST("/accounts/:id/unfreeze", authorizeMiddleware(permFreezeAccounts), server.UnfreezeAccount)
+		adminRoutes.PATCH("/accounts/:id/overdraft_limit", authorizeMiddleware(permManageOverdraft), server.UpdateOverdraftLimit)
+
+		adminRoutes.GET("/users/:username/transfers", authorizeMiddleware(permListUserTransfers), server.ListUserTransfers)
+		adminRoutes.PATCH("/users/:username/role", authorizeMiddleware(permManageRoles), server.UpdateUserRole)
+
+		adminRoutes.POST("/reconciliation_runs", authorizeMiddleware(permReconcile), server.CreateReconciliationRun)
+		adminRoutes.GET("/reconciliation_runs", authorizeMiddleware(permReconcile), server.ListReconciliationRuns)
+		adminRoutes.GET("/reconciliation_runs/:id", authorizeMiddleware(permReconcile), server.GetReconciliationRun)
+	}
 
 	server.router = router
-	
-	return server
 }
 
 func (server *Server) Start(address string) error {
@@ -63,3 +161,9 @@ func (server *Server) Start(address string) error {
 func errorResponse(err error) gin.H {
 	return gin.H{"error": err.Error()}
 }
+
+// errorCodeResponse adds a stable code next to the message so clients
+// can branch on the failure without parsing the text
+func errorCodeResponse(err error, code string) gin.H {
+	return gin.H{"error": err.Error(), "code": code}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/statement"
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidStatementPeriod = errors.New("to must not be before from")
+
+type getStatementUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type getStatementQuery struct {
+	From   time.Time `form:"from" binding:"required" time_format:"2006-01-02" time_utc:"1" swaggertype:"string" format:"date"`
+	To     time.Time `form:"to" binding:"required" time_format:"2006-01-02" time_utc:"1" swaggertype:"string" format:"date"`
+	Format string    `form:"format" binding:"required,oneof=csv ofx pdf"`
+}
+
+// GetStatement godoc
+//	@Summary		Download an account statement
+//	@Description	Download the statement of an account from the start of from to the end of to (UTC dates) as csv, ofx or pdf, with the opening balance, every entry with the running balance and the closing balance
+//	@Param			id			path	int					true	"Account ID"
+//	@Param			statement	query	getStatementQuery	true	"Statement period and format"
+//	@Produce		text/csv
+//	@Produce		application/x-ofx
+//	@Produce		application/pdf
+//	@Tags			accounts
+//	@Success		200	{file}	file
+//	@Security		BearerAuth
+//	@Router			/accounts/{id}/statement [get]
+func (server *Server) GetStatement(ctx *gin.Context) {
+	var uri getStatementUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req getStatementQuery
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.To.Before(req.From) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errInvalidStatementPeriod))
+		return
+	}
+
+	if _, ok := server.ownedAccount(ctx, uri.ID); !ok {
+		return
+	}
+
+	// to is a whole day, the statement runs until the next midnight
+	arg := db.StatementTxParams{
+		AccountID: uri.ID,
+		FromTime:  req.From,
+		ToTime:    req.To.AddDate(0, 0, 1),
+	}
+
+	result, err := server.store.StatementTx(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	exponent := server.currencies.Exponent(result.Account.Currency)
+	stmt := statement.New(result.Account, exponent, arg.FromTime, arg.ToTime, result.OpeningBalance, result.Entries)
+
+	var buf bytes.Buffer
+	if err := stmt.Write(&buf, req.Format); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", stmt.FileName(req.Format)))
+	ctx.Data(http.StatusOK, statement.ContentType(req.Format), buf.Bytes())
+}
@@ -2,27 +2,64 @@ package api
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"time"
 
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/banking"
 	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
+const (
+	errCodeInsufficientFunds  = "insufficient_funds"
+	errCodeConversionTooSmall = "conversion_too_small"
+)
+
+const (
+	errCodeReversalExceedsTransfer = "reversal_exceeds_transfer"
+	errCodeReverseReversal         = "reverse_reversal"
+	errCodeReversalTooSmall        = "reversal_too_small"
+	errCodeTransferNotPending      = "transfer_not_pending"
+	errCodeTransferNotPosted       = "transfer_not_posted"
+	errCodeHoldExpired             = "hold_expired"
+)
+
+// transferModePending creates a transfer that only holds the money until it
+// is captured
+const transferModePending = "pending"
+
 type createTransferRequest struct {
-	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
-	Amount        int64  `json:"amount" binding:"required,gt=0"`
-	Currency      string `json:"currency" binding:"required,currency"`
+	FromAccountID     int64           `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID       int64           `json:"to_account_id" binding:"required,min=1"`
+	Amount            int64           `json:"amount" binding:"required,gt=0"`
+	Currency          string          `json:"currency" binding:"required,currency"`
+	QuoteID           string          `json:"quote_id" binding:"omitempty,uuid"`
+	Description       string          `json:"description" binding:"max=255"`
+	ExternalReference string          `json:"external_reference" binding:"max=64"`
+	Metadata          json.RawMessage `json:"metadata" swaggertype:"object"`
+}
+
+type createTransferQuery struct {
+	Mode string `form:"mode" binding:"omitempty,oneof=pending"`
 }
 
 // CreateTransfer godoc
 //	@Summary		Create a new transfer
-//	@Description	Create a new transfer between two accounts
-//	@Param			transfer	body	createTransferRequest	true	"Create Transfer Request"
+//	@Description	Create a new transfer between two accounts, retries with the same Idempotency-Key replay the first response. The currency is the currency of the sender, when the receiver uses another currency the amount is converted with the quote_id rate or the current exchange rate. With mode=pending the amount is only held on the sender's account until the transfer is captured or voided, the hold expires after the configured TTL. The optional description (at most 255 characters) is copied onto both entries, external_reference takes at most 64 characters and metadata is a JSON object of at most 4 KB. Fails with 422 and code insufficient_funds when the available balance plus overdraft limit does not cover the amount, and with code conversion_too_small when the converted amount rounds down to nothing
+//	@Param			transfer		body	createTransferRequest	true	"Create Transfer Request"
+//	@Param			mode			query	string					false	"pending to hold the money until the transfer is captured"	Enums(pending)
+//	@Param			Idempotency-Key	header	string					false	"Idempotency Key"
 //	@Produce		application/json
 //	@Tags			transfers
-//	@Success		200	{object}	db.Transfer
+//	@Success		200	{object}	db.TransferTxResult
+//	@Security		BearerAuth
 //	@Router			/transfers [post]
 func (server *Server) CreateTransfer(ctx *gin.Context) {
 	var req createTransferRequest
@@ -31,23 +68,108 @@ func (server *Server) CreateTransfer(ctx *gin.Context) {
 		return
 	}
 
-	if !server.validAccount(ctx, req.FromAccountID, req.Currency) {
+	if err := util.ValidateMetadata(req.Metadata); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	if !server.validAccount(ctx, req.ToAccountID, req.Currency) {
+	var query createTransferQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
+	// the mode is part of the request, a retry in another mode must not
+	// replay the response
+	idempotency, err := idempotencyParams(ctx, struct {
+		createTransferRequest
+		Mode string `json:"mode,omitempty"`
+	}{req, query.Mode})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
 
-	arg := db.TransferTxParams{
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
-		Amount:        req.Amount,
+	if server.replayIdempotentResponse(ctx, idempotency) {
+		return
+	}
+
+	var holdExpiresAt *time.Time
+	if query.Mode == transferModePending {
+		expiresAt := time.Now().Add(server.config.HoldTTL)
+		holdExpiresAt = &expiresAt
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if fromAccount.Name != authPayload.Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(banking.ErrAccountNotOwned))
+		return
+	}
+
+	toAccount, valid := server.existingAccount(ctx, req.ToAccountID)
+	if !valid {
+		return
+	}
+
+	var transfer db.TransferTxResult
+	if toAccount.Currency == req.Currency && req.QuoteID == "" {
+		arg := db.TransferTxParams{
+			FromAccountID:     req.FromAccountID,
+			ToAccountID:       req.ToAccountID,
+			Amount:            req.Amount,
+			Idempotency:       idempotency,
+			HoldExpiresAt:     holdExpiresAt,
+			Description:       req.Description,
+			ExternalReference: req.ExternalReference,
+			Metadata:          req.Metadata,
+		}
+
+		transfer, err = server.store.TransferTx(ctx, arg)
+	} else {
+		arg := db.FXTransferTxParams{
+			FromAccountID:     req.FromAccountID,
+			ToAccountID:       req.ToAccountID,
+			Amount:            req.Amount,
+			Idempotency:       idempotency,
+			HoldExpiresAt:     holdExpiresAt,
+			Description:       req.Description,
+			ExternalReference: req.ExternalReference,
+			Metadata:          req.Metadata,
+		}
+
+		if req.QuoteID != "" {
+			quote, ok := server.quotedExchangeRate(ctx, uuid.MustParse(req.QuoteID), req.Currency, toAccount.Currency)
+			if !ok {
+				return
+			}
+			arg.Rate, arg.SpreadBps = quote.Rate, quote.SpreadBps
+		} else {
+			rate, ok := server.currentExchangeRate(ctx, req.Currency, toAccount.Currency)
+			if !ok {
+				return
+			}
+			arg.Rate, arg.SpreadBps = rate.Rate, rate.SpreadBps
+		}
+
+		transfer, err = server.store.FXTransferTx(ctx, arg)
 	}
 
-	transfer, err := server.store.TransferTx(ctx, arg)
 	if err != nil {
+		if isIdempotencyConflict(err) && server.replayIdempotentResponse(ctx, idempotency) {
+			return
+		}
+		if accountStateError(ctx, err) {
+			return
+		}
+		if errors.Is(err, db.ErrConversionTooSmall) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(err, errCodeConversionTooSmall))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
@@ -56,19 +178,31 @@ func (server *Server) CreateTransfer(ctx *gin.Context) {
 }
 
 type getTransfersByAccountRequest struct {
-	Id int64 `form:"id" binding:"required,min=1"`
-	Page int32 `form:"page" binding:"required,min=1"`
-	Size int32 `form:"size" binding:"required,min=1,max=10"`
+	Id             int64      `form:"id" binding:"required,min=1"`
+	Cursor         string     `form:"cursor"`
+	Size           int32      `form:"size" binding:"required,min=1,max=100"`
+	From           *time.Time `form:"from" swaggertype:"string" format:"date-time"`
+	To             *time.Time `form:"to" swaggertype:"string" format:"date-time"`
+	MinAmount      *int64     `form:"min_amount" binding:"omitempty,min=1"`
+	MaxAmount      *int64     `form:"max_amount" binding:"omitempty,min=1"`
+	Direction      string     `form:"direction" binding:"omitempty,oneof=incoming outgoing"`
+	CounterpartyID *int64     `form:"counterparty_id" binding:"omitempty,min=1,nefield=Id"`
+	Reference      string     `form:"reference" binding:"max=64"`
 }
 
+type listTransfersResponse struct {
+	Data       []db.Transfer `json:"data"`
+	NextCursor *string       `json:"next_cursor"`
+}
 
 // GetTransfersByAccount godoc
 //	@Summary		Get transfers by account ID
-//	@Description	Get transfers by the specified account ID
+//	@Description	Get transfers by the specified account ID oldest first, pass next_cursor as cursor to get the next page. Transfers can be filtered by created_at between from and to, amount between min_amount and max_amount, direction (incoming or outgoing), the counterparty account and the external_reference given when the transfer was created
 //	@Param			transfer	query	getTransfersByAccountRequest	true	"Get Transfers By Account Request"
 //	@Produce		application/json
 //	@Tags			transfers
-//	@Success		200	{object}	[]db.Transfer
+//	@Success		200	{object}	listTransfersResponse
+//	@Security		BearerAuth
 //	@Router			/transfers [get]
 func (server *Server) GetTransfersByAccount(ctx *gin.Context) {
 	var req getTransfersByAccountRequest
@@ -76,33 +210,143 @@ func (server *Server) GetTransfersByAccount(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
-	
-	arg := db.GetTransfersByAccountParams{
-		ID: req.Id,
-		Off: (req.Page - 1) * req.Page,
-		Size: req.Size,
+
+	cursor, err := util.DecodeCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := util.ValidateRange(req.From, req.To, req.MinAmount, req.MaxAmount); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.ownedAccount(ctx, req.Id); !ok {
+		return
+	}
+
+	arg := db.ListTransfersParams{
+		AccountID:      req.Id,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+		FromTime:       util.NullTime(req.From),
+		ToTime:         util.NullTime(req.To),
+		MinAmount:      util.NullInt64(req.MinAmount),
+		MaxAmount:      util.NullInt64(req.MaxAmount),
+		Direction:      util.NullString(req.Direction),
+		CounterpartyID: util.NullInt64(req.CounterpartyID),
+		Reference:      util.NullString(req.Reference),
+		Size:           req.Size + 1,
 	}
 
-	transfers, err := server.store.GetTransfersByAccount(ctx, arg)
+	transfers, err := server.store.ListTransfers(ctx, arg)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, transfers)
+	rsp := listTransfersResponse{Data: transfers}
+	if len(transfers) > int(req.Size) {
+		rsp.Data = transfers[:req.Size]
+		last := rsp.Data[len(rsp.Data)-1]
+		rsp.NextCursor = util.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type listUserTransfersUri struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+type listUserTransfersRequest struct {
+	Cursor string `form:"cursor"`
+	Size   int32  `form:"size" binding:"required,min=1,max=100"`
+}
+
+// ListUserTransfers godoc
+//	@Summary		List the transfers of a user
+//	@Description	List the transfers from or to any account of the user oldest first, pass next_cursor as cursor to get the next page. Bankers and admins only
+//	@Param			username	path	string						true	"Username"
+//	@Param			transfers	query	listUserTransfersRequest	true	"List User Transfers Request"
+//	@Produce		application/json
+//	@Tags			admin
+//	@Success		200	{object}	listTransfersResponse
+//	@Security		BearerAuth
+//	@Router			/admin/users/{username}/transfers [get]
+func (server *Server) ListUserTransfers(ctx *gin.Context) {
+	var uri listUserTransfersUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listUserTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	cursor, err := util.DecodeCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, err := server.store.GetUserByUsername(ctx, uri.Username); err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	arg := db.ListUserTransfersParams{
+		Username:       uri.Username,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+		Size:           req.Size + 1,
+	}
+
+	transfers, err := server.store.ListUserTransfers(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := listTransfersResponse{Data: transfers}
+	if len(transfers) > int(req.Size) {
+		rsp.Data = transfers[:req.Size]
+		last := rsp.Data[len(rsp.Data)-1]
+		rsp.NextCursor = util.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
 }
 
 type getTransferByIdRequest struct {
 	Id int64 `uri:"id" binding:"required,min=1"`
 }
 
+type transferResponse struct {
+	db.Transfer
+	// Reversals are the transfers that moved money back, oldest first
+	Reversals      []db.Transfer `json:"reversals"`
+	ReversedAmount int64         `json:"reversed_amount"`
+	// Hold is set for transfers that were created pending
+	Hold *db.Hold `json:"hold,omitempty"`
+}
+
 // GetTransferById godoc
 //	@Summary		Get a transfer by ID
-//	@Description	Get a transfer by the specified ID
+//	@Description	Get a transfer by the specified ID with the reversals made against it and the amount they refunded, transfers created pending also come with their hold
 //	@Param			id	path	getTransferByIdRequest	true	"Transfer ID"
 //	@Produce		application/json
 //	@Tags			transfers
-//	@Success		200	{object}	db.Transfer
+//	@Success		200	{object}	transferResponse
+//	@Security		BearerAuth
 //	@Router			/transfers/{id} [get]
 func (server *Server) GetTransferById(ctx *gin.Context) {
 	var req getTransferByIdRequest
@@ -121,26 +365,241 @@ func (server *Server) GetTransferById(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, transfer)
+	if !server.transferVisible(ctx, transfer) {
+		return
+	}
+
+	reversals, err := server.store.ListTransferReversals(ctx, &transfer.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := transferResponse{Transfer: transfer, Reversals: reversals}
+	for _, reversal := range reversals {
+		rsp.ReversedAmount += reversal.ToAmount
+	}
+
+	if transfer.Status != db.TransferStatusPosted {
+		hold, err := server.store.GetHoldByTransfer(ctx, transfer.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		rsp.Hold = &hold
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
 }
 
+type reverseTransferUri struct {
+	Id int64 `uri:"id" binding:"required,min=1"`
+}
 
-func (server *Server) validAccount(ctx *gin.Context, accountId int64, currency string) bool {
-	account, err := server.store.GetAccount(ctx, accountId)
+type reverseTransferRequest struct {
+	Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+// ReverseTransfer godoc
+//	@Summary		Reverse a transfer
+//	@Description	Move money from the receiver of a transfer back to the sender, only the owner of the receiving account can reverse it. The amount is in the currency of the sender and defaults to everything not reversed yet, a transfer can be reversed in parts until the whole amount is refunded. Fails with 422 and code reversal_exceeds_transfer when the amount is more than what is left, reverse_reversal when the transfer is itself a reversal, transfer_not_posted when the transfer is pending or was never posted and insufficient_funds when the receiver cannot cover it
+//	@Param			id			path	int						true	"Transfer ID"
+//	@Param			reversal	body	reverseTransferRequest	false	"Reverse Transfer Request"
+//	@Produce		application/json
+//	@Tags			transfers
+//	@Success		200	{object}	db.ReverseTransferTxResult
+//	@Security		BearerAuth
+//	@Router			/transfers/{id}/reverse [post]
+func (server *Server) ReverseTransfer(ctx *gin.Context) {
+	var uri reverseTransferUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// the body is optional when reversing everything that is left
+	var req reverseTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, uri.Id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.ownedAccount(ctx, transfer.ToAccountID); !ok {
+		return
+	}
+
+	result, err := server.store.ReverseTransferTx(ctx, db.ReverseTransferTxParams{
+		TransferID: transfer.ID,
+		Amount:     req.Amount,
+	})
+	if err != nil {
+		if accountStateError(ctx, err) || transferStateError(ctx, err) {
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// CaptureTransfer godoc
+//	@Summary		Capture a pending transfer
+//	@Description	Post a pending transfer, the held money moves to the receiver. The owner of either account can capture it. Fails with 422 and code transfer_not_pending when the transfer was already captured, voided or is not pending and hold_expired when the hold ran out
+//	@Param			id	path	int	true	"Transfer ID"
+//	@Produce		application/json
+//	@Tags			transfers
+//	@Success		200	{object}	db.TransferTxResult
+//	@Security		BearerAuth
+//	@Router			/transfers/{id}/capture [post]
+func (server *Server) CaptureTransfer(ctx *gin.Context) {
+	transfer, ok := server.pendingTransfer(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := server.store.CaptureTransferTx(ctx, db.CaptureTransferTxParams{
+		TransferID: transfer.ID,
+		Now:        time.Now(),
+	})
+	if err != nil {
+		if accountStateError(ctx, err) || transferStateError(ctx, err) {
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// VoidTransfer godoc
+//	@Summary		Void a pending transfer
+//	@Description	Cancel a pending transfer and release the held money back to the sender. The owner of either account can void it. Fails with 422 and code transfer_not_pending when the transfer was already captured, voided or is not pending
+//	@Param			id	path	int	true	"Transfer ID"
+//	@Produce		application/json
+//	@Tags			transfers
+//	@Success		200	{object}	db.VoidTransferTxResult
+//	@Security		BearerAuth
+//	@Router			/transfers/{id}/void [post]
+func (server *Server) VoidTransfer(ctx *gin.Context) {
+	transfer, ok := server.pendingTransfer(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := server.store.VoidTransferTx(ctx, transfer.ID)
+	if err != nil {
+		if transferStateError(ctx, err) {
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// pendingTransfer loads the transfer of the :id param for a capture or a
+// void, writing the error response when it cannot be found or is not
+// visible to the authenticated user. Whether it is still pending is checked
+// by the store under the hold lock
+func (server *Server) pendingTransfer(ctx *gin.Context) (db.Transfer, bool) {
+	var req getTransferByIdRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.Transfer{}, false
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, req.Id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return false
+			return transfer, false
 		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return transfer, false
+	}
+
+	return transfer, server.transferVisible(ctx, transfer)
+}
+
+// transferStateError writes the 422 response for the errors returned when
+// the state of a transfer does not allow the operation, it returns false
+// for any other error
+func transferStateError(ctx *gin.Context, err error) bool {
+	var code string
+	switch {
+	case errors.Is(err, db.ErrReversalExceedsTransfer):
+		code = errCodeReversalExceedsTransfer
+	case errors.Is(err, db.ErrReverseReversal):
+		code = errCodeReverseReversal
+	case errors.Is(err, db.ErrReversalTooSmall):
+		code = errCodeReversalTooSmall
+	case errors.Is(err, db.ErrTransferNotPending):
+		code = errCodeTransferNotPending
+	case errors.Is(err, db.ErrTransferNotPosted):
+		code = errCodeTransferNotPosted
+	case errors.Is(err, db.ErrHoldExpired):
+		code = errCodeHoldExpired
+	default:
 		return false
 	}
 
+	ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(err, code))
+	return true
+}
+
+// transferVisible checks that the authenticated user owns either side of the
+// transfer, writing the error response when they do not
+func (server *Server) transferVisible(ctx *gin.Context, transfer db.Transfer) bool {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if err := banking.TransferVisible(ctx, server.store, authPayload.Username, transfer); err != nil {
+		bankingError(ctx, err)
+		return false
+	}
+
+	return true
+}
+
+
+func (server *Server) validAccount(ctx *gin.Context, accountId int64, currency string) (db.Account, bool) {
+	account, valid := server.existingAccount(ctx, accountId)
+	if !valid {
+		return account, false
+	}
+
 	if account.Currency != currency {
 		err := fmt.Errorf("account %d has different currency", accountId)
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return false
+		return account, false
 	}
 
-	return true
-}
\ No newline at end of file
+	return account, true
+}
+// existingAccount loads the account, writing the error response when it
+// cannot be found
+func (server *Server) existingAccount(ctx *gin.Context, accountId int64) (db.Account, bool) {
+	account, err := server.store.GetAccount(ctx, accountId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return account, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return account, false
+	}
+
+	return account, true
+}
@@ -2,149 +2,1423 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/dbtest"
 	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
 	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
 	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
 
-func randomTransfer(account1 db.Account, account2 db.Account) db.Transfer {
-	return db.Transfer{
-		ID:           util.RandomInt(1, 1000),
-		FromAccountID: account1.ID,
-		ToAccountID: account2.ID,
-		Amount:       util.RandomAmount(),
+func TestCreateTransferAPI(t *testing.T) {
+
+	amount := int64(10)
+
+	user1, _ := dbtest.RandomUser(t)
+	user2, _ := dbtest.RandomUser(t)
+	user3, _ := dbtest.RandomUser(t)
+
+	account1 := dbtest.RandomAccount(user1.Username)
+	account2 := dbtest.RandomAccount(user2.Username)
+	account3 := dbtest.RandomAccount(user3.Username)
+
+	account1.Currency = "USD"
+	account2.Currency = "USD"
+	account3.Currency = "EUR"
+
+	quote := randomFxQuote(user1.Username, "USD", "EUR")
+
+	testCases := []struct {
+		name        string
+		query       string
+		body        string
+		setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rec *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID: account2.ID,
+					Amount: amount,
+				}
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, rec.Code)
+			},
+		},
+		{
+			name: "FromAccountNotFound",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, rec.Code)
+			},
+		},
+		{
+			name: "ToAccountNotFound",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, rec.Code)
+			},
+		},
+		{
+			name: "FromAccountCurrencyMismatch",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account3.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user3.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name: "FXTransfer",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account3.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+
+				rate := db.ExchangeRate{FromCurrency: "USD", ToCurrency: "EUR", Rate: "0.92000000", SpreadBps: 50}
+				store.EXPECT().
+					GetExchangeRate(gomock.Any(), gomock.Eq(db.GetExchangeRateParams{FromCurrency: "USD", ToCurrency: "EUR"})).
+					Times(1).
+					Return(rate, nil)
+
+				arg := db.FXTransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account3.ID,
+					Amount:        amount,
+					Rate:          rate.Rate,
+					SpreadBps:     rate.SpreadBps,
+				}
+				store.EXPECT().FXTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			name: "FXTransferWithQuote",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD", "quote_id": "%s"}`, account1.ID, account3.ID, quote.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Eq(quote.ID)).Times(1).Return(quote, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+
+				arg := db.FXTransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account3.ID,
+					Amount:        amount,
+					Rate:          quote.Rate,
+					SpreadBps:     quote.SpreadBps,
+				}
+				store.EXPECT().FXTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			name: "FXTransferTooSmall",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 1, "currency": "USD"}`, account1.ID, account3.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().
+					GetExchangeRate(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ExchangeRate{FromCurrency: "USD", ToCurrency: "EUR", Rate: "0.92000000", SpreadBps: 50}, nil)
+				store.EXPECT().
+					FXTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrConversionTooSmall)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+				requireErrorCode(t, rec.Body, errCodeConversionTooSmall)
+			},
+		},
+		{
+			name: "ExpiredQuote",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD", "quote_id": "%s"}`, account1.ID, account3.ID, quote.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				expired := quote
+				expired.ExpiresAt = time.Now().Add(-time.Second)
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Eq(quote.ID)).Times(1).Return(expired, nil)
+				store.EXPECT().FXTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+			},
+		},
+		{
+			name: "QuoteNotOwned",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD", "quote_id": "%s"}`, account1.ID, account3.ID, quote.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				other := quote
+				other.Username = user2.Username
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().GetFxQuote(gomock.Any(), gomock.Eq(quote.ID)).Times(1).Return(other, nil)
+				store.EXPECT().FXTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			name: "ExchangeRateUnavailable",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account3.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(1).Return(db.ExchangeRate{}, sql.ErrNoRows)
+				store.EXPECT().FXTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+				var body map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &body)
+				require.NoError(t, err)
+				require.Equal(t, errCodeExchangeRateUnavailable, body["code"])
+			},
+		},
+		{
+			name: "NegativeAmount",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": -10, "currency": "USD"}`, account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name: "Get Account Error",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrConnDone)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, rec.Code)
+			},
+		},
+		{
+			name: "TransferTx Error",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, sql.ErrTxDone)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, rec.Code)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+				var body map[string]string
+				err := json.Unmarshal(rec.Body.Bytes(), &body)
+				require.NoError(t, err)
+				require.Equal(t, errCodeInsufficientFunds, body["code"])
+			},
+		},
+		{
+			name: "FrozenAccount",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrAccountFrozen)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+				requireErrorCode(t, rec.Body, errCodeAccountFrozen)
+			},
+		},
+		{
+			name:  "Pending",
+			query: "?mode=pending",
+			body:  fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+						require.Equal(t, amount, arg.Amount)
+						require.NotNil(t, arg.HoldExpiresAt)
+						require.WithinDuration(t, time.Now().Add(time.Hour), *arg.HoldExpiresAt, time.Minute)
+
+						hold := db.Hold{AccountID: account1.ID, Amount: amount, Status: db.HoldStatusActive, ExpiresAt: *arg.HoldExpiresAt}
+						return db.TransferTxResult{Transfer: db.Transfer{Status: db.TransferStatusPending}, Hold: &hold}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+
+				var body db.TransferTxResult
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				require.Equal(t, db.TransferStatusPending, body.Transfer.Status)
+				require.NotNil(t, body.Hold)
+			},
+		},
+		{
+			name: "Details",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD", "description": "rent march", "external_reference": "INV-2024-001", "metadata": {"invoice": 1}}`, account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID:     account1.ID,
+					ToAccountID:       account2.ID,
+					Amount:            amount,
+					Description:       "rent march",
+					ExternalReference: "INV-2024-001",
+					Metadata:          json.RawMessage(`{"invoice": 1}`),
+				}
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+			},
+		},
+		{
+			name: "DescriptionTooLong",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD", "description": "%s"}`, account1.ID, account2.ID, strings.Repeat("a", 256)),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name: "ExternalReferenceTooLong",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD", "external_reference": "%s"}`, account1.ID, account2.ID, strings.Repeat("a", 65)),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name: "MetadataNotObject",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD", "metadata": [1, 2]}`, account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name: "MetadataTooLarge",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD", "metadata": {"note": "%s"}}`, account1.ID, account2.ID, strings.Repeat("a", util.MaxMetadataSize)),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name:  "InvalidMode",
+			query: "?mode=later",
+			body:  fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name: "InvalidCurrency",
+			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "AAA"}`, account1.ID, account2.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			rec := httptest.NewRecorder()
+
+			url := "/api/v1/transfers" + tc.query
+			req, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenMaker)
+			server.router.ServeHTTP(rec, req)
+			tc.checkResponse(t, rec)
+		})
+	}
+}
+
+func TestGetTransfersByAccountAPI(t *testing.T) {
+	user1, _ := dbtest.RandomUser(t)
+	user2, _ := dbtest.RandomUser(t)
+
+	account1 := dbtest.RandomAccount(user1.Username)
+	account2 := dbtest.RandomAccount(user2.Username)
+
+	n := 5
+	transfers := make([]db.Transfer, n)
+	for i := 0; i < n; i++ {
+		transfers[i] = dbtest.RandomTransfer(account1, account2)
+	}
+
+	cursorTime := time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	type Query struct {
+		Id int64
+		Cursor string
+		Size int
+		Filters string
+	}
+
+	testCases := []struct {
+		name string
+		query Query
+		setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rec *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			query: Query{
+				Id: account1.ID,
+				Size: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+
+				arg := db.ListTransfersParams{
+					AccountID: account1.ID,
+					Size: int32(n) + 1,
+				}
+
+				store.EXPECT().
+					ListTransfers(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(transfers, nil)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+				require.Len(t, transfers, n)
+				requireBodyMatchTransfers(t, rec.Body, transfers, nil)
+			},
+		},
+		{
+			name: "NextCursor",
+			query: Query{
+				Id: account1.ID,
+				Size: n - 1,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+
+				arg := db.ListTransfersParams{
+					AccountID: account1.ID,
+					Size: int32(n),
+				}
+
+				store.EXPECT().
+					ListTransfers(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(transfers, nil)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+				last := transfers[n-2]
+				requireBodyMatchTransfers(t, rec.Body, transfers[:n-1], util.EncodeCursor(last.CreatedAt, last.ID))
+			},
+		},
+		{
+			name: "WithCursor",
+			query: Query{
+				Id: account1.ID,
+				Cursor: *util.EncodeCursor(cursorTime, transfers[0].ID),
+				Size: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+
+				arg := db.ListTransfersParams{
+					AccountID: account1.ID,
+					AfterCreatedAt: cursorTime,
+					AfterID: transfers[0].ID,
+					Size: int32(n) + 1,
+				}
+
+				store.EXPECT().
+					ListTransfers(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(transfers[1:], nil)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+				requireBodyMatchTransfers(t, rec.Body, transfers[1:], nil)
+			},
+		},
+		{
+			name: "Filters",
+			query: Query{
+				Id: account1.ID,
+				Size: n,
+				Filters: fmt.Sprintf("&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z&min_amount=10&max_amount=500&direction=outgoing&counterparty_id=%d", account2.ID),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+
+				arg := db.ListTransfersParams{
+					AccountID: account1.ID,
+					FromTime: sql.NullTime{Time: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+					ToTime: sql.NullTime{Time: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+					MinAmount: sql.NullInt64{Int64: 10, Valid: true},
+					MaxAmount: sql.NullInt64{Int64: 500, Valid: true},
+					Direction: sql.NullString{String: "outgoing", Valid: true},
+					CounterpartyID: sql.NullInt64{Int64: account2.ID, Valid: true},
+					Size: int32(n) + 1,
+				}
+
+				store.EXPECT().
+					ListTransfers(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(transfers, nil)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+				requireBodyMatchTransfers(t, rec.Body, transfers, nil)
+			},
+		},
+		{
+			name: "Reference",
+			query: Query{
+				Id: account1.ID,
+				Size: n,
+				Filters: "&reference=INV-2024-001",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+
+				arg := db.ListTransfersParams{
+					AccountID: account1.ID,
+					Reference: sql.NullString{String: "INV-2024-001", Valid: true},
+					Size: int32(n) + 1,
+				}
+
+				store.EXPECT().
+					ListTransfers(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(transfers[:1], nil)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+				requireBodyMatchTransfers(t, rec.Body, transfers[:1], nil)
+			},
+		},
+		{
+			name: "ReferenceTooLong",
+			query: Query{
+				Id: account1.ID,
+				Size: n,
+				Filters: "&reference=" + strings.Repeat("a", 65),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			query: Query{
+				Id: account1.ID,
+				Size: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			query: Query{
+				Id: account1.ID,
+				Size: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, rec.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			query: Query{
+				Id: account1.ID,
+				Size: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					ListTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Transfer{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, rec.Code)
+			},
+		},
+		{
+			name: "InvalidId",
+			query: Query{
+				Id: 0,
+				Size: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name: "InvalidCursor",
+			query: Query{
+				Id: account1.ID,
+				Cursor: "not-a-cursor",
+				Size: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name: "InvalidSize",
+			query: Query{
+				Id: account1.ID,
+				Size: 0,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name: "InvalidDirection",
+			query: Query{
+				Id: account1.ID,
+				Size: n,
+				Filters: "&direction=credit",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name: "InvalidTimeRange",
+			query: Query{
+				Id: account1.ID,
+				Size: n,
+				Filters: "&from=2024-02-01T00:00:00Z&to=2024-01-01T00:00:00Z",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name: "InvalidAmountRange",
+			query: Query{
+				Id: account1.ID,
+				Size: n,
+				Filters: "&min_amount=500&max_amount=10",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+		{
+			name: "SameCounterparty",
+			query: Query{
+				Id: account1.ID,
+				Size: n,
+				Filters: fmt.Sprintf("&counterparty_id=%d", account1.ID),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			rec := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/v1/transfers?id=%d&cursor=%s&size=%d%s", tc.query.Id, tc.query.Cursor, tc.query.Size, tc.query.Filters)
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenMaker)
+			server.router.ServeHTTP(rec, req)
+			tc.checkResponse(t, rec)
+		})
+	}
+}
+
+func TestListUserTransfersAPI(t *testing.T) {
+	user, _ := dbtest.RandomUser(t)
+	account1 := dbtest.RandomAccount(user.Username)
+	account2 := dbtest.RandomAccount(util.RandomName())
+
+	transfers := []db.Transfer{
+		dbtest.RandomTransfer(account1, account2),
+		dbtest.RandomTransfer(account2, account1),
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		query         string
+		authUsername  string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:         "Banker",
+			username:     user.Username,
+			query:        "size=1",
+			authUsername: testBankerUsername,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+
+				arg := db.ListUserTransfersParams{Username: user.Username, Size: 2}
+				store.EXPECT().ListUserTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return(transfers, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				last := transfers[0]
+				requireBodyMatchTransfers(t, recorder.Body, transfers[:1], util.EncodeCursor(last.CreatedAt, last.ID))
+			},
+		},
+		{
+			name:         "OwnTransfersAsCustomer",
+			username:     user.Username,
+			query:        "size=10",
+			authUsername: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListUserTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:         "UserNotFound",
+			username:     user.Username,
+			query:        "size=10",
+			authUsername: testAdminUsername,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().ListUserTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:         "InvalidSize",
+			username:     user.Username,
+			query:        "size=0",
+			authUsername: testBankerUsername,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:         "InternalError",
+			username:     user.Username,
+			query:        "size=10",
+			authUsername: testBankerUsername,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().ListUserTransfers(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/v1/admin/users/%s/transfers?%s", tc.username, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.authUsername, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetTransferByIdAPI(t *testing.T) {
+	user1, _ := dbtest.RandomUser(t)
+	user2, _ := dbtest.RandomUser(t)
+
+	account1 := dbtest.RandomAccount(user1.Username)
+	account2 := dbtest.RandomAccount(user2.Username)
+
+	transfer := dbtest.RandomTransfer(account1, account2)
+	transfer.ToAmount = transfer.Amount
+
+	reversal := dbtest.RandomTransfer(account2, account1)
+	reversal.Amount = transfer.Amount / 2
+	reversal.ToAmount = reversal.Amount
+	reversal.ReversalOf = &transfer.ID
+
+	pending := dbtest.RandomTransfer(account1, account2)
+	pending.Status = db.TransferStatusPending
+	hold := db.Hold{ID: 1, TransferID: pending.ID, AccountID: account1.ID, Amount: pending.Amount, Status: db.HoldStatusActive}
+
+	testCases := []struct {
+		name string
+		id int64
+		setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rec *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			id: transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).
+					Times(1).
+					Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					ListTransferReversals(gomock.Any(), gomock.Eq(&transfer.ID)).
+					Times(1).
+					Return([]db.Transfer{reversal}, nil)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+				requireBodyMatchTransferReversals(t, rec.Body, transfer, []db.Transfer{reversal}, reversal.ToAmount)
+			},
+		},
+		{
+			name: "OKReceiver",
+			id: transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).
+					Times(1).
+					Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					ListTransferReversals(gomock.Any(), gomock.Eq(&transfer.ID)).
+					Times(1).
+					Return([]db.Transfer{}, nil)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+				requireBodyMatchTransferReversals(t, rec.Body, transfer, []db.Transfer{}, 0)
+			},
+		},
+		{
+			name: "Pending",
+			id: pending.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(pending.ID)).
+					Times(1).
+					Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					ListTransferReversals(gomock.Any(), gomock.Eq(&pending.ID)).
+					Times(1).
+					Return([]db.Transfer{}, nil)
+				store.EXPECT().
+					GetHoldByTransfer(gomock.Any(), gomock.Eq(pending.ID)).
+					Times(1).
+					Return(hold, nil)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+
+				var body transferResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				require.Equal(t, pending, body.Transfer)
+				require.NotNil(t, body.Hold)
+				require.Equal(t, hold.ID, body.Hold.ID)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			id: transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized_user", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).
+					Times(1).
+					Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			id: transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, rec.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			id: transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Transfer{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, rec.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			id: transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Transfer{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, rec.Code)
+			},
+		},
+		{
+			name: "InvalidId",
+			id: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, rec.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			rec := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/v1/transfers/%d", tc.id)
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, req, server.tokenMaker)
+			server.router.ServeHTTP(rec, req)
+			tc.checkResponse(t, rec)
+		})
 	}
 }
 
-func TestCreateTransferAPI(t *testing.T) {
+func TestReverseTransferAPI(t *testing.T) {
+	user1, _ := dbtest.RandomUser(t)
+	user2, _ := dbtest.RandomUser(t)
 
-	amount := int64(10)
+	account1 := dbtest.RandomAccount(user1.Username)
+	account2 := dbtest.RandomAccount(user2.Username)
+	account2.ID = account1.ID + 1
 
-	account1 := randomAccount()
-	account2 := randomAccount()
-	account3 := randomAccount()
+	transfer := dbtest.RandomTransfer(account1, account2)
+	transfer.ToAmount = transfer.Amount
 
-	account1.Currency = "USD"
-	account2.Currency = "USD"
-	account3.Currency = "EUR"
+	reversal := db.ReverseTransferTxResult{
+		TransferTxResult: db.TransferTxResult{
+			Transfer: db.Transfer{
+				ID:            transfer.ID + 1,
+				FromAccountID: account2.ID,
+				ToAccountID:   account1.ID,
+				Amount:        transfer.Amount,
+				ToAmount:      transfer.Amount,
+				ReversalOf:    &transfer.ID,
+				Metadata:      json.RawMessage(`{}`),
+			},
+		},
+		Original: transfer,
+	}
 
 	testCases := []struct {
-		name        string
-		body        string
-		buildStubs func(store *mockdb.MockStore)
+		name          string
+		id            int64
+		body          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, rec *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
-			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account2.ID),
+			id:   transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
 
-				arg := db.TransferTxParams{
-					FromAccountID: account1.ID,
-					ToAccountID: account2.ID,
-					Amount: amount,
-				}
+				arg := db.ReverseTransferTxParams{TransferID: transfer.ID}
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(reversal, nil)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, rec.Code)
+
+				var body db.ReverseTransferTxResult
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				require.Equal(t, reversal, body)
+			},
+		},
+		{
+			name: "Partial",
+			id:   transfer.ID,
+			body: `{"amount": 1}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
 
+				arg := db.ReverseTransferTxParams{TransferID: transfer.ID, Amount: 1}
 				store.EXPECT().
-					TransferTx(gomock.Any(), gomock.Eq(arg)).
-					Times(1)
+					ReverseTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(reversal, nil)
 			},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, rec.Code)
 			},
 		},
 		{
-			name: "FromAccountNotFound",
-			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account2.ID),
+			name: "Sender",
+			id:   transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, rec.Code)
+				require.Equal(t, http.StatusForbidden, rec.Code)
 			},
 		},
 		{
-			name: "ToAccountNotFound",
-			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account2.ID),
+			name: "ExceedsTransfer",
+			id:   transfer.ID,
+			body: fmt.Sprintf(`{"amount": %d}`, transfer.Amount+1),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrReversalExceedsTransfer)
 			},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, rec.Code)
+				require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+				requireErrorCode(t, rec.Body, errCodeReversalExceedsTransfer)
 			},
 		},
 		{
-			name: "FromAccountCurrencyMismatch",
-			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account3.ID),
+			name: "ReverseReversal",
+			id:   transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrReverseReversal)
 			},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, rec.Code)
+				require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+				requireErrorCode(t, rec.Body, errCodeReverseReversal)
 			},
 		},
 		{
-			name: "ToAccountCurrencyMismatch",
-			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account3.ID),
+			name: "InsufficientFunds",
+			id:   transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrInsufficientFunds)
 			},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, rec.Code)
+				require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+				requireErrorCode(t, rec.Body, errCodeInsufficientFunds)
 			},
 		},
 		{
-			name: "NegativeAmount",
-			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": -10, "currency": "USD"}`, account1.ID, account2.ID),
-			buildStubs: func(store *mockdb.MockStore) {},
+			name: "InvalidAmount",
+			id:   transfer.ID,
+			body: `{"amount": -1}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, rec.Code)
 			},
 		},
 		{
-			name: "Get Account Error",
-			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account2.ID),
+			name: "NotFound",
+			id:   transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrConnDone)
-				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Any()).Times(1).Return(db.Transfer{}, sql.ErrNoRows)
+				store.EXPECT().ReverseTransferTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, rec.Code)
+				require.Equal(t, http.StatusNotFound, rec.Code)
 			},
 		},
 		{
-			name: "TransferTx Error",
-			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "USD"}`, account1.ID, account2.ID),
+			name: "InternalError",
+			id:   transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
-				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, sql.ErrTxDone)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, rec.Code)
 			},
 		},
 		{
-			name: "InvalidCurrency",
-			body: fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "amount": 10, "currency": "AAA"}`, account1.ID, account2.ID),
-			buildStubs: func(store *mockdb.MockStore) {},
+			name:      "NoAuthorization",
+			id:        transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, rec.Code)
+				require.Equal(t, http.StatusUnauthorized, rec.Code)
 			},
 		},
 	}
@@ -159,115 +1433,182 @@ func TestCreateTransferAPI(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
-			server := NewServer(store)
+			server := newTestServer(t, store)
 			rec := httptest.NewRecorder()
 
-			url := "/api/v1/transfers"
+			url := fmt.Sprintf("/api/v1/transfers/%d/reverse", tc.id)
 			req, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(tc.body))
 			require.NoError(t, err)
 
+			tc.setupAuth(t, req, server.tokenMaker)
 			server.router.ServeHTTP(rec, req)
 			tc.checkResponse(t, rec)
 		})
 	}
 }
 
-func TestGetTransfersByAccountAPI(t *testing.T) {
-	account1 := randomAccount()
-	account2 := randomAccount()
+func TestCaptureTransferAPI(t *testing.T) {
+	user1, _ := dbtest.RandomUser(t)
+	user2, _ := dbtest.RandomUser(t)
 
-	n := 5
-	transfers := make([]db.Transfer, n)
-	for i := 0; i < n; i++ {
-		transfers[i] = randomTransfer(account1, account2)
-	}
+	account1 := dbtest.RandomAccount(user1.Username)
+	account2 := dbtest.RandomAccount(user2.Username)
+	account2.ID = account1.ID + 1
 
-	type Query struct {
-		Id int64
-		Page int
-		Size int
-	}
+	transfer := dbtest.RandomTransfer(account1, account2)
+	transfer.Status = db.TransferStatusPending
+
+	posted := transfer
+	posted.Status = db.TransferStatusPosted
 
 	testCases := []struct {
-		name string
-		query Query
-		buildStubs func(store *mockdb.MockStore)
+		name          string
+		id            int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, rec *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
-			query: Query{
-				Id: account1.ID,
-				Page: 1,
-				Size: n,
+			id:   transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.GetTransfersByAccountParams{
-					ID: account1.ID,
-					Off: 0,
-					Size: int32(n),
-				}
-
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
 				store.EXPECT().
-					GetTransfersByAccount(gomock.Any(), gomock.Eq(arg)).
+					CaptureTransferTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(transfers, nil)
+					DoAndReturn(func(ctx context.Context, arg db.CaptureTransferTxParams) (db.TransferTxResult, error) {
+						require.Equal(t, transfer.ID, arg.TransferID)
+						require.WithinDuration(t, time.Now(), arg.Now, time.Minute)
+						return db.TransferTxResult{Transfer: posted}, nil
+					})
 			},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, rec.Code)
-				require.Len(t, transfers, n)
-				requireBodyMatchTransfers(t, rec.Body, transfers)
+
+				var body db.TransferTxResult
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				require.Equal(t, posted, body.Transfer)
 			},
 		},
 		{
-			name: "InternalError",
-			query: Query{
-				Id: account1.ID,
-				Page: 1,
-				Size: n,
+			name: "NotPending",
+			id:   transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
 				store.EXPECT().
-					GetTransfersByAccount(gomock.Any(), gomock.Any()).
+					CaptureTransferTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return([]db.Transfer{}, sql.ErrConnDone)
+					Return(db.TransferTxResult{}, db.ErrTransferNotPending)
 			},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, rec.Code)
+				require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+				requireErrorCode(t, rec.Body, errCodeTransferNotPending)
 			},
 		},
 		{
-			name: "InvalidId",
-			query: Query{
-				Id: 0,
-				Page: 1,
-				Size: n,
+			name: "HoldExpired",
+			id:   transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					CaptureTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrHoldExpired)
 			},
-			buildStubs: func(store *mockdb.MockStore) {},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, rec.Code)
+				require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+				requireErrorCode(t, rec.Body, errCodeHoldExpired)
 			},
 		},
 		{
-			name: "InvalidPage",
-			query: Query{
-				Id: account1.ID,
-				Page: 0,
-				Size: n,
+			name: "FrozenAccount",
+			id:   transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					CaptureTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrAccountFrozen)
 			},
-			buildStubs: func(store *mockdb.MockStore) {},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, rec.Code)
+				require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+				requireErrorCode(t, rec.Body, errCodeAccountFrozen)
 			},
 		},
 		{
-			name: "InvalidSize",
-			query: Query{
-				Id: account1.ID,
-				Page: 1,
-				Size: 0,
+			name: "UnauthorizedUser",
+			id:   transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized_user", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CaptureTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			id:   transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Any()).Times(1).Return(db.Transfer{}, sql.ErrNoRows)
+				store.EXPECT().CaptureTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, rec.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			id:   transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					CaptureTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, rec.Code)
+			},
+		},
+		{
+			name: "InvalidId",
+			id:   0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Any()).Times(0)
 			},
-			buildStubs: func(store *mockdb.MockStore) {},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, rec.Code)
 			},
@@ -284,77 +1625,129 @@ func TestGetTransfersByAccountAPI(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
-			server := NewServer(store)
+			server := newTestServer(t, store)
 			rec := httptest.NewRecorder()
 
-			url := fmt.Sprintf("/api/v1/transfers?id=%d&page=%d&size=%d", tc.query.Id, tc.query.Page, tc.query.Size)
-			req, err := http.NewRequest(http.MethodGet, url, nil)
+			url := fmt.Sprintf("/api/v1/transfers/%d/capture", tc.id)
+			req, err := http.NewRequest(http.MethodPost, url, nil)
 			require.NoError(t, err)
 
+			tc.setupAuth(t, req, server.tokenMaker)
 			server.router.ServeHTTP(rec, req)
 			tc.checkResponse(t, rec)
 		})
 	}
 }
 
-func TestGetTransferByIdAPI(t *testing.T) {
-	account1 := randomAccount()
-	account2 := randomAccount()
+func TestVoidTransferAPI(t *testing.T) {
+	user1, _ := dbtest.RandomUser(t)
+	user2, _ := dbtest.RandomUser(t)
+
+	account1 := dbtest.RandomAccount(user1.Username)
+	account2 := dbtest.RandomAccount(user2.Username)
+	account2.ID = account1.ID + 1
 
-	transfer := randomTransfer(account1, account2)
+	transfer := dbtest.RandomTransfer(account1, account2)
+	transfer.Status = db.TransferStatusPending
+
+	voided := db.VoidTransferTxResult{
+		Transfer: transfer,
+		Account:  account1,
+		Hold:     db.Hold{ID: 1, TransferID: transfer.ID, AccountID: account1.ID, Amount: transfer.Amount, Status: db.HoldStatusVoided},
+	}
+	voided.Transfer.Status = db.TransferStatusVoided
 
 	testCases := []struct {
-		name string
-		id int64
-		buildStubs func(store *mockdb.MockStore)
+		name          string
+		id            int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, rec *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
-			id: transfer.ID,
+			id:   transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
 				store.EXPECT().
-					GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).
+					VoidTransferTx(gomock.Any(), gomock.Eq(transfer.ID)).
 					Times(1).
-					Return(transfer, nil)
+					Return(voided, nil)
 			},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, rec.Code)
-				requireBodyMatchTransfer(t, rec.Body, transfer)
+
+				var body db.VoidTransferTxResult
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				require.Equal(t, voided, body)
 			},
 		},
 		{
-			name: "InternalError",
-			id: transfer.ID,
+			name: "NotPending",
+			id:   transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
 				store.EXPECT().
-					GetTransfer(gomock.Any(), gomock.Any()).
+					VoidTransferTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.Transfer{}, sql.ErrConnDone)
+					Return(db.VoidTransferTxResult{}, db.ErrTransferNotPending)
 			},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, rec.Code)
+				require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+				requireErrorCode(t, rec.Body, errCodeTransferNotPending)
 			},
 		},
 		{
-			name: "NotFound",
-			id: transfer.ID,
+			name: "UnauthorizedUser",
+			id:   transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized_user", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().VoidTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, rec.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			id:   transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
 				store.EXPECT().
-					GetTransfer(gomock.Any(), gomock.Any()).
+					VoidTransferTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.Transfer{}, sql.ErrNoRows)
+					Return(db.VoidTransferTxResult{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, rec.Code)
+				require.Equal(t, http.StatusInternalServerError, rec.Code)
 			},
 		},
 		{
-			name: "InvalidId",
-			id: 0,
-			buildStubs: func(store *mockdb.MockStore) {},
+			name:      "NoAuthorization",
+			id:        transfer.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
 			checkResponse: func(t *testing.T, rec *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, rec.Code)
+				require.Equal(t, http.StatusUnauthorized, rec.Code)
 			},
 		},
 	}
@@ -369,35 +1762,52 @@ func TestGetTransferByIdAPI(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
-			server := NewServer(store)
+			server := newTestServer(t, store)
 			rec := httptest.NewRecorder()
 
-			url := fmt.Sprintf("/api/v1/transfers/%d", tc.id)
-			req, err := http.NewRequest(http.MethodGet, url, nil)
+			url := fmt.Sprintf("/api/v1/transfers/%d/void", tc.id)
+			req, err := http.NewRequest(http.MethodPost, url, nil)
 			require.NoError(t, err)
 
+			tc.setupAuth(t, req, server.tokenMaker)
 			server.router.ServeHTTP(rec, req)
 			tc.checkResponse(t, rec)
 		})
 	}
 }
 
-func requireBodyMatchTransfer(t *testing.T, body *bytes.Buffer, transfer db.Transfer) {
+func requireBodyMatchTransferReversals(t *testing.T, body *bytes.Buffer, transfer db.Transfer, reversals []db.Transfer, reversedAmount int64) {
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
 
-	var gotTransfer db.Transfer
+	var gotTransfer transferResponse
 	err = json.Unmarshal(data, &gotTransfer)
 	require.NoError(t, err)
-	require.Equal(t, transfer, gotTransfer)
+	require.Equal(t, transfer, gotTransfer.Transfer)
+	require.Equal(t, reversals, gotTransfer.Reversals)
+	require.Equal(t, reversedAmount, gotTransfer.ReversedAmount)
 }
 
-func requireBodyMatchTransfers(t *testing.T, body *bytes.Buffer, transfers []db.Transfer) {
+func requireBodyMatchTransfers(t *testing.T, body *bytes.Buffer, transfers []db.Transfer, nextCursor *string) {
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
 
-	var gotTransfers []db.Transfer
-	err = json.Unmarshal(data, &gotTransfers)
+	var gotPage struct {
+		Data       []db.Transfer `json:"data"`
+		NextCursor *string       `json:"next_cursor"`
+	}
+	err = json.Unmarshal(data, &gotPage)
+	require.NoError(t, err)
+	require.Equal(t, transfers, gotPage.Data)
+	require.Equal(t, nextCursor, gotPage.NextCursor)
+}
+
+func requireBodyMatchTransferResult(t *testing.T, body *bytes.Buffer, result db.TransferTxResult) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotResult db.TransferTxResult
+	err = json.Unmarshal(data, &gotResult)
 	require.NoError(t, err)
-	require.Equal(t, transfers, gotTransfers)
-}
\ No newline at end of file
+	require.Equal(t, result, gotResult)
+}
@@ -1,14 +0,0 @@
-package api
-
-import (
-	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
-	"github.com/go-playground/validator/v10"
-)
-
-var validCurrency validator.Func = func(fl validator.FieldLevel) bool {
-	if currency, ok := fl.Field().Interface().(string); ok {
-		return util.IsSupportedCurrency(currency)
-	}
-
-	return false
-}
\ No newline at end of file
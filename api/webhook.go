@@ -0,0 +1,284 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/webhooks"
+	"github.com/gin-gonic/gin"
+)
+
+var errWebhookNotOwned = errors.New("webhook doesn't belong to the authenticated user")
+
+type createWebhookRequest struct {
+	URL        string   `json:"url" binding:"required,http_url,max=2048"`
+	EventTypes []string `json:"event_types" binding:"required,min=1,unique,dive,oneof=transfer.created account.balance_changed account.frozen"`
+	// Secret signs the deliveries, one is generated when it is empty
+	Secret string `json:"secret" binding:"omitempty,min=16,max=128"`
+}
+
+// webhookResponse hides the secret, it is only returned once when the
+// webhook is created
+type webhookResponse struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func newWebhookResponse(subscription db.WebhookSubscription) webhookResponse {
+	return webhookResponse{
+		ID:         subscription.ID,
+		URL:        subscription.Url,
+		EventTypes: subscription.EventTypes,
+		CreatedAt:  subscription.CreatedAt,
+	}
+}
+
+type listWebhooksResponse struct {
+	Data       []webhookResponse `json:"data"`
+	NextCursor *string           `json:"next_cursor"`
+}
+
+type listWebhookDeliveriesResponse struct {
+	Data       []db.WebhookDelivery `json:"data"`
+	NextCursor *string              `json:"next_cursor"`
+}
+
+// CreateWebhook godoc
+//	@Summary		Create a webhook
+//	@Description	Subscribe a URL to transfer.created, account.balance_changed and account.frozen events of the logged in user's accounts. Every delivery is signed, X-Webhook-Signature is sha256= followed by the hex HMAC-SHA256 of "<X-Webhook-Timestamp>.<body>" keyed with the secret. The secret is only returned here. URLs must point to a public address, private, loopback and link-local addresses fail with 400 and redirects are not followed
+//	@Param			webhook	body	createWebhookRequest	true	"Create Webhook Request"
+//	@Produce		application/json
+//	@Tags			webhooks
+//	@Success		201	{object}	webhookResponse
+//	@Security		BearerAuth
+//	@Router			/webhooks [post]
+func (server *Server) CreateWebhook(ctx *gin.Context) {
+	var req createWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// the worker refuses private addresses too, this only fails early
+	if err := webhooks.CheckURL(req.URL); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Secret == "" {
+		var err error
+		req.Secret, err = newWebhookSecret()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	subscription, err := server.store.CreateWebhookSubscription(ctx, db.CreateWebhookSubscriptionParams{
+		Owner:      authPayload.Username,
+		Url:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := newWebhookResponse(subscription)
+	rsp.Secret = subscription.Secret
+	ctx.JSON(http.StatusCreated, rsp)
+}
+
+type listWebhooksRequest struct {
+	Cursor string `form:"cursor"`
+	Size   int32  `form:"size" binding:"required,min=1,max=100"`
+}
+
+// ListWebhooks godoc
+//	@Summary		List webhooks
+//	@Description	List the webhooks of the logged in user oldest first, pass next_cursor as cursor to get the next page
+//	@Param			cursor	query	string	false	"Cursor"
+//	@Param			size	query	int		true	"Page Size"
+//	@Produce		application/json
+//	@Tags			webhooks
+//	@Success		200	{object}	listWebhooksResponse
+//	@Security		BearerAuth
+//	@Router			/webhooks [get]
+func (server *Server) ListWebhooks(ctx *gin.Context) {
+	var req listWebhooksRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	cursor, err := util.DecodeCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	subscriptions, err := server.store.ListWebhookSubscriptions(ctx, db.ListWebhookSubscriptionsParams{
+		Owner:          authPayload.Username,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+		Size:           req.Size + 1,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := listWebhooksResponse{}
+	if len(subscriptions) > int(req.Size) {
+		subscriptions = subscriptions[:req.Size]
+		last := subscriptions[len(subscriptions)-1]
+		rsp.NextCursor = util.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	rsp.Data = make([]webhookResponse, len(subscriptions))
+	for i, subscription := range subscriptions {
+		rsp.Data[i] = newWebhookResponse(subscription)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type webhookUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// DeleteWebhook godoc
+//	@Summary		Delete a webhook
+//	@Description	Stop sending events to the webhook, its pending deliveries and delivery log are deleted with it
+//	@Param			id	path	int	true	"Webhook ID"
+//	@Tags			webhooks
+//	@Success		204
+//	@Security		BearerAuth
+//	@Router			/webhooks/{id} [delete]
+func (server *Server) DeleteWebhook(ctx *gin.Context) {
+	var uri webhookUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.ownedWebhook(ctx, uri.ID); !ok {
+		return
+	}
+
+	if err := server.store.DeleteWebhookSubscription(ctx, uri.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type listWebhookDeliveriesRequest struct {
+	Cursor string `form:"cursor"`
+	Size   int32  `form:"size" binding:"required,min=1,max=100"`
+	Status string `form:"status" binding:"omitempty,oneof=pending succeeded dead"`
+}
+
+// ListWebhookDeliveries godoc
+//	@Summary		List the deliveries of a webhook
+//	@Description	List every event queued for the webhook oldest first with its status, attempts, next_attempt_at and the response_status and last_error of the last attempt. Failed deliveries are retried with an exponential backoff, dead ones ran out of attempts. Pass next_cursor as cursor to get the next page
+//	@Param			id		path	int		true	"Webhook ID"
+//	@Param			cursor	query	string	false	"Cursor"
+//	@Param			size	query	int		true	"Page Size"
+//	@Param			status	query	string	false	"pending, succeeded or dead"
+//	@Produce		application/json
+//	@Tags			webhooks
+//	@Success		200	{object}	listWebhookDeliveriesResponse
+//	@Security		BearerAuth
+//	@Router			/webhooks/{id}/deliveries [get]
+func (server *Server) ListWebhookDeliveries(ctx *gin.Context) {
+	var uri webhookUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listWebhookDeliveriesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	cursor, err := util.DecodeCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.ownedWebhook(ctx, uri.ID); !ok {
+		return
+	}
+
+	deliveries, err := server.store.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{
+		SubscriptionID: uri.ID,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+		Status:         util.NullString(req.Status),
+		Size:           req.Size + 1,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := listWebhookDeliveriesResponse{Data: deliveries}
+	if len(deliveries) > int(req.Size) {
+		rsp.Data = deliveries[:req.Size]
+		last := rsp.Data[len(rsp.Data)-1]
+		rsp.NextCursor = util.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// ownedWebhook loads the webhook and checks that it belongs to the
+// authenticated user, writing the error response when it does not
+func (server *Server) ownedWebhook(ctx *gin.Context, id int64) (db.WebhookSubscription, bool) {
+	subscription, err := server.store.GetWebhookSubscription(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return subscription, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return subscription, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if subscription.Owner != authPayload.Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(errWebhookNotOwned))
+		return subscription, false
+	}
+
+	return subscription, true
+}
+
+// newWebhookSecret returns 32 random bytes hex encoded
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
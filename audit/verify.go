@@ -0,0 +1,74 @@
+// Package audit walks the hash chain of the audit log and finds the first row
+// that was changed, removed or inserted after it was written
+package audit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+)
+
+// DefaultBatchSize is the number of audit log rows read per query
+const DefaultBatchSize int32 = 1000
+
+// Reasons a link of the chain is broken
+const (
+	ReasonPrevHashMismatch = "prev_hash does not match the hash of the previous row"
+	ReasonHashMismatch     = "hash does not match the contents of the row"
+)
+
+// Break is the first row the chain does not hold at
+type Break struct {
+	ID     int64  `json:"id"`
+	Reason string `json:"reason"`
+}
+
+type Result struct {
+	Checked int64 `json:"checked"`
+	// Break is nil when every row is linked to the one before it
+	Break *Break `json:"break,omitempty"`
+}
+
+// Verify reads the audit log in id order and checks that every row points to
+// the hash of the row before it, the first row to the genesis hash, and that
+// every row still hashes to its stored hash. It stops at the first break
+func Verify(ctx context.Context, store db.Store, batchSize int32) (Result, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	var result Result
+	prevHash := db.GenesisHash
+
+	var afterID int64
+	for {
+		entries, err := store.ListAuditLog(ctx, db.ListAuditLogParams{
+			AfterID: afterID,
+			Size:    batchSize,
+		})
+		if err != nil {
+			return result, fmt.Errorf("cannot list audit log after %d: %w", afterID, err)
+		}
+
+		for _, entry := range entries {
+			result.Checked++
+
+			if !bytes.Equal(entry.PrevHash, prevHash) {
+				result.Break = &Break{ID: entry.ID, Reason: ReasonPrevHashMismatch}
+				return result, nil
+			}
+			if !entry.Valid() {
+				result.Break = &Break{ID: entry.ID, Reason: ReasonHashMismatch}
+				return result, nil
+			}
+			prevHash = entry.Hash
+		}
+
+		if len(entries) < int(batchSize) {
+			return result, nil
+		}
+		afterID = entries[len(entries)-1].ID
+	}
+}
@@ -0,0 +1,163 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// chain builds n linked audit log rows starting at id 1
+func chain(n int) []db.AuditLog {
+	entries := make([]db.AuditLog, n)
+	prevHash := db.GenesisHash
+	for i := range entries {
+		entry := db.AuditLog{
+			ID:        int64(i + 1),
+			Actor:     "alice",
+			Action:    db.AuditAccountDeposit,
+			TargetIds: []int64{int64(i + 1)},
+			Before:    json.RawMessage(`null`),
+			After:     json.RawMessage(`{"balance":10}`),
+			PrevHash:  prevHash,
+			CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		}
+		entry.Hash = entry.ComputeHash()
+		entries[i] = entry
+		prevHash = entry.Hash
+	}
+	return entries
+}
+
+func TestVerify(t *testing.T) {
+	testCases := []struct {
+		name        string
+		buildChain  func() []db.AuditLog
+		checkResult func(t *testing.T, result Result, err error)
+	}{
+		{
+			name:       "Intact",
+			buildChain: func() []db.AuditLog { return chain(5) },
+			checkResult: func(t *testing.T, result Result, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(5), result.Checked)
+				require.Nil(t, result.Break)
+			},
+		},
+		{
+			name:       "Empty",
+			buildChain: func() []db.AuditLog { return nil },
+			checkResult: func(t *testing.T, result Result, err error) {
+				require.NoError(t, err)
+				require.Zero(t, result.Checked)
+				require.Nil(t, result.Break)
+			},
+		},
+		{
+			name: "Tampered",
+			buildChain: func() []db.AuditLog {
+				entries := chain(5)
+				entries[3].After = json.RawMessage(`{"balance":1000}`)
+				return entries
+			},
+			checkResult: func(t *testing.T, result Result, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(4), result.Checked)
+				require.Equal(t, &Break{ID: 4, Reason: ReasonHashMismatch}, result.Break)
+			},
+		},
+		{
+			name: "Removed",
+			buildChain: func() []db.AuditLog {
+				entries := chain(5)
+				return append(entries[:2], entries[3:]...)
+			},
+			checkResult: func(t *testing.T, result Result, err error) {
+				require.NoError(t, err)
+				require.Equal(t, &Break{ID: 4, Reason: ReasonPrevHashMismatch}, result.Break)
+			},
+		},
+		{
+			name: "Rehashed",
+			buildChain: func() []db.AuditLog {
+				// a rewritten row with a fresh hash still breaks the next link
+				entries := chain(5)
+				entries[1].Actor = "mallory"
+				entries[1].Hash = entries[1].ComputeHash()
+				return entries
+			},
+			checkResult: func(t *testing.T, result Result, err error) {
+				require.NoError(t, err)
+				require.Equal(t, &Break{ID: 3, Reason: ReasonPrevHashMismatch}, result.Break)
+			},
+		},
+		{
+			name: "WrongGenesis",
+			buildChain: func() []db.AuditLog {
+				entries := chain(2)
+				entries[0].PrevHash = entries[1].Hash
+				return entries
+			},
+			checkResult: func(t *testing.T, result Result, err error) {
+				require.NoError(t, err)
+				require.Equal(t, &Break{ID: 1, Reason: ReasonPrevHashMismatch}, result.Break)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			entries := tc.buildChain()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				ListAuditLog(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				DoAndReturn(func(_ context.Context, arg db.ListAuditLogParams) ([]db.AuditLog, error) {
+					page := []db.AuditLog{}
+					for _, entry := range entries {
+						if entry.ID > arg.AfterID && len(page) < int(arg.Size) {
+							page = append(page, entry)
+						}
+					}
+					return page, nil
+				})
+
+			result, err := Verify(context.Background(), store, 2)
+			tc.checkResult(t, result, err)
+		})
+	}
+}
+
+func TestVerifyListError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().
+			ListAuditLog(gomock.Any(), gomock.Eq(db.ListAuditLogParams{AfterID: 0, Size: 2})).
+			Times(1).
+			Return(chain(2), nil),
+		store.EXPECT().
+			ListAuditLog(gomock.Any(), gomock.Eq(db.ListAuditLogParams{AfterID: 2, Size: 2})).
+			Times(1).
+			Return(nil, sql.ErrConnDone),
+	)
+
+	result, err := Verify(context.Background(), store, 2)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	require.Equal(t, int64(2), result.Checked)
+	require.Nil(t, result.Break)
+}
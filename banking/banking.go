@@ -0,0 +1,117 @@
+// Package banking holds the ownership checks, rate lookups and request
+// validation shared by the HTTP and the gRPC API, each server only maps the
+// errors returned here to its own responses
+package banking
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAccountNotOwned         = errors.New("account doesn't belong to the authenticated user")
+	ErrTransferNotOwned        = errors.New("transfer doesn't belong to the authenticated user")
+	ErrQuoteNotOwned           = errors.New("quote doesn't belong to the authenticated user")
+	ErrQuoteCurrencies         = errors.New("quote is for other currencies")
+	ErrQuoteExpired            = errors.New("quote has expired")
+	ErrExchangeRateUnavailable = errors.New("no exchange rate between the account currencies")
+)
+
+// NewCurrencyRegistry caches the currency table for ttl, the servers share
+// one registry so a currency changed through either is seen by both
+func NewCurrencyRegistry(store db.Querier, ttl time.Duration) *util.CurrencyRegistry {
+	return util.NewCurrencyRegistry(func() ([]util.Currency, error) {
+		currencies, err := store.ListCurrencies(context.Background())
+		if err != nil {
+			return nil, err
+		}
+
+		result := make([]util.Currency, len(currencies))
+		for i, currency := range currencies {
+			result[i] = util.Currency{
+				Code:        currency.Code,
+				NumericCode: currency.NumericCode,
+				Exponent:    currency.Exponent,
+				Enabled:     currency.Enabled,
+			}
+		}
+
+		return result, nil
+	}, ttl)
+}
+
+// OwnedAccount loads the account and checks that it belongs to username, a
+// missing account is sql.ErrNoRows
+func OwnedAccount(ctx context.Context, store db.Querier, username string, accountID int64) (db.Account, error) {
+	account, err := store.GetAccount(ctx, accountID)
+	if err != nil {
+		return account, err
+	}
+
+	if account.Name != username {
+		return account, ErrAccountNotOwned
+	}
+
+	return account, nil
+}
+
+// TransferVisible checks that username owns either side of the transfer
+func TransferVisible(ctx context.Context, store db.Querier, username string, transfer db.Transfer) error {
+	for _, accountID := range []int64{transfer.FromAccountID, transfer.ToAccountID} {
+		account, err := store.GetAccount(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		if account.Name == username {
+			return nil
+		}
+	}
+
+	return ErrTransferNotOwned
+}
+
+// CurrentExchangeRate loads the rate between the currencies, a missing rate
+// is ErrExchangeRateUnavailable
+func CurrentExchangeRate(ctx context.Context, store db.Querier, fromCurrency, toCurrency string) (db.ExchangeRate, error) {
+	rate, err := store.GetExchangeRate(ctx, db.GetExchangeRateParams{
+		FromCurrency: fromCurrency,
+		ToCurrency:   toCurrency,
+	})
+	if err == sql.ErrNoRows {
+		return rate, ErrExchangeRateUnavailable
+	}
+
+	return rate, err
+}
+
+// QuotedExchangeRate loads a quote created by username for the given
+// currencies and checks it has not expired, a missing quote is
+// sql.ErrNoRows
+func QuotedExchangeRate(ctx context.Context, store db.Querier, username string, quoteID uuid.UUID, fromCurrency, toCurrency string) (db.FxQuote, error) {
+	quote, err := store.GetFxQuote(ctx, quoteID)
+	if err != nil {
+		return quote, err
+	}
+
+	if quote.Username != username {
+		return quote, ErrQuoteNotOwned
+	}
+
+	if quote.FromCurrency != fromCurrency || quote.ToCurrency != toCurrency {
+		return quote, fmt.Errorf("%w: %s to %s", ErrQuoteCurrencies, quote.FromCurrency, quote.ToCurrency)
+	}
+
+	if time.Now().After(quote.ExpiresAt) {
+		return quote, ErrQuoteExpired
+	}
+
+	return quote, nil
+}
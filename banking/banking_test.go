@@ -0,0 +1,136 @@
+package banking
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/dbtest"
+	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnedAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	account := dbtest.RandomAccount(util.RandomName())
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(2).Return(account, nil)
+	store.EXPECT().GetAccount(gomock.Any(), account.ID+1).Times(1).Return(db.Account{}, sql.ErrNoRows)
+
+	got, err := OwnedAccount(context.Background(), store, account.Name, account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account, got)
+
+	_, err = OwnedAccount(context.Background(), store, util.RandomName(), account.ID)
+	require.ErrorIs(t, err, ErrAccountNotOwned)
+
+	_, err = OwnedAccount(context.Background(), store, account.Name, account.ID+1)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestTransferVisible(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	from := dbtest.RandomAccount(util.RandomName())
+	to := dbtest.RandomAccount(util.RandomName())
+	to.ID = from.ID + 1
+	transfer := dbtest.RandomTransfer(from, to)
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), from.ID).AnyTimes().Return(from, nil)
+	store.EXPECT().GetAccount(gomock.Any(), to.ID).AnyTimes().Return(to, nil)
+
+	require.NoError(t, TransferVisible(context.Background(), store, from.Name, transfer))
+	require.NoError(t, TransferVisible(context.Background(), store, to.Name, transfer))
+	require.ErrorIs(t, TransferVisible(context.Background(), store, util.RandomName(), transfer), ErrTransferNotOwned)
+}
+
+func TestCurrentExchangeRateUnavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(1).Return(db.ExchangeRate{}, sql.ErrNoRows)
+
+	_, err := CurrentExchangeRate(context.Background(), store, util.USD, util.EUR)
+	require.ErrorIs(t, err, ErrExchangeRateUnavailable)
+}
+
+func TestQuotedExchangeRate(t *testing.T) {
+	username := util.RandomName()
+
+	testCases := []struct {
+		name        string
+		quote       db.FxQuote
+		username    string
+		expectedErr error
+	}{
+		{
+			name:     "OK",
+			quote:    db.FxQuote{Username: username, FromCurrency: util.USD, ToCurrency: util.EUR, ExpiresAt: time.Now().Add(time.Minute)},
+			username: username,
+		},
+		{
+			name:        "NotOwned",
+			quote:       db.FxQuote{Username: username, FromCurrency: util.USD, ToCurrency: util.EUR, ExpiresAt: time.Now().Add(time.Minute)},
+			username:    util.RandomName(),
+			expectedErr: ErrQuoteNotOwned,
+		},
+		{
+			name:        "OtherCurrencies",
+			quote:       db.FxQuote{Username: username, FromCurrency: util.EUR, ToCurrency: util.USD, ExpiresAt: time.Now().Add(time.Minute)},
+			username:    username,
+			expectedErr: ErrQuoteCurrencies,
+		},
+		{
+			name:        "Expired",
+			quote:       db.FxQuote{Username: username, FromCurrency: util.USD, ToCurrency: util.EUR, ExpiresAt: time.Now().Add(-time.Minute)},
+			username:    username,
+			expectedErr: ErrQuoteExpired,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			quoteID := uuid.New()
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetFxQuote(gomock.Any(), quoteID).Times(1).Return(tc.quote, nil)
+
+			_, err := QuotedExchangeRate(context.Background(), store, tc.username, quoteID, util.USD, util.EUR)
+			if tc.expectedErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestValidator(t *testing.T) {
+	validate := NewValidator(dbtest.NewCurrencyRegistry())
+
+	require.NoError(t, validate.Var(util.USD, "required,currency"))
+	require.Error(t, validate.Var(util.JPY, "required,currency"))
+	require.Error(t, validate.Var("XXX", "required,currency"))
+
+	require.NoError(t, validate.Var(util.BankerRole, "required,role"))
+	require.Error(t, validate.Var("owner", "required,role"))
+
+	type request struct {
+		Currency string `binding:"required,currency"`
+	}
+	require.NoError(t, validate.Struct(request{Currency: util.EUR}))
+	require.Error(t, validate.Struct(request{}))
+}
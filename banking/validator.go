@@ -0,0 +1,35 @@
+package banking
+
+import (
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/go-playground/validator/v10"
+)
+
+// NewValidator returns a validator that reads the binding tags gin reads,
+// so a rule written for the HTTP API means the same in the gRPC API
+func NewValidator(currencies *util.CurrencyRegistry) *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	RegisterValidations(v, currencies)
+	return v
+}
+
+// RegisterValidations adds the currency and role tags to v, currency
+// accepts the codes enabled in currencies
+func RegisterValidations(v *validator.Validate, currencies *util.CurrencyRegistry) {
+	v.RegisterValidation("currency", func(fl validator.FieldLevel) bool {
+		if currency, ok := fl.Field().Interface().(string); ok {
+			return currencies.IsEnabled(currency)
+		}
+
+		return false
+	})
+
+	v.RegisterValidation("role", func(fl validator.FieldLevel) bool {
+		if role, ok := fl.Field().Interface().(string); ok {
+			return util.IsSupportedRole(role)
+		}
+
+		return false
+	})
+}
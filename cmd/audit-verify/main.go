@@ -0,0 +1,51 @@
+// Command audit-verify walks the hash chain of the audit log and prints the
+// first broken link. It exits with status 1 when the chain is broken so it
+// can back a cron alert
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/audit"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	configPath := flag.String("config", ".", "directory of app.env")
+	batchSize := flag.Int("batch-size", int(audit.DefaultBatchSize), "audit log rows read per query")
+	flag.Parse()
+
+	config, err := util.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatal("cannot load config: ", err)
+	}
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		log.Fatal("cannot connect to db: ", err)
+	}
+	defer conn.Close()
+
+	store := db.NewStore(conn)
+
+	result, err := audit.Verify(context.Background(), store, int32(*batchSize))
+	if err != nil {
+		log.Fatal("verification failed: ", err)
+	}
+
+	if result.Break != nil {
+		fmt.Printf("audit log broken at row %d: %s\n", result.Break.ID, result.Break.Reason)
+		fmt.Printf("%d rows checked\n", result.Checked)
+		os.Exit(1)
+	}
+
+	fmt.Printf("audit log intact: %d rows checked\n", result.Checked)
+}
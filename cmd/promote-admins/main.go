@@ -0,0 +1,82 @@
+// Command promote-admins gives the admin role to the users listed in
+// ADMIN_USERNAMES, or to the usernames passed as arguments. It is run once
+// when upgrading from the release that read admins from ADMIN_USERNAMES, the
+// server itself never promotes anyone. Nobody is promoted unless every
+// username is registered, so a listed name cannot be claimed by registering
+// it later
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	configPath := flag.String("config", ".", "directory of app.env")
+	flag.Parse()
+
+	config, err := util.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatal("cannot load config: ", err)
+	}
+
+	usernames := flag.Args()
+	if len(usernames) == 0 {
+		usernames = config.AdminUsernames
+	}
+	if len(usernames) == 0 {
+		log.Fatal("no usernames given and ADMIN_USERNAMES is empty")
+	}
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		log.Fatal("cannot connect to db: ", err)
+	}
+	defer conn.Close()
+
+	store := db.NewStore(conn)
+	ctx := context.Background()
+
+	var missing []string
+	users := make([]db.User, 0, len(usernames))
+	for _, username := range usernames {
+		user, err := store.GetUserByUsername(ctx, username)
+		if err == sql.ErrNoRows {
+			missing = append(missing, username)
+			continue
+		}
+		if err != nil {
+			log.Fatal("cannot get user: ", err)
+		}
+		users = append(users, user)
+	}
+
+	if len(missing) > 0 {
+		log.Fatalf("nobody promoted, users not registered: %s", strings.Join(missing, ", "))
+	}
+
+	for _, user := range users {
+		if user.Role == util.AdminRole {
+			fmt.Printf("%s is already an admin\n", user.Username)
+			continue
+		}
+
+		_, err := store.UpdateUserRole(ctx, db.UpdateUserRoleParams{
+			Username: user.Username,
+			Role:     util.AdminRole,
+		})
+		if err != nil {
+			log.Fatal("cannot promote user: ", err)
+		}
+		fmt.Printf("%s promoted from %s to admin\n", user.Username, user.Role)
+	}
+}
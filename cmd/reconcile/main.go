@@ -0,0 +1,64 @@
+// Command reconcile checks every account balance against the sum of its
+// entries, prints the mismatches and stores the run. It exits with status 1
+// while mismatches are left unadjusted so it can back a cron alert
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/reconcile"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	configPath := flag.String("config", ".", "directory of app.env")
+	adjust := flag.Bool("adjust", false, "write an adjustment entry for every mismatch")
+	batchSize := flag.Int("batch-size", int(reconcile.DefaultBatchSize), "accounts read per query")
+	flag.Parse()
+
+	config, err := util.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatal("cannot load config: ", err)
+	}
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		log.Fatal("cannot connect to db: ", err)
+	}
+	defer conn.Close()
+
+	store := db.NewStore(conn)
+
+	result, err := reconcile.Run(context.Background(), store, reconcile.Options{
+		BatchSize:   int32(*batchSize),
+		Adjust:      *adjust,
+		TriggeredBy: "cli",
+	})
+	if err != nil {
+		log.Fatal("reconciliation failed: ", err)
+	}
+
+	for _, mismatch := range result.Mismatches {
+		line := fmt.Sprintf("account %d: balance %d, entries %d, delta %d",
+			mismatch.AccountID, mismatch.Balance, mismatch.EntriesTotal, mismatch.Delta)
+		if mismatch.AdjustmentEntryID != nil {
+			line += fmt.Sprintf(", adjusted by entry %d", *mismatch.AdjustmentEntryID)
+		}
+		fmt.Println(line)
+	}
+
+	fmt.Printf("run %d: %d accounts checked, %d mismatches\n",
+		result.Run.ID, result.Run.AccountsChecked, result.Run.MismatchCount)
+
+	if result.Unadjusted() > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,95 @@
+// Package dbtest builds the random rows the API tests return from the mocked
+// store, the HTTP and the gRPC tests share them
+package dbtest
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/stretchr/testify/require"
+)
+
+// RandomUser returns a customer and the password it was hashed from
+func RandomUser(t *testing.T) (user db.User, password string) {
+	password = util.RandomString(6)
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+
+	user = db.User{
+		Username:       util.RandomName(),
+		HashedPassword: hashedPassword,
+		FullName:       util.RandomName(),
+		Email:          util.RandomEmail(),
+		Role:           util.CustomerRole,
+	}
+	return
+}
+
+// RandomAccount returns an active USD account of owner
+func RandomAccount(owner string) db.Account {
+	return db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Name:     owner,
+		Balance:  util.RandomAmount(),
+		Currency: util.USD,
+		Status:   db.AccountStatusActive,
+	}
+}
+
+// RandomTransfer returns a posted transfer between the accounts
+func RandomTransfer(from, to db.Account) db.Transfer {
+	return db.Transfer{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        util.RandomAmount(),
+		Status:        db.TransferStatusPosted,
+		Metadata:      json.RawMessage(`{}`),
+	}
+}
+
+// Currencies are the rows the currencies migration seeds
+var Currencies = []util.Currency{
+	{Code: util.USD, NumericCode: 840, Exponent: 2, Enabled: true},
+	{Code: util.EUR, NumericCode: 978, Exponent: 2, Enabled: true},
+	{Code: util.CAD, NumericCode: 124, Exponent: 2, Enabled: true},
+	{Code: util.GBP, NumericCode: 826, Exponent: 2, Enabled: false},
+	{Code: util.JPY, NumericCode: 392, Exponent: 0, Enabled: false},
+	{Code: util.BHD, NumericCode: 48, Exponent: 3, Enabled: false},
+}
+
+var (
+	currenciesMu sync.Mutex
+	currencies   = Currencies
+)
+
+// NewCurrencyRegistry serves the seeded Currencies, or the ones passed to
+// SetCurrencies, without going through the mocked store
+func NewCurrencyRegistry() *util.CurrencyRegistry {
+	return util.NewCurrencyRegistry(func() ([]util.Currency, error) {
+		currenciesMu.Lock()
+		defer currenciesMu.Unlock()
+
+		return currencies, nil
+	}, time.Minute)
+}
+
+// SetCurrencies makes registry serve list until the test ends
+func SetCurrencies(t *testing.T, registry *util.CurrencyRegistry, list []util.Currency) {
+	currenciesMu.Lock()
+	previous := currencies
+	currencies = list
+	currenciesMu.Unlock()
+	registry.Invalidate()
+
+	t.Cleanup(func() {
+		currenciesMu.Lock()
+		currencies = previous
+		currenciesMu.Unlock()
+		registry.Invalidate()
+	})
+}
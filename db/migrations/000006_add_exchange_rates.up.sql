@@ -0,0 +1,37 @@
+CREATE TABLE "exchange_rates" (
+    "from_currency" varchar NOT NULL,
+    "to_currency" varchar NOT NULL,
+    "rate" numeric(18,8) NOT NULL,
+    "spread_bps" integer NOT NULL DEFAULT 0,
+    "updated_at" timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("from_currency", "to_currency"),
+    CHECK ("rate" > 0),
+    CHECK ("spread_bps" >= 0 AND "spread_bps" < 10000)
+);
+
+CREATE TABLE "fx_quotes" (
+    "id" uuid PRIMARY KEY,
+    "username" varchar NOT NULL,
+    "from_currency" varchar NOT NULL,
+    "to_currency" varchar NOT NULL,
+    "rate" numeric(18,8) NOT NULL,
+    "spread_bps" integer NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "fx_quotes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfers" ADD COLUMN "to_amount" bigint;
+
+UPDATE "transfers" SET "to_amount" = "amount";
+
+ALTER TABLE "transfers" ALTER COLUMN "to_amount" SET NOT NULL;
+
+ALTER TABLE "transfers" ADD COLUMN "exchange_rate" numeric(18,8) NOT NULL DEFAULT 1;
+
+ALTER TABLE "transfers" ADD COLUMN "spread_bps" integer NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'Amount credited in the currency of the receiving account';
+
+COMMENT ON COLUMN "exchange_rates"."spread_bps" IS 'Spread taken from the converted amount in basis points';
@@ -0,0 +1,22 @@
+CREATE TABLE "currencies" (
+    "code" varchar(3) PRIMARY KEY,
+    "numeric_code" integer UNIQUE NOT NULL,
+    "exponent" integer NOT NULL,
+    "enabled" boolean NOT NULL DEFAULT false,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    CHECK ("exponent" >= 0 AND "exponent" <= 4)
+);
+
+COMMENT ON COLUMN "currencies"."code" IS 'ISO 4217 alphabetic code';
+
+COMMENT ON COLUMN "currencies"."exponent" IS 'Number of minor unit digits, amounts are stored in minor units';
+
+INSERT INTO "currencies" ("code", "numeric_code", "exponent", "enabled") VALUES
+    ('USD', 840, 2, true),
+    ('EUR', 978, 2, true),
+    ('CAD', 124, 2, true),
+    ('GBP', 826, 2, false),
+    ('JPY', 392, 0, false),
+    ('BHD', 48, 3, false);
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
@@ -0,0 +1,15 @@
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+-- both entries of a transfer are written in the transfer's transaction so they
+-- share its created_at
+UPDATE "entries" e SET "transfer_id" = t."id"
+FROM "transfers" t
+WHERE e."created_at" = t."created_at"
+    AND ((e."account_id" = t."from_account_id" AND e."amount" = -t."amount")
+        OR (e."account_id" = t."to_account_id" AND e."amount" = t."to_amount"));
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'Transfer that wrote the entry, null for deposits and withdrawals';
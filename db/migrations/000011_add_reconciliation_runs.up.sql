@@ -0,0 +1,17 @@
+CREATE TABLE "reconciliation_runs" (
+    "id" bigserial PRIMARY KEY,
+    "triggered_by" varchar NOT NULL,
+    "adjust" boolean NOT NULL DEFAULT false,
+    "status" varchar NOT NULL,
+    "accounts_checked" bigint NOT NULL,
+    "mismatch_count" bigint NOT NULL,
+    "mismatches" jsonb NOT NULL,
+    "error" varchar NOT NULL DEFAULT '',
+    "started_at" timestamptz NOT NULL,
+    "finished_at" timestamptz NOT NULL DEFAULT (now()),
+    CHECK ("status" IN ('succeeded', 'failed'))
+);
+
+COMMENT ON COLUMN "reconciliation_runs"."adjust" IS 'Whether adjustment entries were written for the mismatches';
+
+COMMENT ON COLUMN "reconciliation_runs"."mismatches" IS 'Accounts whose balance differs from the sum of their entries';
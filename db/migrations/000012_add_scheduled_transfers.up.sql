@@ -0,0 +1,51 @@
+CREATE TABLE "scheduled_transfers" (
+    "id" bigserial PRIMARY KEY,
+    "owner" varchar NOT NULL,
+    "from_account_id" bigint NOT NULL,
+    "to_account_id" bigint NOT NULL,
+    "amount" bigint NOT NULL,
+    "currency" varchar NOT NULL,
+    "interval_unit" varchar NOT NULL,
+    "interval_count" integer NOT NULL DEFAULT 1,
+    "start_at" timestamptz NOT NULL,
+    "end_at" timestamptz,
+    "next_run_at" timestamptz NOT NULL,
+    "occurrence" integer NOT NULL DEFAULT 0,
+    "status" varchar NOT NULL DEFAULT 'active',
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    CHECK ("amount" > 0),
+    CHECK ("interval_unit" IN ('day', 'week', 'month')),
+    CHECK ("interval_count" > 0),
+    CHECK ("status" IN ('active', 'paused', 'completed', 'cancelled'))
+);
+
+CREATE TABLE "scheduled_transfer_runs" (
+    "id" bigserial PRIMARY KEY,
+    "scheduled_transfer_id" bigint NOT NULL,
+    "scheduled_for" timestamptz NOT NULL,
+    "status" varchar NOT NULL,
+    "transfer_id" bigint,
+    "error" varchar NOT NULL DEFAULT '',
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    CHECK ("status" IN ('succeeded', 'failed'))
+);
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id");
+
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "scheduled_transfers" ("owner", "created_at", "id");
+
+CREATE INDEX ON "scheduled_transfers" ("next_run_at") WHERE "status" = 'active';
+
+CREATE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id", "created_at", "id");
+
+COMMENT ON COLUMN "scheduled_transfers"."occurrence" IS 'Index of next_run_at in the schedule, counted from start_at';
+
+COMMENT ON COLUMN "scheduled_transfer_runs"."transfer_id" IS 'Transfer made by the run, null when it failed';
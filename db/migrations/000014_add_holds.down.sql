@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "holds";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "status";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "available_balance";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "held_balance";
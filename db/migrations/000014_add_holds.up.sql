@@ -0,0 +1,31 @@
+ALTER TABLE "accounts" ADD COLUMN "held_balance" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD COLUMN "available_balance" bigint GENERATED ALWAYS AS ("balance" - "held_balance") STORED;
+
+ALTER TABLE "transfers" ADD COLUMN "status" varchar NOT NULL DEFAULT 'posted';
+
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_status_check" CHECK ("status" IN ('pending', 'posted', 'voided', 'expired'));
+
+CREATE TABLE "holds" (
+    "id" bigserial PRIMARY KEY,
+    "transfer_id" bigint UNIQUE NOT NULL,
+    "account_id" bigint NOT NULL,
+    "amount" bigint NOT NULL,
+    "status" varchar NOT NULL DEFAULT 'active',
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    CHECK ("amount" > 0),
+    CHECK ("status" IN ('active', 'captured', 'voided', 'expired'))
+);
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE INDEX ON "holds" ("expires_at") WHERE "status" = 'active';
+
+COMMENT ON COLUMN "accounts"."held_balance" IS 'Sum of the active holds on the account';
+
+COMMENT ON COLUMN "accounts"."available_balance" IS 'Balance that is not held by a pending transfer';
+
+COMMENT ON COLUMN "transfers"."status" IS 'pending while the money is held, posted once it moved, voided or expired when the hold was released';
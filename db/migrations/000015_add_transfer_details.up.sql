@@ -0,0 +1,17 @@
+ALTER TABLE "transfers" ADD COLUMN "description" varchar(255) NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers" ADD COLUMN "external_reference" varchar(64) NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers" ADD COLUMN "metadata" jsonb NOT NULL DEFAULT '{}';
+
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_metadata_check" CHECK (jsonb_typeof("metadata") = 'object' AND octet_length("metadata"::text) <= 4096);
+
+ALTER TABLE "entries" ADD COLUMN "description" varchar(255) NOT NULL DEFAULT '';
+
+CREATE INDEX ON "transfers" ("external_reference") WHERE "external_reference" <> '';
+
+COMMENT ON COLUMN "transfers"."description" IS 'Free text memo, copied onto both entries';
+
+COMMENT ON COLUMN "transfers"."external_reference" IS 'Reference of the payment in the client''s own system';
+
+COMMENT ON COLUMN "transfers"."metadata" IS 'Client defined JSON object, at most 4 KB';
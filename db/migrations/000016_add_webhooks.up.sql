@@ -0,0 +1,43 @@
+CREATE TABLE "webhook_subscriptions" (
+    "id" bigserial PRIMARY KEY,
+    "owner" varchar NOT NULL,
+    "url" varchar NOT NULL,
+    "secret" varchar NOT NULL,
+    "event_types" varchar[] NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    CHECK (cardinality("event_types") > 0)
+);
+
+CREATE TABLE "webhook_deliveries" (
+    "id" bigserial PRIMARY KEY,
+    "subscription_id" bigint NOT NULL,
+    "event_id" uuid NOT NULL,
+    "event_type" varchar NOT NULL,
+    "payload" jsonb NOT NULL,
+    "status" varchar NOT NULL DEFAULT 'pending',
+    "attempts" integer NOT NULL DEFAULT 0,
+    "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+    "last_attempt_at" timestamptz,
+    "response_status" integer NOT NULL DEFAULT 0,
+    "last_error" varchar NOT NULL DEFAULT '',
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    CHECK ("status" IN ('pending', 'succeeded', 'dead'))
+);
+
+ALTER TABLE "webhook_subscriptions" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("subscription_id") REFERENCES "webhook_subscriptions" ("id") ON DELETE CASCADE;
+
+CREATE INDEX ON "webhook_subscriptions" ("owner", "created_at", "id");
+
+CREATE INDEX ON "webhook_deliveries" ("subscription_id", "created_at", "id");
+
+CREATE INDEX ON "webhook_deliveries" ("next_attempt_at") WHERE "status" = 'pending';
+
+COMMENT ON COLUMN "webhook_subscriptions"."secret" IS 'Key of the HMAC-SHA256 signature sent with every delivery';
+
+COMMENT ON COLUMN "webhook_deliveries"."event_id" IS 'Same for every delivery of one event so receivers can drop duplicates';
+
+COMMENT ON COLUMN "webhook_deliveries"."status" IS 'pending until the receiver answers 2xx, dead once every attempt failed';
+
+COMMENT ON COLUMN "webhook_deliveries"."response_status" IS 'HTTP status of the last attempt, 0 when no response was received';
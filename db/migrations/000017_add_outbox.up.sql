@@ -0,0 +1,18 @@
+CREATE TABLE "outbox" (
+    "id" bigserial PRIMARY KEY,
+    "event_id" uuid UNIQUE NOT NULL,
+    "event_type" varchar NOT NULL,
+    "aggregate_type" varchar NOT NULL,
+    "aggregate_id" bigint NOT NULL,
+    "payload" jsonb NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "sent_at" timestamptz
+);
+
+CREATE INDEX ON "outbox" ("id") WHERE "sent_at" IS NULL;
+
+CREATE INDEX ON "outbox" ("aggregate_type", "aggregate_id");
+
+COMMENT ON TABLE "outbox" IS 'Domain events written in the transaction that raised them, relayed to the event publisher';
+
+COMMENT ON COLUMN "outbox"."sent_at" IS 'When the relay published the event, null until then';
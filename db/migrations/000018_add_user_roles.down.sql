@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "accounts_created_at_id_idx";
+
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "role";
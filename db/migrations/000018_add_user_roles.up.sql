@@ -0,0 +1,7 @@
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'customer';
+
+ALTER TABLE "users" ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('customer', 'banker', 'admin'));
+
+CREATE INDEX ON "accounts" ("created_at", "id");
+
+COMMENT ON COLUMN "users"."role" IS 'customer, banker (can see and freeze every account) or admin (can also manage currencies, rates and roles)';
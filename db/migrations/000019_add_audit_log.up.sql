@@ -0,0 +1,37 @@
+CREATE TABLE "audit_log" (
+    "id" bigserial PRIMARY KEY,
+    "actor" varchar NOT NULL,
+    "action" varchar NOT NULL,
+    "target_type" varchar NOT NULL DEFAULT '',
+    "target_ids" bigint[] NOT NULL DEFAULT '{}',
+    "request_id" varchar NOT NULL DEFAULT '',
+    "before" json NOT NULL DEFAULT 'null',
+    "after" json NOT NULL DEFAULT 'null',
+    "prev_hash" bytea NOT NULL,
+    "hash" bytea NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "audit_log" ("request_id");
+
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_log_no_update" BEFORE UPDATE OR DELETE ON "audit_log"
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+CREATE TRIGGER "audit_log_no_truncate" BEFORE TRUNCATE ON "audit_log"
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+
+COMMENT ON TABLE "audit_log" IS 'Hash-chained record of every state-changing request and store transaction, rows can only be appended';
+
+COMMENT ON COLUMN "audit_log"."actor" IS 'Username that made the change, anonymous or system when there is none';
+
+COMMENT ON COLUMN "audit_log"."before" IS 'Snapshot of the rows before the change, JSON kept as written so the hash can be checked';
+
+COMMENT ON COLUMN "audit_log"."prev_hash" IS 'SHA-256 of the previous row, all zeros for the first one';
+
+COMMENT ON COLUMN "audit_log"."hash" IS 'SHA-256 of prev_hash and the other columns except id';
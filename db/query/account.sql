@@ -16,7 +16,11 @@ SELECT * FROM accounts WHERE id = $1 LIMIT 1;
 SELECT * FROM accounts WHERE id = $1 LIMIT 1 FOR NO KEY UPDATE;
 
 -- name: GetAccounts :many
-SELECT * FROM accounts ORDER BY name LIMIT $1 OFFSET $2;
+SELECT * FROM accounts
+WHERE name = sqlc.arg(name)
+    AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(size);
 
 -- name: UpdateAccount :one
 UPDATE accounts SET balance = $2 WHERE id = $1 RETURNING *;
@@ -24,5 +28,21 @@ UPDATE accounts SET balance = $2 WHERE id = $1 RETURNING *;
 -- name: AddAccountBalance :one
 UPDATE accounts SET balance = balance + sqlc.arg(amount) WHERE id = sqlc.arg(id) RETURNING *;
 
--- name: DeleteAccount :exec
-DELETE FROM accounts WHERE id = $1;
+-- name: AddAccountHeldBalance :one
+UPDATE accounts SET held_balance = held_balance + sqlc.arg(amount) WHERE id = sqlc.arg(id) RETURNING *;
+
+-- name: UpdateAccountOverdraftLimit :one
+UPDATE accounts SET overdraft_limit = sqlc.arg(overdraft_limit) WHERE id = sqlc.arg(id) RETURNING *;
+
+-- name: UpdateAccountStatus :one
+UPDATE accounts SET status = sqlc.arg(status)
+WHERE id = sqlc.arg(id) AND status <> 'closed'
+RETURNING *;
+
+-- name: ListAllAccounts :many
+SELECT * FROM accounts
+WHERE (sqlc.narg(name)::varchar IS NULL OR name = sqlc.narg(name))
+    AND (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status))
+    AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(size);
@@ -0,0 +1,19 @@
+-- name: CreateCurrency :one
+INSERT INTO currencies (
+    code,
+    numeric_code,
+    exponent,
+    enabled
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetCurrency :one
+SELECT * FROM currencies WHERE code = $1 LIMIT 1;
+
+-- name: ListCurrencies :many
+SELECT * FROM currencies ORDER BY code;
+
+-- name: UpdateCurrencyEnabled :one
+UPDATE currencies SET enabled = sqlc.arg(enabled) WHERE code = sqlc.arg(code) RETURNING *;
@@ -1,18 +1,46 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id,
+  description
 ) VALUES (
-  $1, $2
+  $1, $2, $3, $4
 ) RETURNING *;
 
 -- name: GetEntry :one
 SELECT * FROM entries
 WHERE id = $1 LIMIT 1;
 
--- name: GetEntries :many
+-- name: ListEntries :many
+SELECT entries.* FROM entries
+LEFT JOIN transfers ON transfers.id = entries.transfer_id
+WHERE entries.account_id = sqlc.arg(account_id)
+    AND (entries.created_at, entries.id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+    AND (sqlc.narg(from_time)::timestamptz IS NULL OR entries.created_at >= sqlc.narg(from_time))
+    AND (sqlc.narg(to_time)::timestamptz IS NULL OR entries.created_at <= sqlc.narg(to_time))
+    AND (sqlc.narg(min_amount)::bigint IS NULL OR abs(entries.amount) >= sqlc.narg(min_amount))
+    AND (sqlc.narg(max_amount)::bigint IS NULL OR abs(entries.amount) <= sqlc.narg(max_amount))
+    AND (sqlc.narg(direction)::text IS NULL
+        OR (sqlc.narg(direction) = 'credit' AND entries.amount > 0)
+        OR (sqlc.narg(direction) = 'debit' AND entries.amount < 0))
+    AND (sqlc.narg(counterparty_id)::bigint IS NULL
+        OR sqlc.narg(counterparty_id) IN (transfers.from_account_id, transfers.to_account_id))
+ORDER BY entries.created_at, entries.id
+LIMIT sqlc.arg(size);
+
+-- name: GetEntriesBetween :many
 SELECT * FROM entries
-WHERE account_id = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3;
\ No newline at end of file
+WHERE account_id = sqlc.arg(account_id)
+    AND created_at >= sqlc.arg(from_time)
+    AND created_at < sqlc.arg(to_time)
+ORDER BY created_at, id;
+
+-- name: SumEntriesSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries
+WHERE account_id = sqlc.arg(account_id)
+    AND created_at >= sqlc.arg(since);
+
+-- name: SumEntries :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries
+WHERE account_id = $1;
@@ -0,0 +1,16 @@
+-- name: CreateFxQuote :one
+INSERT INTO fx_quotes (
+    id,
+    username,
+    from_currency,
+    to_currency,
+    rate,
+    spread_bps,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: GetFxQuote :one
+SELECT * FROM fx_quotes WHERE id = $1 LIMIT 1;
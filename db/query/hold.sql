@@ -0,0 +1,26 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+    transfer_id,
+    account_id,
+    amount,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetHoldByTransfer :one
+SELECT * FROM holds WHERE transfer_id = $1 LIMIT 1;
+
+-- name: GetHoldByTransferForUpdate :one
+SELECT * FROM holds WHERE transfer_id = $1 LIMIT 1 FOR NO KEY UPDATE;
+
+-- name: UpdateHoldStatus :one
+UPDATE holds SET status = sqlc.arg(status) WHERE id = sqlc.arg(id) RETURNING *;
+
+-- name: ClaimExpiredHolds :many
+SELECT * FROM holds
+WHERE status = 'active' AND expires_at <= sqlc.arg(now)
+ORDER BY expires_at, id
+LIMIT sqlc.arg(size)
+FOR UPDATE SKIP LOCKED;
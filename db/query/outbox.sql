@@ -0,0 +1,31 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (
+    event_id,
+    event_type,
+    aggregate_type,
+    aggregate_id,
+    payload
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: ListOutboxEventsByAggregate :many
+SELECT * FROM outbox
+WHERE aggregate_type = $1 AND aggregate_id = $2
+ORDER BY id;
+
+-- name: ClaimUnsentOutboxEvents :many
+SELECT * FROM outbox
+WHERE sent_at IS NULL
+ORDER BY id
+LIMIT sqlc.arg(size)
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxEventsSent :exec
+UPDATE outbox
+SET sent_at = sqlc.arg(sent_at)
+WHERE id = ANY(sqlc.arg(ids)::bigint[]);
+
+-- name: NotifyAccountEvent :exec
+SELECT pg_notify('account_events', sqlc.arg(payload)::text);
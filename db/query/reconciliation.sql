@@ -0,0 +1,32 @@
+-- name: ListAccountEntryTotals :many
+SELECT accounts.id, accounts.balance, COALESCE(SUM(entries.amount), 0)::bigint AS entries_total
+FROM accounts
+LEFT JOIN entries ON entries.account_id = accounts.id
+WHERE accounts.id > sqlc.arg(after_id)
+GROUP BY accounts.id
+ORDER BY accounts.id
+LIMIT sqlc.arg(size);
+
+-- name: CreateReconciliationRun :one
+INSERT INTO reconciliation_runs (
+    triggered_by,
+    adjust,
+    status,
+    accounts_checked,
+    mismatch_count,
+    mismatches,
+    error,
+    started_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING *;
+
+-- name: GetReconciliationRun :one
+SELECT * FROM reconciliation_runs
+WHERE id = $1 LIMIT 1;
+
+-- name: ListReconciliationRuns :many
+SELECT * FROM reconciliation_runs
+ORDER BY id DESC
+LIMIT sqlc.arg(size);
@@ -2,29 +2,67 @@
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  to_amount,
+  exchange_rate,
+  spread_bps,
+  reversal_of,
+  status,
+  description,
+  external_reference,
+  metadata
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 ) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
--- name: GetTransfers :many
+-- name: GetTransferForUpdate :one
 SELECT * FROM transfers
-WHERE 
-    from_account_id = $1 OR
-    to_account_id = $2
-ORDER BY id
-LIMIT $3
-OFFSET $4;
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: UpdateTransferStatus :one
+UPDATE transfers SET status = sqlc.arg(status) WHERE id = sqlc.arg(id) RETURNING *;
+
+-- name: ListTransferReversals :many
+SELECT * FROM transfers
+WHERE reversal_of = $1
+ORDER BY id;
+
+-- name: SumTransferReversals :one
+SELECT COALESCE(SUM(to_amount), 0)::bigint AS total FROM transfers
+WHERE reversal_of = $1;
 
--- name: GetTransfersByAccount :many
+-- name: ListTransfers :many
 SELECT * FROM transfers
 WHERE 
-    from_account_id = sqlc.arg(id) OR
-    to_account_id = sqlc.arg(id)
-ORDER BY id
-LIMIT sqlc.arg(size)
-OFFSET sqlc.arg(off);
\ No newline at end of file
+    (from_account_id = sqlc.arg(account_id) OR
+    to_account_id = sqlc.arg(account_id))
+    AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+    AND (sqlc.narg(from_time)::timestamptz IS NULL OR created_at >= sqlc.narg(from_time))
+    AND (sqlc.narg(to_time)::timestamptz IS NULL OR created_at <= sqlc.narg(to_time))
+    AND (sqlc.narg(min_amount)::bigint IS NULL OR amount >= sqlc.narg(min_amount))
+    AND (sqlc.narg(max_amount)::bigint IS NULL OR amount <= sqlc.narg(max_amount))
+    AND (sqlc.narg(direction)::text IS NULL
+        OR (sqlc.narg(direction) = 'outgoing' AND from_account_id = sqlc.arg(account_id))
+        OR (sqlc.narg(direction) = 'incoming' AND to_account_id = sqlc.arg(account_id)))
+    AND (sqlc.narg(counterparty_id)::bigint IS NULL
+        OR (from_account_id = sqlc.arg(account_id) AND to_account_id = sqlc.narg(counterparty_id))
+        OR (to_account_id = sqlc.arg(account_id) AND from_account_id = sqlc.narg(counterparty_id)))
+    AND (sqlc.narg(reference)::text IS NULL OR external_reference = sqlc.narg(reference))
+ORDER BY created_at, id
+LIMIT sqlc.arg(size);
+
+-- name: ListUserTransfers :many
+SELECT t.* FROM transfers t
+WHERE EXISTS (
+        SELECT 1 FROM accounts a
+        WHERE a.name = sqlc.arg(username)
+            AND a.id IN (t.from_account_id, t.to_account_id)
+    )
+    AND (t.created_at, t.id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY t.created_at, t.id
+LIMIT sqlc.arg(size);
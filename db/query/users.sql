@@ -10,4 +10,7 @@ INSERT INTO users (
 RETURNING *;
 
 -- name: GetUserByUsername :one
-SELECT * FROM users WHERE username = $1 LIMIT 1;
\ No newline at end of file
+SELECT * FROM users WHERE username = $1 LIMIT 1;
+
+-- name: UpdateUserRole :one
+UPDATE users SET role = sqlc.arg(role) WHERE username = sqlc.arg(username) RETURNING *;
\ No newline at end of file
@@ -0,0 +1,70 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (
+    owner,
+    url,
+    secret,
+    event_types
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE owner = sqlc.arg(owner)
+    AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(size);
+
+-- name: DeleteWebhookSubscription :exec
+DELETE FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: CreateWebhookDeliveries :exec
+INSERT INTO webhook_deliveries (
+    subscription_id,
+    event_id,
+    event_type,
+    payload
+)
+SELECT id, sqlc.arg(event_id), sqlc.arg(event_type), sqlc.arg(payload)
+FROM webhook_subscriptions
+WHERE owner = sqlc.arg(owner) AND sqlc.arg(event_type) = ANY(event_types);
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = sqlc.arg(subscription_id)
+    AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+    AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status))
+ORDER BY created_at, id
+LIMIT sqlc.arg(size);
+
+-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries AS d
+SET next_attempt_at = sqlc.arg(lease_until)
+FROM webhook_subscriptions AS s
+WHERE s.id = d.subscription_id
+    AND d.id IN (
+        SELECT id FROM webhook_deliveries
+        WHERE status = 'pending' AND next_attempt_at <= sqlc.arg(now)
+        ORDER BY next_attempt_at, id
+        LIMIT sqlc.arg(size)
+        FOR UPDATE SKIP LOCKED
+    )
+RETURNING d.*, s.url, s.secret;
+
+-- name: RecordWebhookDeliveryAttempt :one
+UPDATE webhook_deliveries
+SET
+    status = sqlc.arg(status),
+    attempts = attempts + 1,
+    next_attempt_at = sqlc.arg(next_attempt_at),
+    last_attempt_at = sqlc.arg(last_attempt_at),
+    response_status = sqlc.arg(response_status),
+    last_error = sqlc.arg(last_error)
+WHERE id = sqlc.arg(id)
+RETURNING *;
@@ -7,10 +7,12 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const addAccountBalance = `-- name: AddAccountBalance :one
-UPDATE accounts SET balance = balance + $1 WHERE id = $2 RETURNING id, name, balance, currency, created_at
+UPDATE accounts SET balance = balance + $1 WHERE id = $2 RETURNING id, name, balance, currency, created_at, overdraft_limit, status, held_balance, available_balance
 `
 
 type AddAccountBalanceParams struct {
@@ -27,6 +29,36 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const addAccountHeldBalance = `-- name: AddAccountHeldBalance :one
+UPDATE accounts SET held_balance = held_balance + $1 WHERE id = $2 RETURNING id, name, balance, currency, created_at, overdraft_limit, status, held_balance, available_balance
+`
+
+type AddAccountHeldBalanceParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountHeldBalance(ctx context.Context, arg AddAccountHeldBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountHeldBalance, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.HeldBalance,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
@@ -39,7 +71,7 @@ INSERT INTO accounts (
 ) VALUES (
     $1, $2, $3
 ) 
-RETURNING id, name, balance, currency, created_at
+RETURNING id, name, balance, currency, created_at, overdraft_limit, status, held_balance, available_balance
 `
 
 type CreateAccountParams struct {
@@ -57,21 +89,16 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.HeldBalance,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
 
-const deleteAccount = `-- name: DeleteAccount :exec
-DELETE FROM accounts WHERE id = $1
-`
-
-func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteAccount, id)
-	return err
-}
-
 const getAccount = `-- name: GetAccount :one
-SELECT id, name, balance, currency, created_at FROM accounts WHERE id = $1 LIMIT 1
+SELECT id, name, balance, currency, created_at, overdraft_limit, status, held_balance, available_balance FROM accounts WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
@@ -83,12 +110,16 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.HeldBalance,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, name, balance, currency, created_at FROM accounts WHERE id = $1 LIMIT 1 FOR NO KEY UPDATE
+SELECT id, name, balance, currency, created_at, overdraft_limit, status, held_balance, available_balance FROM accounts WHERE id = $1 LIMIT 1 FOR NO KEY UPDATE
 `
 
 func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, error) {
@@ -100,21 +131,92 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.HeldBalance,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
 
 const getAccounts = `-- name: GetAccounts :many
-SELECT id, name, balance, currency, created_at FROM accounts ORDER BY name LIMIT $1 OFFSET $2
+SELECT id, name, balance, currency, created_at, overdraft_limit, status, held_balance, available_balance FROM accounts
+WHERE name = $1
+    AND (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at, id
+LIMIT $4
 `
 
 type GetAccountsParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	Name           string    `json:"name"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+	Size           int32     `json:"size"`
 }
 
 func (q *Queries) GetAccounts(ctx context.Context, arg GetAccountsParams) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, getAccounts, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, getAccounts,
+		arg.Name,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Size,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.Status,
+			&i.HeldBalance,
+			&i.AvailableBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllAccounts = `-- name: ListAllAccounts :many
+SELECT id, name, balance, currency, created_at, overdraft_limit, status, held_balance, available_balance FROM accounts
+WHERE ($1::varchar IS NULL OR name = $1)
+    AND ($2::varchar IS NULL OR status = $2)
+    AND (created_at, id) > ($3::timestamptz, $4::bigint)
+ORDER BY created_at, id
+LIMIT $5
+`
+
+type ListAllAccountsParams struct {
+	Name           sql.NullString `json:"name"`
+	Status         sql.NullString `json:"status"`
+	AfterCreatedAt time.Time      `json:"after_created_at"`
+	AfterID        int64          `json:"after_id"`
+	Size           int32          `json:"size"`
+}
+
+func (q *Queries) ListAllAccounts(ctx context.Context, arg ListAllAccountsParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAllAccounts,
+		arg.Name,
+		arg.Status,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Size,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -128,6 +230,10 @@ func (q *Queries) GetAccounts(ctx context.Context, arg GetAccountsParams) ([]Acc
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.Status,
+			&i.HeldBalance,
+			&i.AvailableBalance,
 		); err != nil {
 			return nil, err
 		}
@@ -143,7 +249,7 @@ func (q *Queries) GetAccounts(ctx context.Context, arg GetAccountsParams) ([]Acc
 }
 
 const updateAccount = `-- name: UpdateAccount :one
-UPDATE accounts SET balance = $2 WHERE id = $1 RETURNING id, name, balance, currency, created_at
+UPDATE accounts SET balance = $2 WHERE id = $1 RETURNING id, name, balance, currency, created_at, overdraft_limit, status, held_balance, available_balance
 `
 
 type UpdateAccountParams struct {
@@ -160,6 +266,64 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const updateAccountOverdraftLimit = `-- name: UpdateAccountOverdraftLimit :one
+UPDATE accounts SET overdraft_limit = $1 WHERE id = $2 RETURNING id, name, balance, currency, created_at, overdraft_limit, status, held_balance, available_balance
+`
+
+type UpdateAccountOverdraftLimitParams struct {
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	ID             int64 `json:"id"`
+}
+
+func (q *Queries) UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountOverdraftLimit, arg.OverdraftLimit, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const updateAccountStatus = `-- name: UpdateAccountStatus :one
+UPDATE accounts SET status = $1
+WHERE id = $2 AND status <> 'closed'
+RETURNING id, name, balance, currency, created_at, overdraft_limit, status, held_balance, available_balance
+`
+
+type UpdateAccountStatusParams struct {
+	Status string `json:"status"`
+	ID     int64  `json:"id"`
+}
+
+func (q *Queries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountStatus, arg.Status, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.HeldBalance,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
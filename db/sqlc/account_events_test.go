@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepositTxNotifyAccountEvent(t *testing.T) {
+	config, err := util.LoadConfig("../..")
+	require.NoError(t, err)
+
+	// the listener retries forever without a database, check there is one
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := testDB.PingContext(ctx); err != nil {
+		t.Skipf("cannot reach the database: %v", err)
+	}
+
+	listener := pq.NewListener(config.DBSource, time.Second, time.Minute, nil)
+	defer listener.Close()
+
+	// Listen blocks until the listener is connected
+	listening := make(chan error, 1)
+	go func() {
+		listening <- listener.Listen(AccountEventsChannel)
+	}()
+	select {
+	case err := <-listening:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener did not connect")
+	}
+
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	_, err = store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account.ID,
+		Amount:    10,
+	})
+	require.NoError(t, err)
+
+	// other tests notify the channel too
+	for {
+		select {
+		case notification := <-listener.Notify:
+			require.NotNil(t, notification)
+
+			var event struct {
+				Type        string              `json:"type"`
+				AggregateID int64               `json:"aggregate_id"`
+				Data        BalanceChangedEvent `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(notification.Extra), &event))
+			if event.AggregateID != account.ID {
+				continue
+			}
+
+			require.Equal(t, EventAccountBalanceChanged, event.Type)
+			require.Equal(t, int64(10), event.Data.Amount)
+			require.Equal(t, account.Balance+10, event.Data.Account.Balance)
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatal("account event was not notified")
+		}
+	}
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
@@ -11,9 +12,10 @@ import (
 func createRandomAccount(t *testing.T) Account {
 	user := createRandomUser(t)
 
+	// keep enough balance for the transfers made in the store tests
 	arg := CreateAccountParams{
 		Name: user.Username,
-		Balance: util.RandomAmount(),
+		Balance: util.RandomInt(100, 1000),
 		Currency: util.RandomCurrency(),
 	}
 
@@ -25,6 +27,7 @@ func createRandomAccount(t *testing.T) Account {
 	require.Equal(t, arg.Name, account.Name)
 	require.Equal(t, arg.Balance, account.Balance)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.Equal(t, AccountStatusActive, account.Status)
 
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
@@ -39,22 +42,23 @@ func TestCreateAccount(t *testing.T) {
 
 
 func TestGetAccounts(t *testing.T) {
-	for i := 0; i < 10; i++ {
-		createRandomAccount(t)
+	var lastAccount Account
+	for i := 0; i < 5; i++ {
+		lastAccount = createRandomAccount(t)
 	}
 
 	arg := GetAccountsParams{
-		Limit:  5,
-		Offset: 5,
+		Name: lastAccount.Name,
+		Size: 5,
 	}
 
 	accounts, err := testQueries.GetAccounts(context.Background(), arg)
-
 	require.NoError(t, err)
-	require.Len(t, accounts, 5)
+	require.NotEmpty(t, accounts)
 
 	for _, account := range accounts {
 		require.NotEmpty(t, account)
+		require.Equal(t, lastAccount.Name, account.Name)
 	}
 }
 
@@ -79,30 +83,97 @@ func TestUpdateAccount(t *testing.T) {
 	require.Equal(t, account1.CreatedAt, account2.CreatedAt)
 }
 
-func TestDeleteAccount(t *testing.T) {
+func TestGetAccount(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2, err := testQueries.GetAccount(context.Background(), account1.ID)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, account2)
+
+	require.Equal(t, account1.Name, account2.Name)
+	require.Equal(t, account1.Balance, account2.Balance)
+	require.Equal(t, account1.Currency, account2.Currency)
+
+	require.Equal(t, account1.ID, account2.ID)
+	require.Equal(t, account1.CreatedAt, account2.CreatedAt)
+}
+func TestUpdateAccountOverdraftLimit(t *testing.T) {
 	account1 := createRandomAccount(t)
+	require.Zero(t, account1.OverdraftLimit)
 
-	err := testQueries.DeleteAccount(context.Background(), account1.ID)
+	arg := UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: util.RandomInt(1, 1000),
+	}
 
+	account2, err := testQueries.UpdateAccountOverdraftLimit(context.Background(), arg)
 	require.NoError(t, err)
+	require.Equal(t, account1.ID, account2.ID)
+	require.Equal(t, account1.Balance, account2.Balance)
+	require.Equal(t, arg.OverdraftLimit, account2.OverdraftLimit)
+}
 
-	account2, err := testQueries.GetAccount(context.Background(), account1.ID)
+func TestUpdateAccountStatus(t *testing.T) {
+	account1 := createRandomAccount(t)
+
+	account2, err := testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:     account1.ID,
+		Status: AccountStatusFrozen,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account1.ID, account2.ID)
+	require.Equal(t, AccountStatusFrozen, account2.Status)
 
+	_, err = testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:     account1.ID,
+		Status: "deleted",
+	})
 	require.Error(t, err)
-	require.Empty(t, account2)
+
+	// closed accounts are never reopened
+	_, err = testDB.Exec("UPDATE accounts SET status = 'closed' WHERE id = $1", account1.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:     account1.ID,
+		Status: AccountStatusActive,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
 }
 
-func TestGetAccount(t *testing.T) {
+func TestListAllAccounts(t *testing.T) {
 	account1 := createRandomAccount(t)
-	account2, err := testQueries.GetAccount(context.Background(), account1.ID)
+	account2 := createRandomAccount(t)
 
+	_, err := testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:     account2.ID,
+		Status: AccountStatusFrozen,
+	})
 	require.NoError(t, err)
-	require.NotEmpty(t, account2)
 
-	require.Equal(t, account1.Name, account2.Name)
-	require.Equal(t, account1.Balance, account2.Balance)
-	require.Equal(t, account1.Currency, account2.Currency)
+	// older accounts left by other tests come first, start from account1
+	accounts, err := testQueries.ListAllAccounts(context.Background(), ListAllAccountsParams{
+		AfterCreatedAt: account1.CreatedAt,
+		AfterID:        account1.ID,
+		Size:           100,
+	})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(accounts), 1)
+	require.Equal(t, account2.ID, accounts[0].ID)
 
-	require.Equal(t, account1.ID, account2.ID)
-	require.Equal(t, account1.CreatedAt, account2.CreatedAt)
-}
\ No newline at end of file
+	accounts, err = testQueries.ListAllAccounts(context.Background(), ListAllAccountsParams{
+		Name: sql.NullString{String: account1.Name, Valid: true},
+		Size: 5,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, account1.ID, accounts[0].ID)
+
+	accounts, err = testQueries.ListAllAccounts(context.Background(), ListAllAccountsParams{
+		Name:   sql.NullString{String: account2.Name, Valid: true},
+		Status: sql.NullString{String: AccountStatusActive, Valid: true},
+		Size:   5,
+	})
+	require.NoError(t, err)
+	require.Empty(t, accounts)
+}
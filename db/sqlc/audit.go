@@ -0,0 +1,210 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash"
+	"strconv"
+	"time"
+)
+
+// Audit actions recorded by the store transactions
+const (
+	AuditTransferCreate   = "transfer.create"
+	AuditTransferCapture  = "transfer.capture"
+	AuditTransferVoid     = "transfer.void"
+	AuditHoldExpire       = "hold.expire"
+	AuditAccountDeposit   = "account.deposit"
+	AuditAccountWithdraw  = "account.withdraw"
+	AuditAccountClose     = "account.close"
+	AuditAccountStatus    = "account.update_status"
+	AuditAccountOverdraft = "account.update_overdraft_limit"
+	AuditAccountAdjust    = "account.adjust_entries"
+)
+
+// Audit target types, the kind of rows TargetIds point to
+const (
+	AuditTargetAccount  = "account"
+	AuditTargetTransfer = "transfer"
+)
+
+// Actors of the changes nobody logged in made
+const (
+	AuditActorAnonymous = "anonymous"
+	AuditActorSystem    = "system"
+)
+
+// GenesisHash is the prev_hash of the first audit log row
+var GenesisHash = make([]byte, sha256.Size)
+
+var errAuditOutsideTx = errors.New("audit records can only be written inside a store transaction")
+
+// AuditContext says who the changes of a request are made for, the store
+// transactions read it from the ctx they are given
+type AuditContext struct {
+	Actor     string
+	RequestID string
+}
+
+type auditContextKey struct{}
+
+// WithAuditContext returns a ctx that makes the store transactions record
+// audit as the actor of the request
+func WithAuditContext(ctx context.Context, audit AuditContext) context.Context {
+	return context.WithValue(ctx, auditContextKey{}, audit)
+}
+
+// AuditContextFrom returns the audit context of ctx, changes made outside a
+// request are recorded as the system's
+func AuditContextFrom(ctx context.Context) AuditContext {
+	audit, _ := ctx.Value(auditContextKey{}).(AuditContext)
+	if audit.Actor == "" {
+		audit.Actor = AuditActorSystem
+	}
+	return audit
+}
+
+// AuditRecord is a change to append to the audit log. Before and After are
+// snapshots of the changed rows, they are written as JSON
+type AuditRecord struct {
+	Actor      string
+	Action     string
+	TargetType string
+	TargetIDs  []int64
+	RequestID  string
+	Before     interface{}
+	After      interface{}
+}
+
+// recordAudit adds a record to the store transaction q belongs to. execTx
+// appends it to the audit log right before it commits, with the actor and
+// request id of its ctx unless the record has its own
+func recordAudit(q *Queries, record AuditRecord) error {
+	tx, ok := q.db.(*outboxTx)
+	if !ok {
+		return errAuditOutsideTx
+	}
+
+	tx.audits = append(tx.audits, record)
+	return nil
+}
+
+// writeAuditLog appends the records to the hash chain. The advisory lock
+// serializes appends until the transaction ends, so every row is linked to
+// the last committed one and no two rows share a previous row
+func writeAuditLog(ctx context.Context, q *Queries, records []AuditRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	if err := q.LockAuditLog(ctx); err != nil {
+		return err
+	}
+
+	prevHash, err := q.GetLastAuditLogHash(ctx)
+	if err == sql.ErrNoRows {
+		prevHash = GenesisHash
+	} else if err != nil {
+		return err
+	}
+
+	audit := AuditContextFrom(ctx)
+	for _, record := range records {
+		entry := AuditLog{
+			Actor:      record.Actor,
+			Action:     record.Action,
+			TargetType: record.TargetType,
+			TargetIds:  record.TargetIDs,
+			RequestID:  record.RequestID,
+			PrevHash:   prevHash,
+			// Postgres keeps microseconds, the hash has to match what is read back
+			CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		}
+		if entry.Actor == "" {
+			entry.Actor = audit.Actor
+		}
+		if entry.RequestID == "" {
+			entry.RequestID = audit.RequestID
+		}
+		if entry.TargetIds == nil {
+			entry.TargetIds = []int64{}
+		}
+
+		if entry.Before, err = json.Marshal(record.Before); err != nil {
+			return err
+		}
+		if entry.After, err = json.Marshal(record.After); err != nil {
+			return err
+		}
+		entry.Hash = entry.ComputeHash()
+
+		_, err = q.CreateAuditLog(ctx, CreateAuditLogParams{
+			Actor:      entry.Actor,
+			Action:     entry.Action,
+			TargetType: entry.TargetType,
+			TargetIds:  entry.TargetIds,
+			RequestID:  entry.RequestID,
+			Before:     entry.Before,
+			After:      entry.After,
+			PrevHash:   entry.PrevHash,
+			Hash:       entry.Hash,
+			CreatedAt:  entry.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		prevHash = entry.Hash
+	}
+
+	return nil
+}
+
+// ComputeHash returns the SHA-256 of the previous hash and every column but
+// the id and the hash itself. Each field is written with its length first so
+// moving bytes from one field to the next changes the hash
+func (entry AuditLog) ComputeHash() []byte {
+	h := sha256.New()
+
+	writeHashField(h, entry.PrevHash)
+	writeHashField(h, []byte(entry.Actor))
+	writeHashField(h, []byte(entry.Action))
+	writeHashField(h, []byte(entry.TargetType))
+
+	var ids []byte
+	for _, id := range entry.TargetIds {
+		ids = strconv.AppendInt(ids, id, 10)
+		ids = append(ids, ',')
+	}
+	writeHashField(h, ids)
+
+	writeHashField(h, []byte(entry.RequestID))
+	writeHashField(h, entry.Before)
+	writeHashField(h, entry.After)
+	writeHashField(h, strconv.AppendInt(nil, entry.CreatedAt.UnixMicro(), 10))
+
+	return h.Sum(nil)
+}
+
+// Valid reports whether the row still matches its hash
+func (entry AuditLog) Valid() bool {
+	return bytes.Equal(entry.ComputeHash(), entry.Hash)
+}
+
+func writeHashField(h hash.Hash, field []byte) {
+	var size [8]byte
+	binary.BigEndian.PutUint64(size[:], uint64(len(field)))
+	h.Write(size[:])
+	h.Write(field)
+}
+
+// AuditTx appends a single record to the audit log in its own transaction
+func (store *SQLStore) AuditTx(ctx context.Context, record AuditRecord) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		return recordAudit(q, record)
+	})
+}
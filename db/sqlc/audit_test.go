@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepositTxAudit(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	requestID := util.RandomString(16)
+	ctx := WithAuditContext(context.Background(), AuditContext{
+		Actor:     account.Name,
+		RequestID: requestID,
+	})
+
+	result, err := store.DepositTx(ctx, DepositTxParams{
+		AccountID: account.ID,
+		Amount:    10,
+	})
+	require.NoError(t, err)
+
+	entries, err := testQueries.ListAuditLogByRequest(context.Background(), requestID)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	entry := entries[0]
+	require.Equal(t, account.Name, entry.Actor)
+	require.Equal(t, AuditAccountDeposit, entry.Action)
+	require.Equal(t, AuditTargetAccount, entry.TargetType)
+	require.Equal(t, []int64{account.ID}, entry.TargetIds)
+	require.True(t, entry.Valid())
+
+	var before Account
+	require.NoError(t, json.Unmarshal(entry.Before, &before))
+	require.Equal(t, account.Balance, before.Balance)
+
+	var after DepositTxResult
+	require.NoError(t, json.Unmarshal(entry.After, &after))
+	require.Equal(t, result.Account.Balance, after.Account.Balance)
+}
+
+func TestCloseAccountTxAuditChain(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	sweepAccount, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Name:     account.Name,
+		Balance:  0,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+
+	requestID := util.RandomString(16)
+	ctx := WithAuditContext(context.Background(), AuditContext{RequestID: requestID})
+
+	_, err = store.CloseAccountTx(ctx, CloseAccountTxParams{
+		AccountID:        account.ID,
+		SweepToAccountID: sweepAccount.ID,
+	})
+	require.NoError(t, err)
+
+	entries, err := testQueries.ListAuditLogByRequest(context.Background(), requestID)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// the sweep comes first, both rows were written in one transaction so
+	// nothing else can sit between them
+	require.Equal(t, AuditTransferCreate, entries[0].Action)
+	require.Equal(t, AuditAccountClose, entries[1].Action)
+	require.Equal(t, entries[0].Hash, entries[1].PrevHash)
+	require.Equal(t, entries[0].ID+1, entries[1].ID)
+
+	for _, entry := range entries {
+		require.Equal(t, AuditActorSystem, entry.Actor)
+		require.True(t, entry.Valid())
+	}
+}
+
+func TestAuditTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	requestID := util.RandomString(16)
+	err := store.AuditTx(context.Background(), AuditRecord{
+		Actor:     "alice",
+		Action:    "POST /api/v1/accounts",
+		RequestID: requestID,
+		After:     map[string]int{"status": 200},
+	})
+	require.NoError(t, err)
+
+	entries, err := testQueries.ListAuditLogByRequest(context.Background(), requestID)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "alice", entries[0].Actor)
+	require.Empty(t, entries[0].TargetIds)
+	require.JSONEq(t, `null`, string(entries[0].Before))
+	require.JSONEq(t, `{"status": 200}`, string(entries[0].After))
+	require.True(t, entries[0].Valid())
+
+	// the previous row is whatever was appended last, but it must exist
+	previous, err := testQueries.ListAuditLog(context.Background(), ListAuditLogParams{
+		AfterID: entries[0].ID - 1,
+		Size:    1,
+	})
+	require.NoError(t, err)
+	require.Len(t, previous, 1)
+}
+
+func TestAuditLogAppendOnly(t *testing.T) {
+	store := NewStore(testDB)
+
+	requestID := util.RandomString(16)
+	require.NoError(t, store.AuditTx(context.Background(), AuditRecord{Action: "test", RequestID: requestID}))
+
+	_, err := testDB.Exec("UPDATE audit_log SET actor = 'mallory' WHERE request_id = $1", requestID)
+	require.ErrorContains(t, err, "append-only")
+
+	_, err = testDB.Exec("DELETE FROM audit_log WHERE request_id = $1", requestID)
+	require.ErrorContains(t, err, "append-only")
+}
+
+func TestRecordAuditOutsideTx(t *testing.T) {
+	err := recordAudit(testQueries, AuditRecord{Action: AuditAccountDeposit})
+	require.ErrorIs(t, err, errAuditOutsideTx)
+}
+
+func TestAuditLogComputeHash(t *testing.T) {
+	entry := AuditLog{
+		Actor:     "alice",
+		Action:    AuditAccountDeposit,
+		TargetIds: []int64{1, 2},
+		Before:    json.RawMessage(`null`),
+		After:     json.RawMessage(`{"balance":10}`),
+		PrevHash:  GenesisHash,
+		CreatedAt: time.Date(2024, time.January, 2, 3, 4, 5, 6000, time.UTC),
+	}
+	entry.Hash = entry.ComputeHash()
+	require.Len(t, entry.Hash, 32)
+	require.True(t, entry.Valid())
+
+	// the same instant in another zone hashes the same
+	moved := entry
+	moved.CreatedAt = entry.CreatedAt.In(time.FixedZone("UTC+8", 8*60*60))
+	require.True(t, moved.Valid())
+
+	tampered := entry
+	tampered.After = json.RawMessage(`{"balance":1000}`)
+	require.False(t, tampered.Valid())
+
+	// moving a byte from one field to the next changes the hash
+	shifted := entry
+	shifted.Actor = "alic"
+	shifted.Action = "e" + entry.Action
+	require.False(t, shifted.Valid())
+}
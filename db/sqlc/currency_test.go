@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomCurrency(t *testing.T) Currency {
+	arg := CreateCurrencyParams{
+		Code:        strings.ToUpper(util.RandomString(3)),
+		NumericCode: int32(util.RandomInt(1000, 1000000)),
+		Exponent:    int32(util.RandomInt(0, 4)),
+		Enabled:     false,
+	}
+
+	currency, err := testQueries.CreateCurrency(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.Code, currency.Code)
+	require.Equal(t, arg.NumericCode, currency.NumericCode)
+	require.Equal(t, arg.Exponent, currency.Exponent)
+	require.False(t, currency.Enabled)
+	require.NotZero(t, currency.CreatedAt)
+
+	return currency
+}
+
+func TestSeededCurrencies(t *testing.T) {
+	seeded := []util.Currency{
+		{Code: util.USD, NumericCode: 840, Exponent: 2, Enabled: true},
+		{Code: util.EUR, NumericCode: 978, Exponent: 2, Enabled: true},
+		{Code: util.CAD, NumericCode: 124, Exponent: 2, Enabled: true},
+		{Code: util.GBP, NumericCode: 826, Exponent: 2, Enabled: false},
+		{Code: util.JPY, NumericCode: 392, Exponent: 0, Enabled: false},
+		{Code: util.BHD, NumericCode: 48, Exponent: 3, Enabled: false},
+	}
+
+	for _, expected := range seeded {
+		currency, err := testQueries.GetCurrency(context.Background(), expected.Code)
+		require.NoError(t, err)
+		require.Equal(t, expected.NumericCode, currency.NumericCode)
+		require.Equal(t, expected.Exponent, currency.Exponent)
+	}
+}
+
+func TestUpdateCurrencyEnabled(t *testing.T) {
+	currency1 := createRandomCurrency(t)
+
+	currency2, err := testQueries.UpdateCurrencyEnabled(context.Background(), UpdateCurrencyEnabledParams{
+		Code:    currency1.Code,
+		Enabled: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, currency1.Code, currency2.Code)
+	require.True(t, currency2.Enabled)
+}
+
+func TestListCurrencies(t *testing.T) {
+	currency := createRandomCurrency(t)
+
+	currencies, err := testQueries.ListCurrencies(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, currencies, currency)
+}
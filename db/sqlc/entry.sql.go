@@ -7,50 +7,58 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id,
+  description
 ) VALUES (
-  $1, $2
-) RETURNING id, account_id, amount, created_at
+  $1, $2, $3, $4
+) RETURNING id, account_id, amount, created_at, transfer_id, description
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID   int64  `json:"account_id"`
+	Amount      int64  `json:"amount"`
+	TransferID  *int64 `json:"transfer_id"`
+	Description string `json:"description"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount, arg.TransferID)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.Description,
 	)
 	return i, err
 }
 
-const getEntries = `-- name: GetEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+const getEntriesBetween = `-- name: GetEntriesBetween :many
+SELECT id, account_id, amount, created_at, transfer_id, description FROM entries
 WHERE account_id = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3
+    AND created_at >= $2
+    AND created_at < $3
+ORDER BY created_at, id
 `
 
-type GetEntriesParams struct {
-	AccountID int64 `json:"account_id"`
-	Limit     int32 `json:"limit"`
-	Offset    int32 `json:"offset"`
+type GetEntriesBetweenParams struct {
+	AccountID int64     `json:"account_id"`
+	FromTime  time.Time `json:"from_time"`
+	ToTime    time.Time `json:"to_time"`
 }
 
-func (q *Queries) GetEntries(ctx context.Context, arg GetEntriesParams) ([]Entry, error) {
-	rows, err := q.db.QueryContext(ctx, getEntries, arg.AccountID, arg.Limit, arg.Offset)
+func (q *Queries) GetEntriesBetween(ctx context.Context, arg GetEntriesBetweenParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, getEntriesBetween, arg.AccountID, arg.FromTime, arg.ToTime)
 	if err != nil {
 		return nil, err
 	}
@@ -63,6 +71,8 @@ func (q *Queries) GetEntries(ctx context.Context, arg GetEntriesParams) ([]Entry
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.Description,
 		); err != nil {
 			return nil, err
 		}
@@ -78,7 +88,7 @@ func (q *Queries) GetEntries(ctx context.Context, arg GetEntriesParams) ([]Entry
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, description FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -90,6 +100,110 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.Description,
 	)
 	return i, err
 }
+
+const listEntries = `-- name: ListEntries :many
+SELECT entries.id, entries.account_id, entries.amount, entries.created_at, entries.transfer_id, entries.description FROM entries
+LEFT JOIN transfers ON transfers.id = entries.transfer_id
+WHERE entries.account_id = $1
+    AND (entries.created_at, entries.id) > ($2::timestamptz, $3::bigint)
+    AND ($4::timestamptz IS NULL OR entries.created_at >= $4)
+    AND ($5::timestamptz IS NULL OR entries.created_at <= $5)
+    AND ($6::bigint IS NULL OR abs(entries.amount) >= $6)
+    AND ($7::bigint IS NULL OR abs(entries.amount) <= $7)
+    AND ($8::text IS NULL
+        OR ($8 = 'credit' AND entries.amount > 0)
+        OR ($8 = 'debit' AND entries.amount < 0))
+    AND ($9::bigint IS NULL
+        OR $9 IN (transfers.from_account_id, transfers.to_account_id))
+ORDER BY entries.created_at, entries.id
+LIMIT $10
+`
+
+type ListEntriesParams struct {
+	AccountID      int64          `json:"account_id"`
+	AfterCreatedAt time.Time      `json:"after_created_at"`
+	AfterID        int64          `json:"after_id"`
+	FromTime       sql.NullTime   `json:"from_time"`
+	ToTime         sql.NullTime   `json:"to_time"`
+	MinAmount      sql.NullInt64  `json:"min_amount"`
+	MaxAmount      sql.NullInt64  `json:"max_amount"`
+	Direction      sql.NullString `json:"direction"`
+	CounterpartyID sql.NullInt64  `json:"counterparty_id"`
+	Size           int32          `json:"size"`
+}
+
+func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listEntries,
+		arg.AccountID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Direction,
+		arg.CounterpartyID,
+		arg.Size,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.Description,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sumEntries = `-- name: SumEntries :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries
+WHERE account_id = $1
+`
+
+func (q *Queries) SumEntries(ctx context.Context, accountID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumEntries, accountID)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const sumEntriesSince = `-- name: SumEntriesSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries
+WHERE account_id = $1
+    AND created_at >= $2
+`
+
+type SumEntriesSinceParams struct {
+	AccountID int64     `json:"account_id"`
+	Since     time.Time `json:"since"`
+}
+
+func (q *Queries) SumEntriesSince(ctx context.Context, arg SumEntriesSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumEntriesSince, arg.AccountID, arg.Since)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
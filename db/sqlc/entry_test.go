@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -52,25 +53,115 @@ func TestGetEntry(t *testing.T) {
 	require.WithinDuration(t, entry1.CreatedAt, entry2.CreatedAt, time.Second)
 }
 
-func TestGetEntries(t *testing.T) {
-	account := createRandomAccount(t)
+func TestListEntries(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account3 := createRandomAccount(t)
+	store := NewStore(testDB)
+
+	// make sure the sender can cover every transfer
+	_, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account3.ID,
+		Amount: 60,
+	})
+	require.NoError(t, err)
 
-	for i := 0; i < 10; i++ {
-		createRandomEntry(t, account.ID)
+	for i := 0; i < 3; i++ {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account3.ID,
+			ToAccountID:   account1.ID,
+			Amount:        20,
+		})
+		require.NoError(t, err)
+
+		_, err = store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		})
+		require.NoError(t, err)
 	}
 
-	arg := GetEntriesParams {
-		AccountID: account.ID,
-		Limit: 5,
-		Offset: 5,
+	testCases := []struct {
+		name  string
+		arg   ListEntriesParams
+		count int
+		check func(entry Entry)
+	}{
+		{
+			name:  "All",
+			arg:   ListEntriesParams{},
+			count: 6,
+		},
+		{
+			name:  "Debit",
+			arg:   ListEntriesParams{Direction: sql.NullString{String: "debit", Valid: true}},
+			count: 3,
+			check: func(entry Entry) {
+				require.Negative(t, entry.Amount)
+			},
+		},
+		{
+			name:  "Credit",
+			arg:   ListEntriesParams{Direction: sql.NullString{String: "credit", Valid: true}},
+			count: 3,
+			check: func(entry Entry) {
+				require.Positive(t, entry.Amount)
+			},
+		},
+		{
+			name:  "MinAmount",
+			arg:   ListEntriesParams{MinAmount: sql.NullInt64{Int64: 15, Valid: true}},
+			count: 3,
+			check: func(entry Entry) {
+				require.Equal(t, int64(20), entry.Amount)
+			},
+		},
+		{
+			name:  "MaxAmount",
+			arg:   ListEntriesParams{MaxAmount: sql.NullInt64{Int64: 15, Valid: true}},
+			count: 3,
+			check: func(entry Entry) {
+				require.Equal(t, int64(-10), entry.Amount)
+			},
+		},
+		{
+			name:  "Counterparty",
+			arg:   ListEntriesParams{CounterpartyID: sql.NullInt64{Int64: account3.ID, Valid: true}},
+			count: 3,
+			check: func(entry Entry) {
+				require.Equal(t, int64(20), entry.Amount)
+			},
+		},
+		{
+			name:  "Future",
+			arg:   ListEntriesParams{FromTime: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}},
+			count: 0,
+		},
+		{
+			name:  "Past",
+			arg:   ListEntriesParams{ToTime: sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true}},
+			count: 0,
+		},
 	}
 
-	entries, err := testQueries.GetEntries(context.Background(), arg)
+	for i := range testCases {
+		tc := testCases[i]
 
-	require.NoError(t, err)
-	require.Len(t, entries, 5)
+		t.Run(tc.name, func(t *testing.T) {
+			tc.arg.AccountID = account1.ID
+			tc.arg.Size = 10
 
-	for _, entry := range entries {
-		require.NotEmpty(t, entry)
+			entries, err := testQueries.ListEntries(context.Background(), tc.arg)
+			require.NoError(t, err)
+			require.Len(t, entries, tc.count)
+
+			for _, entry := range entries {
+				require.Equal(t, account1.ID, entry.AccountID)
+				if tc.check != nil {
+					tc.check(entry)
+				}
+			}
+		})
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomExchangeRate(t *testing.T) ExchangeRate {
+	arg := UpsertExchangeRateParams{
+		FromCurrency: util.RandomString(3),
+		ToCurrency:   util.RandomString(3),
+		Rate:         "1.25000000",
+		SpreadBps:    int32(util.RandomInt(0, 100)),
+	}
+
+	rate, err := testQueries.UpsertExchangeRate(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.FromCurrency, rate.FromCurrency)
+	require.Equal(t, arg.ToCurrency, rate.ToCurrency)
+	require.Equal(t, arg.Rate, rate.Rate)
+	require.Equal(t, arg.SpreadBps, rate.SpreadBps)
+	require.NotZero(t, rate.UpdatedAt)
+
+	return rate
+}
+
+func TestUpsertExchangeRate(t *testing.T) {
+	rate1 := createRandomExchangeRate(t)
+
+	rate2, err := testQueries.UpsertExchangeRate(context.Background(), UpsertExchangeRateParams{
+		FromCurrency: rate1.FromCurrency,
+		ToCurrency:   rate1.ToCurrency,
+		Rate:         "0.80000000",
+		SpreadBps:    rate1.SpreadBps + 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "0.80000000", rate2.Rate)
+	require.Equal(t, rate1.SpreadBps+1, rate2.SpreadBps)
+}
+
+func TestGetExchangeRate(t *testing.T) {
+	rate1 := createRandomExchangeRate(t)
+
+	rate2, err := testQueries.GetExchangeRate(context.Background(), GetExchangeRateParams{
+		FromCurrency: rate1.FromCurrency,
+		ToCurrency:   rate1.ToCurrency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, rate1, rate2)
+}
+
+func TestDeleteExchangeRate(t *testing.T) {
+	rate1 := createRandomExchangeRate(t)
+
+	arg := DeleteExchangeRateParams{
+		FromCurrency: rate1.FromCurrency,
+		ToCurrency:   rate1.ToCurrency,
+	}
+	err := testQueries.DeleteExchangeRate(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetExchangeRate(context.Background(), GetExchangeRateParams(arg))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestListExchangeRates(t *testing.T) {
+	createRandomExchangeRate(t)
+
+	rates, err := testQueries.ListExchangeRates(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, rates)
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomFxQuote(t *testing.T) FxQuote {
+	user := createRandomUser(t)
+
+	arg := CreateFxQuoteParams{
+		ID:           uuid.New(),
+		Username:     user.Username,
+		FromCurrency: "USD",
+		ToCurrency:   "EUR",
+		Rate:         "0.92000000",
+		SpreadBps:    50,
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+
+	quote, err := testQueries.CreateFxQuote(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.ID, quote.ID)
+	require.Equal(t, arg.Username, quote.Username)
+	require.Equal(t, arg.FromCurrency, quote.FromCurrency)
+	require.Equal(t, arg.ToCurrency, quote.ToCurrency)
+	require.Equal(t, arg.Rate, quote.Rate)
+	require.Equal(t, arg.SpreadBps, quote.SpreadBps)
+	require.WithinDuration(t, arg.ExpiresAt, quote.ExpiresAt, time.Second)
+	require.NotZero(t, quote.CreatedAt)
+
+	return quote
+}
+
+func TestCreateFxQuote(t *testing.T) {
+	createRandomFxQuote(t)
+}
+
+func TestGetFxQuote(t *testing.T) {
+	quote1 := createRandomFxQuote(t)
+
+	quote2, err := testQueries.GetFxQuote(context.Background(), quote1.ID)
+	require.NoError(t, err)
+	require.Equal(t, quote1.ID, quote2.ID)
+	require.Equal(t, quote1.Rate, quote2.Rate)
+	require.WithinDuration(t, quote1.ExpiresAt, quote2.ExpiresAt, time.Second)
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomIdempotencyKey(t *testing.T) IdempotencyKey {
+	user := createRandomUser(t)
+
+	arg := CreateIdempotencyKeyParams{
+		Username:     user.Username,
+		Key:          util.RandomString(16),
+		RequestHash:  util.RandomString(64),
+		ResponseBody: []byte(`{"ok":true}`),
+	}
+
+	key, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, key)
+
+	require.Equal(t, arg.Username, key.Username)
+	require.Equal(t, arg.Key, key.Key)
+	require.Equal(t, arg.RequestHash, key.RequestHash)
+	require.JSONEq(t, string(arg.ResponseBody), string(key.ResponseBody))
+	require.NotZero(t, key.CreatedAt)
+
+	return key
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	key := createRandomIdempotencyKey(t)
+
+	// the same key cannot be stored twice for a user
+	_, err := testQueries.CreateIdempotencyKey(context.Background(), CreateIdempotencyKeyParams{
+		Username:     key.Username,
+		Key:          key.Key,
+		RequestHash:  key.RequestHash,
+		ResponseBody: key.ResponseBody,
+	})
+	require.Error(t, err)
+}
+
+func TestGetIdempotencyKey(t *testing.T) {
+	key1 := createRandomIdempotencyKey(t)
+
+	key2, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: key1.Username,
+		Key:      key1.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, key1.Username, key2.Username)
+	require.Equal(t, key1.Key, key2.Key)
+	require.Equal(t, key1.RequestHash, key2.RequestHash)
+	require.JSONEq(t, string(key1.ResponseBody), string(key2.ResponseBody))
+}
@@ -5,7 +5,10 @@
 package db
 
 import (
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // Account represents an account in the database.
@@ -15,6 +18,42 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// How far below zero the balance may go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	// active, frozen (no money can move) or closed (final)
+	Status string `json:"status"`
+	// Sum of the active holds on the account
+	HeldBalance int64 `json:"held_balance"`
+	// Balance that is not held by a pending transfer
+	AvailableBalance int64 `json:"available_balance"`
+}
+
+type AuditLog struct {
+	ID int64 `json:"id"`
+	// Username that made the change, anonymous or system when there is none
+	Actor      string  `json:"actor"`
+	Action     string  `json:"action"`
+	TargetType string  `json:"target_type"`
+	TargetIds  []int64 `json:"target_ids"`
+	RequestID  string  `json:"request_id"`
+	// Snapshot of the rows before the change, JSON kept as written so the hash can be checked
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+	// SHA-256 of the previous row, all zeros for the first one
+	PrevHash []byte `json:"prev_hash"`
+	// SHA-256 of prev_hash and the other columns except id
+	Hash      []byte    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Currency struct {
+	// ISO 4217 alphabetic code
+	Code        string `json:"code"`
+	NumericCode int32  `json:"numeric_code"`
+	// Number of minor unit digits, amounts are stored in minor units
+	Exponent  int32     `json:"exponent"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Entry struct {
@@ -23,6 +62,115 @@ type Entry struct {
 	// Can be negative or positive value
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// Transfer that wrote the entry, null for deposits and withdrawals
+	TransferID  *int64 `json:"transfer_id"`
+	Description string `json:"description"`
+}
+
+type ExchangeRate struct {
+	FromCurrency string `json:"from_currency"`
+	ToCurrency   string `json:"to_currency"`
+	Rate         string `json:"rate"`
+	// Spread taken from the converted amount in basis points
+	SpreadBps int32     `json:"spread_bps"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type FxQuote struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	FromCurrency string    `json:"from_currency"`
+	ToCurrency   string    `json:"to_currency"`
+	Rate         string    `json:"rate"`
+	SpreadBps    int32     `json:"spread_bps"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type Hold struct {
+	ID         int64     `json:"id"`
+	TransferID int64     `json:"transfer_id"`
+	AccountID  int64     `json:"account_id"`
+	Amount     int64     `json:"amount"`
+	Status     string    `json:"status"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	Username     string          `json:"username"`
+	Key          string          `json:"key"`
+	RequestHash  string          `json:"request_hash"`
+	ResponseBody json.RawMessage `json:"response_body"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// Domain events written in the transaction that raised them, relayed to the event publisher
+type OutboxEvent struct {
+	ID            int64           `json:"id"`
+	EventID       uuid.UUID       `json:"event_id"`
+	EventType     string          `json:"event_type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+	// When the relay published the event, null until then
+	SentAt *time.Time `json:"sent_at"`
+}
+
+type ReconciliationRun struct {
+	ID          int64  `json:"id"`
+	TriggeredBy string `json:"triggered_by"`
+	// Whether adjustment entries were written for the mismatches
+	Adjust          bool   `json:"adjust"`
+	Status          string `json:"status"`
+	AccountsChecked int64  `json:"accounts_checked"`
+	MismatchCount   int64  `json:"mismatch_count"`
+	// Accounts whose balance differs from the sum of their entries
+	Mismatches json.RawMessage `json:"mismatches"`
+	Error      string          `json:"error"`
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt time.Time       `json:"finished_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64      `json:"id"`
+	Owner         string     `json:"owner"`
+	FromAccountID int64      `json:"from_account_id"`
+	ToAccountID   int64      `json:"to_account_id"`
+	Amount        int64      `json:"amount"`
+	Currency      string     `json:"currency"`
+	IntervalUnit  string     `json:"interval_unit"`
+	IntervalCount int32      `json:"interval_count"`
+	StartAt       time.Time  `json:"start_at"`
+	EndAt         *time.Time `json:"end_at"`
+	NextRunAt     time.Time  `json:"next_run_at"`
+	// Index of next_run_at in the schedule, counted from start_at
+	Occurrence int32     `json:"occurrence"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64     `json:"id"`
+	ScheduledTransferID int64     `json:"scheduled_transfer_id"`
+	ScheduledFor        time.Time `json:"scheduled_for"`
+	Status              string    `json:"status"`
+	// Transfer made by the run, null when it failed
+	TransferID *int64    `json:"transfer_id"`
+	Error      string    `json:"error"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type Session struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	RefreshToken string    `json:"refresh_token"`
+	UserAgent    string    `json:"user_agent"`
+	ClientIp     string    `json:"client_ip"`
+	IsBlocked    bool      `json:"is_blocked"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 type Transfer struct {
@@ -32,6 +180,20 @@ type Transfer struct {
 	// Must be positive value
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// Amount credited in the currency of the receiving account
+	ToAmount     int64  `json:"to_amount"`
+	ExchangeRate string `json:"exchange_rate"`
+	SpreadBps    int32  `json:"spread_bps"`
+	// Transfer this one reverses, null for regular transfers
+	ReversalOf *int64 `json:"reversal_of"`
+	// pending while the money is held, posted once it moved, voided or expired when the hold was released
+	Status string `json:"status"`
+	// Free text memo, copied onto both entries
+	Description string `json:"description"`
+	// Reference of the payment in the client's own system
+	ExternalReference string `json:"external_reference"`
+	// Client defined JSON object, at most 4 KB
+	Metadata json.RawMessage `json:"metadata" swaggertype:"object"`
 }
 
 type User struct {
@@ -41,4 +203,34 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	// customer, banker (can see and freeze every account) or admin (can also manage currencies, rates and roles)
+	Role string `json:"role"`
+}
+
+type WebhookDelivery struct {
+	ID             int64 `json:"id"`
+	SubscriptionID int64 `json:"subscription_id"`
+	// Same for every delivery of one event so receivers can drop duplicates
+	EventID   uuid.UUID       `json:"event_id"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload" swaggertype:"object"`
+	// pending until the receiver answers 2xx, dead once every attempt failed
+	Status        string     `json:"status"`
+	Attempts      int32      `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastAttemptAt *time.Time `json:"last_attempt_at"`
+	// HTTP status of the last attempt, 0 when no response was received
+	ResponseStatus int32     `json:"response_status"`
+	LastError      string    `json:"last_error"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type WebhookSubscription struct {
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	Url   string `json:"url"`
+	// Key of the HMAC-SHA256 signature sent with every delivery
+	Secret     string    `json:"secret"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
 }
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types raised by the store transactions
+const (
+	EventTransferCreated       = "transfer.created"
+	EventAccountBalanceChanged = "account.balance_changed"
+	EventAccountFrozen         = "account.frozen"
+)
+
+// Aggregate types, the kind of row an event is about
+const (
+	AggregateAccount  = "account"
+	AggregateTransfer = "transfer"
+)
+
+// AccountEventsChannel is the Postgres channel the account events are
+// notified on, the NotifyAccountEvent query uses the same name
+const AccountEventsChannel = "account_events"
+
+var errEventOutsideTx = errors.New("events can only be raised inside a store transaction")
+
+// Event is a domain event. Its JSON is the payload stored in the outbox and
+// posted to the webhooks
+type Event struct {
+	ID            uuid.UUID   `json:"id"`
+	Type          string      `json:"type"`
+	AggregateType string      `json:"aggregate_type"`
+	AggregateID   int64       `json:"aggregate_id"`
+	CreatedAt     time.Time   `json:"created_at"`
+	Data          interface{} `json:"data"`
+	// Owners are the users the event is about, their webhooks receive it
+	Owners []string `json:"-"`
+}
+
+// BalanceChangedEvent is the data of an account.balance_changed event,
+// Amount is what was added to the balance and TransferID is set when a
+// transfer moved the money
+type BalanceChangedEvent struct {
+	Account    Account `json:"account"`
+	Amount     int64   `json:"amount"`
+	TransferID *int64  `json:"transfer_id,omitempty"`
+}
+
+// outboxTx is the DBTX behind the Queries of a store transaction, it
+// collects the events raised and the audit records written by the
+// transaction for execTx
+type outboxTx struct {
+	*sql.Tx
+	events []Event
+	audits []AuditRecord
+}
+
+// publishEvent raises an event in the store transaction q belongs to.
+// execTx appends it to the outbox right before it commits, so the event is
+// only published when the change that raised it commits, and never lost
+// when it does
+func publishEvent(q *Queries, event Event) error {
+	tx, ok := q.db.(*outboxTx)
+	if !ok {
+		return errEventOutsideTx
+	}
+
+	event.ID = uuid.New()
+	event.CreatedAt = time.Now().UTC()
+	tx.events = append(tx.events, event)
+	return nil
+}
+
+// publishBalanceChanged raises an account.balance_changed event for the
+// account
+func publishBalanceChanged(q *Queries, account Account, amount int64, transferID *int64) error {
+	return publishEvent(q, Event{
+		Type:          EventAccountBalanceChanged,
+		AggregateType: AggregateAccount,
+		AggregateID:   account.ID,
+		Data: BalanceChangedEvent{
+			Account:    account,
+			Amount:     amount,
+			TransferID: transferID,
+		},
+		Owners: []string{account.Name},
+	})
+}
+
+// writeOutbox appends the events to the outbox, queues their webhook
+// deliveries and notifies the account events, in the order they were raised.
+// Postgres only delivers the notifications when the transaction commits
+func writeOutbox(ctx context.Context, q *Queries, events []Event) error {
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+			EventID:       event.ID,
+			EventType:     event.Type,
+			AggregateType: event.AggregateType,
+			AggregateID:   event.AggregateID,
+			Payload:       payload,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := queueWebhooks(ctx, q, event, payload); err != nil {
+			return err
+		}
+
+		if event.AggregateType == AggregateAccount {
+			if err := q.NotifyAccountEvent(ctx, string(payload)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
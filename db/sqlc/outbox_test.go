@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferTxOutbox(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	events, err := testQueries.ListOutboxEventsByAggregate(context.Background(), ListOutboxEventsByAggregateParams{
+		AggregateType: AggregateTransfer,
+		AggregateID:   result.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, EventTransferCreated, events[0].EventType)
+	require.Nil(t, events[0].SentAt)
+
+	var event struct {
+		ID   string   `json:"id"`
+		Data Transfer `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(events[0].Payload, &event))
+	require.Equal(t, events[0].EventID.String(), event.ID)
+	require.Equal(t, result.Transfer.ID, event.Data.ID)
+
+	for _, account := range []Account{account1, account2} {
+		events, err := testQueries.ListOutboxEventsByAggregate(context.Background(), ListOutboxEventsByAggregateParams{
+			AggregateType: AggregateAccount,
+			AggregateID:   account.ID,
+		})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, EventAccountBalanceChanged, events[0].EventType)
+	}
+}
+
+func TestTransferTxOutboxRollback(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + account1.OverdraftLimit + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	events, err := testQueries.ListOutboxEventsByAggregate(context.Background(), ListOutboxEventsByAggregateParams{
+		AggregateType: AggregateAccount,
+		AggregateID:   account1.ID,
+	})
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestPublishEventOutsideTx(t *testing.T) {
+	err := publishEvent(testQueries, Event{Type: EventAccountFrozen})
+	require.ErrorIs(t, err, errEventOutsideTx)
+}
+
+func TestRelayOutboxTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	_, err := store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account.ID,
+		Amount:    10,
+	})
+	require.NoError(t, err)
+
+	listEvents := func() []OutboxEvent {
+		events, err := testQueries.ListOutboxEventsByAggregate(context.Background(), ListOutboxEventsByAggregateParams{
+			AggregateType: AggregateAccount,
+			AggregateID:   account.ID,
+		})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		return events
+	}
+	event := listEvents()[0]
+
+	// a failed publish leaves the event unsent
+	errPublish := errors.New("broker is down")
+	_, err = store.RelayOutboxTx(context.Background(), RelayOutboxTxParams{
+		Now:  time.Now(),
+		Size: 1000,
+		Publish: func(ctx context.Context, events []OutboxEvent) error {
+			return errPublish
+		},
+	})
+	require.ErrorIs(t, err, errPublish)
+	require.Nil(t, listEvents()[0].SentAt)
+
+	// older events left by other tests are relayed first, keep going until
+	// the deposit's event is sent
+	var published []OutboxEvent
+	for listEvents()[0].SentAt == nil {
+		sent, err := store.RelayOutboxTx(context.Background(), RelayOutboxTxParams{
+			Now:  time.Now(),
+			Size: 1000,
+			Publish: func(ctx context.Context, events []OutboxEvent) error {
+				published = append(published, events...)
+				return nil
+			},
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, sent)
+	}
+
+	found := false
+	for _, sent := range published {
+		if sent.ID == event.ID {
+			found = true
+		}
+	}
+	require.True(t, found)
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomReconciliationRun(t *testing.T) ReconciliationRun {
+	arg := CreateReconciliationRunParams{
+		TriggeredBy:     util.RandomName(),
+		Status:          "succeeded",
+		AccountsChecked: util.RandomInt(1, 100),
+		MismatchCount:   1,
+		Mismatches:      []byte(`[{"account_id":1,"delta":10}]`),
+		StartedAt:       time.Now(),
+	}
+
+	run, err := testQueries.CreateReconciliationRun(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, run)
+
+	require.Equal(t, arg.TriggeredBy, run.TriggeredBy)
+	require.Equal(t, arg.Status, run.Status)
+	require.Equal(t, arg.AccountsChecked, run.AccountsChecked)
+	require.Equal(t, arg.MismatchCount, run.MismatchCount)
+	require.JSONEq(t, string(arg.Mismatches), string(run.Mismatches))
+	require.Empty(t, run.Error)
+	require.WithinDuration(t, arg.StartedAt, run.StartedAt, time.Second)
+	require.NotZero(t, run.FinishedAt)
+
+	return run
+}
+
+func TestCreateReconciliationRun(t *testing.T) {
+	createRandomReconciliationRun(t)
+}
+
+func TestGetReconciliationRun(t *testing.T) {
+	run1 := createRandomReconciliationRun(t)
+
+	run2, err := testQueries.GetReconciliationRun(context.Background(), run1.ID)
+	require.NoError(t, err)
+	require.Equal(t, run1.ID, run2.ID)
+	require.Equal(t, run1.TriggeredBy, run2.TriggeredBy)
+	require.WithinDuration(t, run1.FinishedAt, run2.FinishedAt, time.Second)
+}
+
+func TestListReconciliationRuns(t *testing.T) {
+	var last ReconciliationRun
+	for i := 0; i < 3; i++ {
+		last = createRandomReconciliationRun(t)
+	}
+
+	runs, err := testQueries.ListReconciliationRuns(context.Background(), 3)
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	require.Equal(t, last.ID, runs[0].ID)
+	require.Greater(t, runs[0].ID, runs[1].ID)
+}
+
+func TestListAccountEntryTotals(t *testing.T) {
+	account := createRandomAccount(t)
+	entry := createRandomEntry(t, account.ID)
+
+	totals, err := testQueries.ListAccountEntryTotals(context.Background(), ListAccountEntryTotalsParams{
+		AfterID: account.ID - 1,
+		Size:    1,
+	})
+	require.NoError(t, err)
+	require.Len(t, totals, 1)
+	require.Equal(t, account.ID, totals[0].ID)
+	require.Equal(t, account.Balance, totals[0].Balance)
+	require.Equal(t, entry.Amount, totals[0].EntriesTotal)
+}
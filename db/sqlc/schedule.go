@@ -0,0 +1,69 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// Schedule interval units of a scheduled transfer
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// Scheduled transfer statuses, only active ones are picked up by the worker
+const (
+	ScheduledTransferActive    = "active"
+	ScheduledTransferPaused    = "paused"
+	ScheduledTransferCompleted = "completed"
+	ScheduledTransferCancelled = "cancelled"
+)
+
+// Scheduled transfer run statuses
+const (
+	ScheduledRunSucceeded = "succeeded"
+	ScheduledRunFailed    = "failed"
+)
+
+// scheduledRunRequestHash marks the idempotency keys of scheduled runs
+const scheduledRunRequestHash = "scheduled_transfer"
+
+// Idempotency returns the key the transfer of the due run is stored under,
+// one per schedule and occurrence
+func (scheduled ScheduledTransfer) Idempotency() *IdempotencyParams {
+	return &IdempotencyParams{
+		Username:    scheduled.Owner,
+		Key:         fmt.Sprintf("scheduled_transfer:%d:%d", scheduled.ID, scheduled.Occurrence),
+		RequestHash: scheduledRunRequestHash,
+	}
+}
+
+// RunAt returns when the n-th run of the schedule is due, the first run is
+// n = 0. Runs are counted from StartAt instead of the previous run so a
+// monthly transfer on the 31st comes back to the 31st after a short month
+func (scheduled ScheduledTransfer) RunAt(n int32) time.Time {
+	steps := int(n) * int(scheduled.IntervalCount)
+
+	switch scheduled.IntervalUnit {
+	case IntervalDay:
+		return scheduled.StartAt.AddDate(0, 0, steps)
+	case IntervalWeek:
+		return scheduled.StartAt.AddDate(0, 0, 7*steps)
+	default:
+		return addMonths(scheduled.StartAt, steps)
+	}
+}
+
+// addMonths moves t by months and clamps the day to the end of the month
+// instead of spilling into the next one like time.AddDate does
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+
+	return first.AddDate(0, 0, day-1)
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunAt(t *testing.T) {
+	start := time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name  string
+		unit  string
+		count int32
+		n     int32
+		want  time.Time
+	}{
+		{"First", IntervalMonth, 1, 0, start},
+		{"Days", IntervalDay, 3, 2, time.Date(2024, time.February, 6, 9, 0, 0, 0, time.UTC)},
+		{"Weeks", IntervalWeek, 2, 1, time.Date(2024, time.February, 14, 9, 0, 0, 0, time.UTC)},
+		{"ShortMonth", IntervalMonth, 1, 1, time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC)},
+		{"BackToTheEnd", IntervalMonth, 1, 2, time.Date(2024, time.March, 31, 9, 0, 0, 0, time.UTC)},
+		{"Quarterly", IntervalMonth, 3, 4, time.Date(2025, time.January, 31, 9, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheduled := ScheduledTransfer{
+				IntervalUnit:  tc.unit,
+				IntervalCount: tc.count,
+				StartAt:       start,
+			}
+			require.Equal(t, tc.want, scheduled.RunAt(tc.n))
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomScheduledTransfer(t *testing.T, account1, account2 Account) ScheduledTransfer {
+	endAt := time.Now().AddDate(1, 0, 0)
+	arg := CreateScheduledTransferParams{
+		Owner:         account1.Name,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        util.RandomAmount(),
+		Currency:      account1.Currency,
+		IntervalUnit:  IntervalMonth,
+		IntervalCount: 1,
+		StartAt:       time.Now().Add(time.Hour),
+		EndAt:         &endAt,
+	}
+
+	scheduled, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, scheduled)
+
+	require.Equal(t, arg.Owner, scheduled.Owner)
+	require.Equal(t, arg.FromAccountID, scheduled.FromAccountID)
+	require.Equal(t, arg.ToAccountID, scheduled.ToAccountID)
+	require.Equal(t, arg.Amount, scheduled.Amount)
+	require.Equal(t, arg.IntervalUnit, scheduled.IntervalUnit)
+	require.WithinDuration(t, arg.StartAt, scheduled.StartAt, time.Second)
+	require.WithinDuration(t, arg.StartAt, scheduled.NextRunAt, time.Second)
+	require.WithinDuration(t, endAt, *scheduled.EndAt, time.Second)
+	require.Zero(t, scheduled.Occurrence)
+	require.Equal(t, ScheduledTransferActive, scheduled.Status)
+	require.NotZero(t, scheduled.CreatedAt)
+
+	return scheduled
+}
+
+func TestCreateScheduledTransfer(t *testing.T) {
+	createRandomScheduledTransfer(t, createRandomAccount(t), createRandomAccount(t))
+}
+
+func TestGetScheduledTransfer(t *testing.T) {
+	scheduled1 := createRandomScheduledTransfer(t, createRandomAccount(t), createRandomAccount(t))
+
+	scheduled2, err := testQueries.GetScheduledTransfer(context.Background(), scheduled1.ID)
+	require.NoError(t, err)
+	require.Equal(t, scheduled1.ID, scheduled2.ID)
+	require.Equal(t, scheduled1.Amount, scheduled2.Amount)
+	require.WithinDuration(t, scheduled1.NextRunAt, scheduled2.NextRunAt, time.Second)
+}
+
+func TestListScheduledTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	for i := 0; i < 3; i++ {
+		createRandomScheduledTransfer(t, account1, account2)
+	}
+
+	scheduled, err := testQueries.ListScheduledTransfers(context.Background(), ListScheduledTransfersParams{
+		Owner: account1.Name,
+		Size:  2,
+	})
+	require.NoError(t, err)
+	require.Len(t, scheduled, 2)
+
+	last := scheduled[1]
+	rest, err := testQueries.ListScheduledTransfers(context.Background(), ListScheduledTransfersParams{
+		Owner:          account1.Name,
+		AfterCreatedAt: last.CreatedAt,
+		AfterID:        last.ID,
+		Size:           2,
+	})
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+}
+
+func TestUpdateScheduledTransfer(t *testing.T) {
+	scheduled1 := createRandomScheduledTransfer(t, createRandomAccount(t), createRandomAccount(t))
+
+	scheduled2, err := testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:     scheduled1.ID,
+		Status: sql.NullString{String: ScheduledTransferPaused, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferPaused, scheduled2.Status)
+	require.Equal(t, scheduled1.Amount, scheduled2.Amount)
+	require.WithinDuration(t, *scheduled1.EndAt, *scheduled2.EndAt, time.Second)
+
+	scheduled3, err := testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:     scheduled1.ID,
+		Amount: sql.NullInt64{Int64: scheduled1.Amount + 1, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, scheduled1.Amount+1, scheduled3.Amount)
+	require.Equal(t, ScheduledTransferPaused, scheduled3.Status)
+
+	_, err = testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:     scheduled1.ID,
+		Status: sql.NullString{String: ScheduledTransferCancelled, Valid: true},
+	})
+	require.NoError(t, err)
+
+	// a finished schedule cannot be resumed
+	_, err = testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:     scheduled1.ID,
+		Status: sql.NullString{String: ScheduledTransferActive, Valid: true},
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestListScheduledTransferRuns(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	scheduled := createRandomScheduledTransfer(t, account1, account2)
+	transfer := createRandomTransfer(t, account1, account2)
+
+	succeeded, err := testQueries.CreateScheduledTransferRun(context.Background(), CreateScheduledTransferRunParams{
+		ScheduledTransferID: scheduled.ID,
+		ScheduledFor:        scheduled.NextRunAt,
+		Status:              ScheduledRunSucceeded,
+		TransferID:          &transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, transfer.ID, *succeeded.TransferID)
+
+	failed, err := testQueries.CreateScheduledTransferRun(context.Background(), CreateScheduledTransferRunParams{
+		ScheduledTransferID: scheduled.ID,
+		ScheduledFor:        scheduled.RunAt(1),
+		Status:              ScheduledRunFailed,
+		Error:               ErrInsufficientFunds.Error(),
+	})
+	require.NoError(t, err)
+	require.Nil(t, failed.TransferID)
+
+	runs, err := testQueries.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduled.ID,
+		Size:                5,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	require.Equal(t, succeeded.ID, runs[0].ID)
+	require.Equal(t, failed.ID, runs[1].ID)
+}
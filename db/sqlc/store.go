@@ -3,12 +3,92 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+)
+
+// ErrInsufficientFunds is returned when a transfer would take the source
+// account below its balance plus overdraft limit
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// ErrConversionTooSmall is returned when a currency conversion rounds the
+// amount down to nothing in the receiver's currency
+var ErrConversionTooSmall = errors.New("amount is too small to convert")
+
+// Account statuses, money only moves in and out of active accounts
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+var (
+	ErrAccountFrozen = errors.New("account is frozen")
+	ErrAccountClosed = errors.New("account is closed")
+	// ErrNonZeroBalance is returned when an account is closed with money
+	// left and nowhere to sweep it
+	ErrNonZeroBalance = errors.New("account balance is not zero")
+)
+
+// Transfer statuses, a pending transfer only holds the money until it is
+// captured, voided or the hold expires
+const (
+	TransferStatusPending = "pending"
+	TransferStatusPosted  = "posted"
+	TransferStatusVoided  = "voided"
+	TransferStatusExpired = "expired"
+)
+
+// Hold statuses, only active holds count towards the held balance
+const (
+	HoldStatusActive   = "active"
+	HoldStatusCaptured = "captured"
+	HoldStatusVoided   = "voided"
+	HoldStatusExpired  = "expired"
+)
+
+var (
+	ErrTransferNotPending = errors.New("transfer is not pending")
+	ErrTransferNotPosted  = errors.New("transfer is not posted")
+	ErrHoldExpired        = errors.New("hold has expired")
+	// ErrHeldBalance is returned when an account with pending transfers is
+	// closed
+	ErrHeldBalance = errors.New("account has held funds")
+)
+
+var (
+	// ErrReversalExceedsTransfer is returned when a reversal is larger than
+	// what is left of the transfer after earlier reversals
+	ErrReversalExceedsTransfer = errors.New("reversal exceeds the amount left to reverse")
+	ErrReverseReversal         = errors.New("a reversal cannot be reversed")
+	// ErrReversalTooSmall is returned when a partial reversal of a currency
+	// conversion rounds down to nothing in the receiver's currency
+	ErrReversalTooSmall = errors.New("reversal amount is too small")
 )
 
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	FXTransferTx(ctx context.Context, arg FXTransferTxParams) (TransferTxResult, error)
+	DepositTx(ctx context.Context, arg DepositTxParams) (DepositTxResult, error)
+	WithdrawTx(ctx context.Context, arg WithdrawTxParams) (WithdrawTxResult, error)
+	CloseAccountTx(ctx context.Context, arg CloseAccountTxParams) (CloseAccountTxResult, error)
+	UpdateAccountStatusTx(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateAccountOverdraftLimitTx(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error)
+	StatementTx(ctx context.Context, arg StatementTxParams) (StatementTxResult, error)
+	AdjustEntriesTx(ctx context.Context, accountID int64) (AdjustEntriesTxResult, error)
+	ProcessScheduledTransfersTx(ctx context.Context, arg ProcessScheduledTransfersTxParams) ([]ScheduledTransferRun, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	CaptureTransferTx(ctx context.Context, arg CaptureTransferTxParams) (TransferTxResult, error)
+	VoidTransferTx(ctx context.Context, transferID int64) (VoidTransferTxResult, error)
+	ExpireHoldsTx(ctx context.Context, arg ExpireHoldsTxParams) ([]Hold, error)
+	RelayOutboxTx(ctx context.Context, arg RelayOutboxTxParams) ([]OutboxEvent, error)
+	AuditTx(ctx context.Context, record AuditRecord) error
 }
 
 type SQLStore struct {
@@ -24,16 +104,51 @@ func NewStore(db *sql.DB) Store {
 }
 
 
+// execTx runs fn in a transaction. The events fn raises with publishEvent
+// are appended to the outbox and the changes it records with recordAudit to
+// the audit log in the same transaction before it commits
 func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
 	tx, err := store.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	q := New(tx)
+	otx := &outboxTx{Tx: tx}
+	q := New(otx)
 	err = fn(q)
+	if err == nil {
+		err = writeOutbox(ctx, q, otx.events)
+	}
+	if err == nil {
+		err = writeAuditLog(ctx, q, otx.audits)
+	}
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			LoggerFrom(ctx).Error("cannot roll back transaction", "error", err, "rollback_error", rbErr)
+			return fmt.Errorf("tx error: %v, rb error: %v", err, rbErr)
+		}
+		LoggerFrom(ctx).Debug("transaction rolled back", "error", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		LoggerFrom(ctx).Error("cannot commit transaction", "error", err)
+		return err
+	}
+	return nil
+}
+
+// execReadTx runs fn in a read-only REPEATABLE READ transaction, every query
+// of fn sees the same snapshot without locking the rows it reads
+func (store *SQLStore) execReadTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
 	if err != nil {
+		return err
+	}
+
+	if err := fn(New(tx)); err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
+			LoggerFrom(ctx).Error("cannot roll back transaction", "error", err, "rollback_error", rbErr)
 			return fmt.Errorf("tx error: %v, rb error: %v", err, rbErr)
 		}
 		return err
@@ -42,10 +157,25 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 	return tx.Commit()
 }
 
+// IdempotencyParams identifies a client request that must only be applied once.
+// The transaction result is stored under the key so retries can be replayed.
+type IdempotencyParams struct {
+	Username    string `json:"username"`
+	Key         string `json:"key"`
+	RequestHash string `json:"request_hash"`
+}
+
 type TransferTxParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64              `json:"from_account_id"`
+	ToAccountID   int64              `json:"to_account_id"`
+	Amount        int64              `json:"amount"`
+	Idempotency   *IdempotencyParams `json:"-"`
+	// HoldExpiresAt makes the transfer pending, the money is only held on
+	// the source account until then
+	HoldExpiresAt     *time.Time      `json:"hold_expires_at"`
+	Description       string          `json:"description"`
+	ExternalReference string          `json:"external_reference"`
+	Metadata          json.RawMessage `json:"metadata"`
 }
 
 type TransferTxResult struct {
@@ -54,6 +184,8 @@ type TransferTxResult struct {
 	ToAccount   Account `json:"to_account"`
 	FromEntry   Entry `json:"from_entry"`
 	ToEntry     Entry `json:"to_entry"`
+	// Hold is set for pending transfers, which have no entries yet
+	Hold *Hold `json:"hold,omitempty"`
 }
 
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
@@ -62,54 +194,1136 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
 
+		result, err = transfer(ctx, q, CreateTransferParams{
+			FromAccountID:     arg.FromAccountID,
+			ToAccountID:       arg.ToAccountID,
+			Amount:            arg.Amount,
+			ToAmount:          arg.Amount,
+			ExchangeRate:      "1",
+			Description:       arg.Description,
+			ExternalReference: arg.ExternalReference,
+			Metadata:          arg.Metadata,
+		}, arg.HoldExpiresAt)
+		if err != nil {
+			return err
+		}
+
+		return saveIdempotentResponse(ctx, q, arg.Idempotency, result)
+	})
+
+	return result, err
+}
+
+// FXTransferTxParams moves money between accounts in different currencies,
+// Amount is debited in the source currency and converted with Rate minus
+// the spread before it is credited
+type FXTransferTxParams struct {
+	FromAccountID     int64              `json:"from_account_id"`
+	ToAccountID       int64              `json:"to_account_id"`
+	Amount            int64              `json:"amount"`
+	Rate              string             `json:"rate"`
+	SpreadBps         int32              `json:"spread_bps"`
+	Idempotency       *IdempotencyParams `json:"-"`
+	HoldExpiresAt     *time.Time         `json:"hold_expires_at"`
+	Description       string             `json:"description"`
+	ExternalReference string             `json:"external_reference"`
+	Metadata          json.RawMessage    `json:"metadata"`
+}
+
+func (store *SQLStore) FXTransferTx(ctx context.Context, arg FXTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		fromExponent, err := accountExponent(ctx, q, arg.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		toExponent, err := accountExponent(ctx, q, arg.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		toAmount, err := util.ConvertAmount(arg.Amount, arg.Rate, arg.SpreadBps, fromExponent, toExponent)
+		if err != nil {
+			return err
+		}
+		if toAmount <= 0 {
+			return ErrConversionTooSmall
+		}
+
+		result, err = transfer(ctx, q, CreateTransferParams{
+			FromAccountID:     arg.FromAccountID,
+			ToAccountID:       arg.ToAccountID,
+			Amount:            arg.Amount,
+			ToAmount:          toAmount,
+			ExchangeRate:      arg.Rate,
+			SpreadBps:         arg.SpreadBps,
+			Description:       arg.Description,
+			ExternalReference: arg.ExternalReference,
+			Metadata:          arg.Metadata,
+		}, arg.HoldExpiresAt)
+		if err != nil {
+			return err
+		}
+
+		return saveIdempotentResponse(ctx, q, arg.Idempotency, result)
+	})
+
+	return result, err
+}
+
+// accountExponent returns the number of minor unit digits of the account's currency
+func accountExponent(ctx context.Context, q *Queries, accountID int64) (int32, error) {
+	account, err := q.GetAccount(ctx, accountID)
+	if err != nil {
+		return 0, err
+	}
+
+	currency, err := q.GetCurrency(ctx, account.Currency)
+	if err != nil {
+		return 0, err
+	}
+
+	return currency.Exponent, nil
+}
+
+// transfer debits Amount from the source account and credits ToAmount to
+// the destination account, writing the transfer row and both entries. When
+// holdExpiresAt is set the transfer is left pending instead and Amount is
+// only held on the source account until it is captured
+func transfer(ctx context.Context, q *Queries, arg CreateTransferParams, holdExpiresAt *time.Time) (TransferTxResult, error) {
+	var result TransferTxResult
+	var err error
+
+	// lock both accounts in id order so concurrent transfers cannot deadlock
+	var fromAccount, toAccount Account
+	if arg.FromAccountID < arg.ToAccountID {
+		fromAccount, toAccount, err = lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+	} else {
+		toAccount, fromAccount, err = lockAccounts(ctx, q, arg.ToAccountID, arg.FromAccountID)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if err := checkAccountActive(fromAccount); err != nil {
+		return result, err
+	}
+	if err := checkAccountActive(toAccount); err != nil {
+		return result, err
+	}
+
+	if fromAccount.AvailableBalance+fromAccount.OverdraftLimit < arg.Amount {
+		return result, ErrInsufficientFunds
+	}
+
+	if len(arg.Metadata) == 0 {
+		arg.Metadata = json.RawMessage(`{}`)
+	}
+
+	arg.Status = TransferStatusPosted
+	if holdExpiresAt != nil {
+		arg.Status = TransferStatusPending
+	}
+
+	result.Transfer, err = q.CreateTransfer(ctx, arg)
+	if err != nil {
+		return result, err
+	}
+
+	err = publishEvent(q, Event{
+		Type:          EventTransferCreated,
+		AggregateType: AggregateTransfer,
+		AggregateID:   result.Transfer.ID,
+		Data:          result.Transfer,
+		Owners:        []string{fromAccount.Name, toAccount.Name},
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if holdExpiresAt == nil {
+		result, err = postTransfer(ctx, q, result.Transfer)
+	} else {
+		result, err = holdTransfer(ctx, q, result.Transfer, toAccount, *holdExpiresAt)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	err = recordAudit(q, AuditRecord{
+		Action:     AuditTransferCreate,
+		TargetType: AuditTargetTransfer,
+		TargetIDs:  []int64{result.Transfer.ID},
+		Before:     transferAccounts{FromAccount: fromAccount, ToAccount: toAccount},
+		After:      result,
+	})
+	return result, err
+}
+
+// transferAccounts is the audit snapshot of both accounts of a transfer
+type transferAccounts struct {
+	FromAccount Account `json:"from_account"`
+	ToAccount   Account `json:"to_account"`
+}
+
+// holdTransfer holds the amount of a pending transfer on the source account
+// until expiresAt, the accounts must already be locked
+func holdTransfer(ctx context.Context, q *Queries, transfer Transfer, toAccount Account, expiresAt time.Time) (TransferTxResult, error) {
+	result := TransferTxResult{Transfer: transfer, ToAccount: toAccount}
+
+	hold, err := q.CreateHold(ctx, CreateHoldParams{
+		TransferID: transfer.ID,
+		AccountID:  transfer.FromAccountID,
+		Amount:     transfer.Amount,
+		ExpiresAt:  expiresAt,
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Hold = &hold
+
+	result.FromAccount, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+		ID:     transfer.FromAccountID,
+		Amount: transfer.Amount,
+	})
+	return result, err
+}
+
+// postTransfer writes both entries of a transfer and moves the money, the
+// accounts must already be locked
+func postTransfer(ctx context.Context, q *Queries, transfer Transfer) (TransferTxResult, error) {
+	result := TransferTxResult{Transfer: transfer}
+	var err error
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:   transfer.FromAccountID,
+		Amount:      -transfer.Amount,
+		TransferID:  &transfer.ID,
+		Description: transfer.Description,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:   transfer.ToAccountID,
+		Amount:      transfer.ToAmount,
+		TransferID:  &transfer.ID,
+		Description: transfer.Description,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if transfer.FromAccountID < transfer.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(ctx, q, transfer.FromAccountID, -transfer.Amount, transfer.ToAccountID, transfer.ToAmount)
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(ctx, q, transfer.ToAccountID, transfer.ToAmount, transfer.FromAccountID, -transfer.Amount)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	err = publishBalanceChanged(q, result.FromAccount, -transfer.Amount, &transfer.ID)
+	if err != nil {
+		return result, err
+	}
+
+	err = publishBalanceChanged(q, result.ToAccount, transfer.ToAmount, &transfer.ID)
+	return result, err
+}
+
+type DepositTxParams struct {
+	AccountID   int64              `json:"account_id"`
+	Amount      int64              `json:"amount"`
+	Idempotency *IdempotencyParams `json:"-"`
+}
+
+type DepositTxResult struct {
+	Account Account `json:"account"`
+	Entry   Entry   `json:"entry"`
+}
+
+func (store *SQLStore) DepositTx(ctx context.Context, arg DepositTxParams) (DepositTxResult, error) {
+	var result DepositTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
 
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
+		if err := checkAccountActive(account); err != nil {
+			return err
+		}
+
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: arg.AccountID,
+			Amount:    arg.Amount,
 		})
 		if err != nil {
 			return err
 		}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
+		result.Account, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     arg.AccountID,
+			Amount: arg.Amount,
 		})
+		if err != nil {
+			return err
+		}
 
+		err = publishBalanceChanged(q, result.Account, arg.Amount, nil)
 		if err != nil {
 			return err
 		}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+		err = recordAudit(q, AuditRecord{
+			Action:     AuditAccountDeposit,
+			TargetType: AuditTargetAccount,
+			TargetIDs:  []int64{arg.AccountID},
+			Before:     account,
+			After:      result,
 		})
+		if err != nil {
+			return err
+		}
 
+		return saveIdempotentResponse(ctx, q, arg.Idempotency, result)
+	})
+
+	return result, err
+}
+
+type WithdrawTxParams struct {
+	AccountID   int64              `json:"account_id"`
+	Amount      int64              `json:"amount"`
+	Idempotency *IdempotencyParams `json:"-"`
+}
+
+type WithdrawTxResult struct {
+	Account Account `json:"account"`
+	Entry   Entry   `json:"entry"`
+}
+
+func (store *SQLStore) WithdrawTx(ctx context.Context, arg WithdrawTxParams) (WithdrawTxResult, error) {
+	var result WithdrawTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
 		if err != nil {
 			return err
 		}
 
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
+		if err := checkAccountActive(account); err != nil {
+			return err
+		}
 
-			if err != nil {
-				return err
-			}
+		if account.AvailableBalance+account.OverdraftLimit < arg.Amount {
+			return ErrInsufficientFunds
+		}
+
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: arg.AccountID,
+			Amount:    -arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     arg.AccountID,
+			Amount: -arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = publishBalanceChanged(q, result.Account, -arg.Amount, nil)
+		if err != nil {
+			return err
+		}
+
+		err = recordAudit(q, AuditRecord{
+			Action:     AuditAccountWithdraw,
+			TargetType: AuditTargetAccount,
+			TargetIDs:  []int64{arg.AccountID},
+			Before:     account,
+			After:      result,
+		})
+		if err != nil {
+			return err
+		}
+
+		return saveIdempotentResponse(ctx, q, arg.Idempotency, result)
+	})
+
+	return result, err
+}
+
+type CloseAccountTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// SweepToAccountID receives the remaining balance, zero means the
+	// balance must already be zero
+	SweepToAccountID int64 `json:"sweep_to_account_id"`
+}
+
+type CloseAccountTxResult struct {
+	Account Account           `json:"account"`
+	Sweep   *TransferTxResult `json:"sweep,omitempty"`
+}
+
+// CloseAccountTx closes an active account for good. A positive balance is
+// first moved to SweepToAccountID with a regular transfer, a negative one
+// has to be paid back before the account can be closed
+func (store *SQLStore) CloseAccountTx(ctx context.Context, arg CloseAccountTxParams) (CloseAccountTxResult, error) {
+	var result CloseAccountTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var account, sweepAccount Account
+		var err error
+		if arg.SweepToAccountID == 0 {
+			account, err = q.GetAccountForUpdate(ctx, arg.AccountID)
+		} else if arg.AccountID < arg.SweepToAccountID {
+			account, sweepAccount, err = lockAccounts(ctx, q, arg.AccountID, arg.SweepToAccountID)
 		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
+			sweepAccount, account, err = lockAccounts(ctx, q, arg.SweepToAccountID, arg.AccountID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := checkAccountActive(account); err != nil {
+			return err
+		}
+
+		if arg.SweepToAccountID != 0 && sweepAccount.Currency != account.Currency {
+			return fmt.Errorf("account %d has different currency", arg.SweepToAccountID)
+		}
+
+		if account.HeldBalance != 0 {
+			return ErrHeldBalance
+		}
+
+		if account.Balance < 0 || (account.Balance > 0 && arg.SweepToAccountID == 0) {
+			return ErrNonZeroBalance
+		}
 
+		if account.Balance > 0 {
+			sweep, err := transfer(ctx, q, CreateTransferParams{
+				FromAccountID: arg.AccountID,
+				ToAccountID:   arg.SweepToAccountID,
+				Amount:        account.Balance,
+				ToAmount:      account.Balance,
+				ExchangeRate:  "1",
+			}, nil)
 			if err != nil {
 				return err
 			}
+			result.Sweep = &sweep
 		}
-		
-		return nil
+
+		result.Account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			ID:     arg.AccountID,
+			Status: AccountStatusClosed,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(q, AuditRecord{
+			Action:     AuditAccountClose,
+			TargetType: AuditTargetAccount,
+			TargetIDs:  []int64{arg.AccountID},
+			Before:     account,
+			After:      result.Account,
+		})
 	})
 
 	return result, err
 }
 
+// UpdateAccountStatusTx moves an account to another status, freezing it
+// raises an account.frozen event. Closed accounts stay closed, it fails with
+// ErrAccountClosed
+func (store *SQLStore) UpdateAccountStatusTx(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetAccountForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		// the caller checked a snapshot, the account may have been closed since
+		if before.Status == AccountStatusClosed {
+			return ErrAccountClosed
+		}
+
+		account, err = q.UpdateAccountStatus(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		err = recordAudit(q, AuditRecord{
+			Action:     AuditAccountStatus,
+			TargetType: AuditTargetAccount,
+			TargetIDs:  []int64{arg.ID},
+			Before:     before,
+			After:      account,
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.Status != AccountStatusFrozen {
+			return nil
+		}
+
+		return publishEvent(q, Event{
+			Type:          EventAccountFrozen,
+			AggregateType: AggregateAccount,
+			AggregateID:   account.ID,
+			Data:          account,
+			Owners:        []string{account.Name},
+		})
+	})
+
+	return account, err
+}
+
+// UpdateAccountOverdraftLimitTx sets how far below zero the available
+// balance of an account may go. Closed accounts keep their limit, it fails
+// with ErrAccountClosed
+func (store *SQLStore) UpdateAccountOverdraftLimitTx(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetAccountForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if before.Status == AccountStatusClosed {
+			return ErrAccountClosed
+		}
+
+		account, err = q.UpdateAccountOverdraftLimit(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(q, AuditRecord{
+			Action:     AuditAccountOverdraft,
+			TargetType: AuditTargetAccount,
+			TargetIDs:  []int64{arg.ID},
+			Before:     before,
+			After:      account,
+		})
+	})
+
+	return account, err
+}
+
+type StatementTxParams struct {
+	AccountID int64     `json:"account_id"`
+	FromTime  time.Time `json:"from_time"`
+	ToTime    time.Time `json:"to_time"`
+}
+
+type StatementTxResult struct {
+	Account        Account `json:"account"`
+	OpeningBalance int64   `json:"opening_balance"`
+	Entries        []Entry `json:"entries"`
+}
+
+// StatementTx reads the entries of an account between FromTime (inclusive)
+// and ToTime (exclusive). The opening balance is worked back from the current
+// balance so it also counts money that was never written as an entry. The
+// reads share one snapshot, a transfer that lands meanwhile is in neither of
+// them and the account is never locked
+func (store *SQLStore) StatementTx(ctx context.Context, arg StatementTxParams) (StatementTxResult, error) {
+	var result StatementTxResult
+
+	err := store.execReadTx(ctx, func(q *Queries) error {
+		var err error
+		result.Account, err = q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		since, err := q.SumEntriesSince(ctx, SumEntriesSinceParams{
+			AccountID: arg.AccountID,
+			Since:     arg.FromTime,
+		})
+		if err != nil {
+			return err
+		}
+		result.OpeningBalance = result.Account.Balance - since
+
+		result.Entries, err = q.GetEntriesBetween(ctx, GetEntriesBetweenParams{
+			AccountID: arg.AccountID,
+			FromTime:  arg.FromTime,
+			ToTime:    arg.ToTime,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+type AdjustEntriesTxResult struct {
+	Account Account `json:"account"`
+	// Delta is the balance minus the sum of the entries before the adjustment
+	Delta int64  `json:"delta"`
+	Entry *Entry `json:"entry,omitempty"`
+}
+
+// AdjustEntriesTx writes an entry for the difference between the balance of
+// an account and the sum of its entries so the ledger adds up again. The
+// balance is trusted and left untouched, the difference is worked out again
+// under the account lock so a transfer that landed after the caller looked
+// is not counted twice. Entry is nil when there was nothing to adjust
+func (store *SQLStore) AdjustEntriesTx(ctx context.Context, accountID int64) (AdjustEntriesTxResult, error) {
+	var result AdjustEntriesTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Account, err = q.GetAccountForUpdate(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		total, err := q.SumEntries(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		result.Delta = result.Account.Balance - total
+		if result.Delta == 0 {
+			return nil
+		}
+
+		entry, err := q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: accountID,
+			Amount:    result.Delta,
+		})
+		if err != nil {
+			return err
+		}
+		result.Entry = &entry
+
+		return recordAudit(q, AuditRecord{
+			Action:     AuditAccountAdjust,
+			TargetType: AuditTargetAccount,
+			TargetIDs:  []int64{accountID},
+			Before:     result.Account,
+			After:      result,
+		})
+	})
+
+	return result, err
+}
+
+type ProcessScheduledTransfersTxParams struct {
+	Now  time.Time `json:"now"`
+	Size int32     `json:"size"`
+	// Execute makes the transfer for one due run, it runs outside of the
+	// claiming transaction and must store the transfer under idempotency
+	Execute func(ctx context.Context, scheduled ScheduledTransfer, idempotency *IdempotencyParams) (TransferTxResult, error) `json:"-"`
+}
+
+// ProcessScheduledTransfersTx claims up to Size due scheduled transfers with
+// FOR UPDATE SKIP LOCKED so concurrent workers never pick the same one, calls
+// Execute for each, records the run and moves next_run_at forward. A failed
+// transfer is recorded and the schedule still moves on. Runs missed while no
+// worker was running are skipped, only the latest one is executed, and a run
+// due after end_at completes the schedule without a transfer.
+//
+// Execute commits on its own, so when recording the run fails the money has
+// already moved. The transfer is stored under the idempotency key of the
+// run, and the retry replays it instead of calling Execute again
+func (store *SQLStore) ProcessScheduledTransfersTx(ctx context.Context, arg ProcessScheduledTransfersTxParams) ([]ScheduledTransferRun, error) {
+	runs := []ScheduledTransferRun{}
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		due, err := q.ClaimDueScheduledTransfers(ctx, ClaimDueScheduledTransfersParams{
+			Now:  arg.Now,
+			Size: arg.Size,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, scheduled := range due {
+			// end_at can be moved before the due run after it was scheduled
+			if scheduled.EndAt != nil && scheduled.NextRunAt.After(*scheduled.EndAt) {
+				_, err = q.AdvanceScheduledTransfer(ctx, AdvanceScheduledTransferParams{
+					ID:         scheduled.ID,
+					NextRunAt:  scheduled.NextRunAt,
+					Occurrence: scheduled.Occurrence,
+					Status:     ScheduledTransferCompleted,
+				})
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			run := CreateScheduledTransferRunParams{
+				ScheduledTransferID: scheduled.ID,
+				ScheduledFor:        scheduled.NextRunAt,
+				Status:              ScheduledRunSucceeded,
+			}
+
+			var result TransferTxResult
+			idempotency := scheduled.Idempotency()
+			replayed, err := replayIdempotentResponse(ctx, q, idempotency, &result)
+			if err != nil {
+				return err
+			}
+			if !replayed {
+				result, err = arg.Execute(ctx, scheduled, idempotency)
+			}
+			if err != nil {
+				run.Status = ScheduledRunFailed
+				run.Error = err.Error()
+			} else {
+				run.TransferID = &result.Transfer.ID
+			}
+
+			saved, err := q.CreateScheduledTransferRun(ctx, run)
+			if err != nil {
+				return err
+			}
+			runs = append(runs, saved)
+
+			next := scheduled.Occurrence + 1
+			for !scheduled.RunAt(next).After(arg.Now) {
+				next++
+			}
+
+			status := ScheduledTransferActive
+			nextRunAt := scheduled.RunAt(next)
+			if scheduled.EndAt != nil && nextRunAt.After(*scheduled.EndAt) {
+				status = ScheduledTransferCompleted
+			}
+
+			_, err = q.AdvanceScheduledTransfer(ctx, AdvanceScheduledTransferParams{
+				ID:         scheduled.ID,
+				NextRunAt:  nextRunAt,
+				Occurrence: next,
+				Status:     status,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return runs, err
+}
+
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount is refunded to the sender in the currency of the original
+	// transfer, zero reverses everything that is left
+	Amount int64 `json:"amount"`
+}
+
+type ReverseTransferTxResult struct {
+	TransferTxResult
+	Original Transfer `json:"original"`
+}
+
+// ReverseTransferTx moves money back from the receiver to the sender of a
+// transfer and links the new transfer to it. The original is locked so
+// concurrent reversals cannot together refund more than it moved. The
+// receiver is debited its share of the original credit, worked out on the
+// running total so a transfer reversed in parts debits exactly what it
+// credited
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Original, err = q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+		original := result.Original
+
+		if original.ReversalOf != nil {
+			return ErrReverseReversal
+		}
+		if original.Status != TransferStatusPosted {
+			return ErrTransferNotPosted
+		}
+
+		reversed, err := q.SumTransferReversals(ctx, &original.ID)
+		if err != nil {
+			return err
+		}
+
+		remaining := original.Amount - reversed
+		amount := arg.Amount
+		if amount == 0 {
+			amount = remaining
+		}
+		if remaining <= 0 || amount > remaining {
+			return ErrReversalExceedsTransfer
+		}
+
+		debit := share(original.ToAmount, reversed+amount, original.Amount) - share(original.ToAmount, reversed, original.Amount)
+		if debit <= 0 {
+			return ErrReversalTooSmall
+		}
+
+		rate, err := util.InverseRate(original.ExchangeRate)
+		if err != nil {
+			return err
+		}
+
+		result.TransferTxResult, err = transfer(ctx, q, CreateTransferParams{
+			FromAccountID: original.ToAccountID,
+			ToAccountID:   original.FromAccountID,
+			Amount:        debit,
+			ToAmount:      amount,
+			ExchangeRate:  rate,
+			ReversalOf:    &original.ID,
+		}, nil)
+		return err
+	})
+
+	return result, err
+}
+
+// share returns total * part / whole rounded down, without overflowing
+func share(total, part, whole int64) int64 {
+	n := new(big.Int).Mul(big.NewInt(total), big.NewInt(part))
+	return n.Quo(n, big.NewInt(whole)).Int64()
+}
+
+type CaptureTransferTxParams struct {
+	TransferID int64     `json:"transfer_id"`
+	Now        time.Time `json:"now"`
+}
+
+// CaptureTransferTx posts a pending transfer, the hold is released and the
+// money it reserved moves to the receiver. The hold is locked first like in
+// ExpireHoldsTx so a capture and an expiry cannot both release it
+func (store *SQLStore) CaptureTransferTx(ctx context.Context, arg CaptureTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := activeHold(ctx, q, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		if !hold.ExpiresAt.After(arg.Now) {
+			return ErrHoldExpired
+		}
+
+		pending, err := q.GetTransfer(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		var fromAccount, toAccount Account
+		if pending.FromAccountID < pending.ToAccountID {
+			fromAccount, toAccount, err = lockAccounts(ctx, q, pending.FromAccountID, pending.ToAccountID)
+		} else {
+			toAccount, fromAccount, err = lockAccounts(ctx, q, pending.ToAccountID, pending.FromAccountID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := checkAccountActive(fromAccount); err != nil {
+			return err
+		}
+		if err := checkAccountActive(toAccount); err != nil {
+			return err
+		}
+
+		_, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			ID:     hold.AccountID,
+			Amount: -hold.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		hold, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+			ID:     hold.ID,
+			Status: HoldStatusCaptured,
+		})
+		if err != nil {
+			return err
+		}
+
+		posted, err := q.UpdateTransferStatus(ctx, UpdateTransferStatusParams{
+			ID:     pending.ID,
+			Status: TransferStatusPosted,
+		})
+		if err != nil {
+			return err
+		}
+
+		result, err = postTransfer(ctx, q, posted)
+		result.Hold = &hold
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(q, AuditRecord{
+			Action:     AuditTransferCapture,
+			TargetType: AuditTargetTransfer,
+			TargetIDs:  []int64{pending.ID},
+			Before:     transferAccounts{FromAccount: fromAccount, ToAccount: toAccount},
+			After:      result,
+		})
+	})
+
+	return result, err
+}
+
+type VoidTransferTxResult struct {
+	Transfer Transfer `json:"transfer"`
+	Account  Account  `json:"account"`
+	Hold     Hold     `json:"hold"`
+}
+
+// VoidTransferTx cancels a pending transfer and gives the held money back
+// to the source account
+func (store *SQLStore) VoidTransferTx(ctx context.Context, transferID int64) (VoidTransferTxResult, error) {
+	var result VoidTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := activeHold(ctx, q, transferID)
+		if err != nil {
+			return err
+		}
+
+		result, err = releaseHold(ctx, q, hold, HoldStatusVoided, TransferStatusVoided)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(q, AuditRecord{
+			Action:     AuditTransferVoid,
+			TargetType: AuditTargetTransfer,
+			TargetIDs:  []int64{transferID},
+			Before:     hold,
+			After:      result,
+		})
+	})
+
+	return result, err
+}
+
+type ExpireHoldsTxParams struct {
+	Now  time.Time `json:"now"`
+	Size int32     `json:"size"`
+}
+
+// ExpireHoldsTx releases up to Size holds that expired by Now, claimed with
+// FOR UPDATE SKIP LOCKED so concurrent workers never pick the same one
+func (store *SQLStore) ExpireHoldsTx(ctx context.Context, arg ExpireHoldsTxParams) ([]Hold, error) {
+	expired := []Hold{}
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		holds, err := q.ClaimExpiredHolds(ctx, ClaimExpiredHoldsParams{
+			Now:  arg.Now,
+			Size: arg.Size,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, hold := range holds {
+			result, err := releaseHold(ctx, q, hold, HoldStatusExpired, TransferStatusExpired)
+			if err != nil {
+				return err
+			}
+			expired = append(expired, result.Hold)
+
+			err = recordAudit(q, AuditRecord{
+				Action:     AuditHoldExpire,
+				TargetType: AuditTargetTransfer,
+				TargetIDs:  []int64{hold.TransferID},
+				Before:     hold,
+				After:      result,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return expired, err
+}
+
+type RelayOutboxTxParams struct {
+	Now  time.Time `json:"now"`
+	Size int32     `json:"size"`
+	// Publish sends the claimed events on, they are only marked sent when
+	// it succeeds
+	Publish func(ctx context.Context, events []OutboxEvent) error `json:"-"`
+}
+
+// RelayOutboxTx claims up to Size unsent outbox events oldest first with
+// FOR UPDATE SKIP LOCKED so concurrent relays never publish the same one,
+// calls Publish and marks them sent at Now. A failed Publish rolls back and
+// the events are claimed again by the next run, so events are published at
+// least once
+func (store *SQLStore) RelayOutboxTx(ctx context.Context, arg RelayOutboxTxParams) ([]OutboxEvent, error) {
+	var events []OutboxEvent
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		events, err = q.ClaimUnsentOutboxEvents(ctx, arg.Size)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		if err := arg.Publish(ctx, events); err != nil {
+			return err
+		}
+
+		ids := make([]int64, len(events))
+		for i := range events {
+			ids[i] = events[i].ID
+			events[i].SentAt = &arg.Now
+		}
+
+		return q.MarkOutboxEventsSent(ctx, MarkOutboxEventsSentParams{
+			SentAt: &arg.Now,
+			Ids:    ids,
+		})
+	})
+	if err != nil {
+		return []OutboxEvent{}, err
+	}
+
+	return events, nil
+}
+
+// activeHold locks the hold of a pending transfer, a transfer without an
+// active hold is not pending
+func activeHold(ctx context.Context, q *Queries, transferID int64) (Hold, error) {
+	hold, err := q.GetHoldByTransferForUpdate(ctx, transferID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return hold, ErrTransferNotPending
+		}
+		return hold, err
+	}
+
+	if hold.Status != HoldStatusActive {
+		return hold, ErrTransferNotPending
+	}
+
+	return hold, nil
+}
+
+// releaseHold gives the held money back to the account and closes the hold
+// and its transfer with the given statuses
+func releaseHold(ctx context.Context, q *Queries, hold Hold, holdStatus, transferStatus string) (VoidTransferTxResult, error) {
+	var result VoidTransferTxResult
+	var err error
+
+	result.Account, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+		ID:     hold.AccountID,
+		Amount: -hold.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Hold, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+		ID:     hold.ID,
+		Status: holdStatus,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Transfer, err = q.UpdateTransferStatus(ctx, UpdateTransferStatusParams{
+		ID:     hold.TransferID,
+		Status: transferStatus,
+	})
+	return result, err
+}
+
+// replayIdempotentResponse loads the response stored under the idempotency
+// key into response, it returns false when no response was stored yet
+func replayIdempotentResponse(ctx context.Context, q *Queries, arg *IdempotencyParams, response interface{}) (bool, error) {
+	stored, err := q.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		Username: arg.Username,
+		Key:      arg.Key,
+	})
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, json.Unmarshal(stored.ResponseBody, response)
+}
+
+// saveIdempotentResponse stores the response of a transaction under its
+// idempotency key, it does nothing when the request carried no key
+func saveIdempotentResponse(ctx context.Context, q *Queries, arg *IdempotencyParams, response interface{}) error {
+	if arg == nil {
+		return nil
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Username:     arg.Username,
+		Key:          arg.Key,
+		RequestHash:  arg.RequestHash,
+		ResponseBody: body,
+	})
+	return err
+}
+
+// checkAccountActive returns the error matching the account's status when
+// money cannot move in or out of it
+func checkAccountActive(account Account) error {
+	switch account.Status {
+	case AccountStatusFrozen:
+		return ErrAccountFrozen
+	case AccountStatusClosed:
+		return ErrAccountClosed
+	default:
+		return nil
+	}
+}
+
+func lockAccounts(ctx context.Context, q *Queries, accountId1 int64, accountId2 int64) (account1 Account, account2 Account, err error) {
+	account1, err = q.GetAccountForUpdate(ctx, accountId1)
+	if err != nil {
+		return
+	}
+
+	account2, err = q.GetAccountForUpdate(ctx, accountId2)
+	return
+}
+
 func addMoney(ctx context.Context, q *Queries, accountId1 int64, amount1 int64, accountId2 int64, amount2 int64) (account1 Account, account2 Account, err error) {
 	account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
 		ID: accountId1,
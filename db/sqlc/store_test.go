@@ -2,8 +2,13 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
 	"github.com/stretchr/testify/require"
 )
 
@@ -56,6 +61,7 @@ func TestTransferTx(t *testing.T) {
 		require.NotEmpty(t, fromEntry)
 		require.Equal(t, account1.ID, fromEntry.AccountID)
 		require.Equal(t, -amount, fromEntry.Amount)
+		require.Equal(t, transfer.ID, *fromEntry.TransferID)
 		require.NotZero(t, fromEntry.ID)
 		require.NotZero(t, fromEntry.CreatedAt)
 
@@ -66,6 +72,7 @@ func TestTransferTx(t *testing.T) {
 		require.NotEmpty(t, toEntry)
 		require.Equal(t, account2.ID, toEntry.AccountID)
 		require.Equal(t, amount, toEntry.Amount)
+		require.Equal(t, transfer.ID, *toEntry.TransferID)
 		require.NotZero(t, toEntry.ID)
 		require.NotZero(t, toEntry.CreatedAt)
 
@@ -103,6 +110,49 @@ func TestTransferTx(t *testing.T) {
 	require.Equal(t, account2.Balance+int64(n)*amount, updateAccount2.Balance)
 }
 
+func TestTransferTxDetails(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	reference := fmt.Sprintf("INV-%d", account1.ID)
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID:     account1.ID,
+		ToAccountID:       account2.ID,
+		Amount:            10,
+		Description:       "rent march",
+		ExternalReference: reference,
+		Metadata:          json.RawMessage(`{"invoice": 1}`),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "rent march", result.Transfer.Description)
+	require.Equal(t, reference, result.Transfer.ExternalReference)
+	require.JSONEq(t, `{"invoice": 1}`, string(result.Transfer.Metadata))
+	require.Equal(t, "rent march", result.FromEntry.Description)
+	require.Equal(t, "rent march", result.ToEntry.Description)
+
+	transfers, err := store.ListTransfers(context.Background(), ListTransfersParams{
+		AccountID: account1.ID,
+		Reference: sql.NullString{String: reference, Valid: true},
+		Size:      10,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, result.Transfer.ID, transfers[0].ID)
+
+	// transfers without metadata store an empty object
+	result, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{}`, string(result.Transfer.Metadata))
+	require.Empty(t, result.FromEntry.Description)
+}
+
 func TestTransferTxDeadlock(t *testing.T) {
 	store := NewStore(testDB)
 
@@ -148,4 +198,845 @@ func TestTransferTxDeadlock(t *testing.T) {
 
 	require.Equal(t, account1.Balance, updateAccount1.Balance)
 	require.Equal(t, account2.Balance, updateAccount2.Balance)
-}
\ No newline at end of file
+}
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// nothing from the rejected transfer is persisted
+	updateAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updateAccount1.Balance)
+
+	updateAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updateAccount2.Balance)
+}
+
+func TestTransferTxOverdraft(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	overdraftLimit := int64(100)
+	account1, err := testQueries.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: overdraftLimit,
+	})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + overdraftLimit,
+	})
+	require.NoError(t, err)
+	require.Equal(t, -overdraftLimit, result.FromAccount.Balance)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestDepositTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	amount := int64(10)
+
+	result, err := store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account.ID, result.Account.ID)
+	require.Equal(t, account.Balance+amount, result.Account.Balance)
+
+	require.NotZero(t, result.Entry.ID)
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.Equal(t, amount, result.Entry.Amount)
+
+	_, err = store.GetEntry(context.Background(), result.Entry.ID)
+	require.NoError(t, err)
+}
+
+func TestWithdrawTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	amount := int64(10)
+
+	result, err := store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account.ID, result.Account.ID)
+	require.Equal(t, account.Balance-amount, result.Account.Balance)
+
+	require.NotZero(t, result.Entry.ID)
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.Equal(t, -amount, result.Entry.Amount)
+
+	_, err = store.GetEntry(context.Background(), result.Entry.ID)
+	require.NoError(t, err)
+}
+
+func TestWithdrawTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+
+	_, err := store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID: account.ID,
+		Amount:    account.Balance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, updatedAccount.Balance)
+}
+
+func TestFXTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	arg := FXTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        50,
+		Rate:          "1.5",
+		SpreadBps:     200,
+	}
+
+	result, err := store.FXTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// 50 * 1.5 = 75, minus a 2% spread rounded down
+	toAmount := int64(73)
+
+	require.Equal(t, arg.Amount, result.Transfer.Amount)
+	require.Equal(t, toAmount, result.Transfer.ToAmount)
+	require.Equal(t, "1.50000000", result.Transfer.ExchangeRate)
+	require.Equal(t, arg.SpreadBps, result.Transfer.SpreadBps)
+
+	require.Equal(t, -arg.Amount, result.FromEntry.Amount)
+	require.Equal(t, toAmount, result.ToEntry.Amount)
+
+	require.Equal(t, account1.Balance-arg.Amount, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+toAmount, result.ToAccount.Balance)
+}
+
+func TestFXTransferTxTooSmall(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	fromAccount, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Name:     user.Username,
+		Balance:  100,
+		Currency: util.JPY,
+	})
+	require.NoError(t, err)
+
+	toAccount := createRandomAccount(t)
+	require.NotEqual(t, util.JPY, toAccount.Currency)
+
+	// 1 JPY is less than a cent
+	_, err = store.FXTransferTx(context.Background(), FXTransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        1,
+		Rate:          "0.00666667",
+	})
+	require.ErrorIs(t, err, ErrConversionTooSmall)
+
+	account, err := testQueries.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance, account.Balance)
+}
+
+func TestReverseTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	partial, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     30,
+	})
+	require.NoError(t, err)
+	require.Equal(t, original.Transfer.ID, partial.Original.ID)
+	require.Equal(t, &original.Transfer.ID, partial.Transfer.ReversalOf)
+	require.Equal(t, account2.ID, partial.Transfer.FromAccountID)
+	require.Equal(t, account1.ID, partial.Transfer.ToAccountID)
+	require.Equal(t, int64(30), partial.Transfer.Amount)
+	require.Equal(t, int64(-30), partial.FromEntry.Amount)
+	require.Equal(t, int64(30), partial.ToEntry.Amount)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     71,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	// no amount reverses what is left
+	rest, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(70), rest.Transfer.Amount)
+	require.Equal(t, account1.Balance, rest.ToAccount.Balance)
+	require.Equal(t, account2.Balance, rest.FromAccount.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: rest.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrReverseReversal)
+
+	reversals, err := testQueries.ListTransferReversals(context.Background(), &original.Transfer.ID)
+	require.NoError(t, err)
+	require.Len(t, reversals, 2)
+	require.Equal(t, partial.Transfer.ID, reversals[0].ID)
+	require.Equal(t, rest.Transfer.ID, reversals[1].ID)
+}
+
+func TestReverseFXTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// 50 is converted to 73, see TestFXTransferTx
+	original, err := store.FXTransferTx(context.Background(), FXTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        50,
+		Rate:          "1.5",
+		SpreadBps:     200,
+	})
+	require.NoError(t, err)
+
+	half, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     25,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(36), half.Transfer.Amount)
+	require.Equal(t, int64(25), half.Transfer.ToAmount)
+	require.Equal(t, "0.66666667", half.Transfer.ExchangeRate)
+
+	// the last part debits the rounding left over so the receiver gives back
+	// exactly what it was credited
+	rest, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(37), rest.Transfer.Amount)
+	require.Equal(t, int64(25), rest.Transfer.ToAmount)
+
+	require.Equal(t, account1.Balance, rest.ToAccount.Balance)
+	require.Equal(t, account2.Balance, rest.FromAccount.Balance)
+}
+
+func TestPendingTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	expiresAt := time.Now().Add(time.Hour)
+	pending, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		HoldExpiresAt: &expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusPending, pending.Transfer.Status)
+	require.NotNil(t, pending.Hold)
+	require.Equal(t, HoldStatusActive, pending.Hold.Status)
+	require.Zero(t, pending.FromEntry.ID)
+
+	// the money is held, not moved
+	require.Equal(t, account1.Balance, pending.FromAccount.Balance)
+	require.Equal(t, int64(10), pending.FromAccount.HeldBalance)
+	require.Equal(t, account1.Balance-10, pending.FromAccount.AvailableBalance)
+	require.Equal(t, account2.Balance, pending.ToAccount.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: pending.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferNotPosted)
+
+	_, err = store.CaptureTransferTx(context.Background(), CaptureTransferTxParams{
+		TransferID: pending.Transfer.ID,
+		Now:        expiresAt,
+	})
+	require.ErrorIs(t, err, ErrHoldExpired)
+
+	captured, err := store.CaptureTransferTx(context.Background(), CaptureTransferTxParams{
+		TransferID: pending.Transfer.ID,
+		Now:        time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusPosted, captured.Transfer.Status)
+	require.Equal(t, HoldStatusCaptured, captured.Hold.Status)
+	require.Equal(t, int64(-10), captured.FromEntry.Amount)
+	require.Equal(t, int64(10), captured.ToEntry.Amount)
+	require.Equal(t, account1.Balance-10, captured.FromAccount.Balance)
+	require.Zero(t, captured.FromAccount.HeldBalance)
+	require.Equal(t, account2.Balance+10, captured.ToAccount.Balance)
+
+	_, err = store.VoidTransferTx(context.Background(), pending.Transfer.ID)
+	require.ErrorIs(t, err, ErrTransferNotPending)
+}
+
+func TestPendingTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	expiresAt := time.Now().Add(time.Hour)
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance,
+		HoldExpiresAt: &expiresAt,
+	})
+	require.NoError(t, err)
+
+	// the held money is not available to anything else
+	_, err = store.WithdrawTx(context.Background(), WithdrawTxParams{AccountID: account1.ID, Amount: 1})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.CloseAccountTx(context.Background(), CloseAccountTxParams{
+		AccountID:        account1.ID,
+		SweepToAccountID: account2.ID,
+	})
+	require.ErrorIs(t, err, ErrHeldBalance)
+}
+
+func TestVoidTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	expiresAt := time.Now().Add(time.Hour)
+	pending, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		HoldExpiresAt: &expiresAt,
+	})
+	require.NoError(t, err)
+
+	voided, err := store.VoidTransferTx(context.Background(), pending.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusVoided, voided.Transfer.Status)
+	require.Equal(t, HoldStatusVoided, voided.Hold.Status)
+	require.Equal(t, account1.Balance, voided.Account.Balance)
+	require.Equal(t, account1.Balance, voided.Account.AvailableBalance)
+
+	_, err = store.CaptureTransferTx(context.Background(), CaptureTransferTxParams{
+		TransferID: pending.Transfer.ID,
+		Now:        time.Now(),
+	})
+	require.ErrorIs(t, err, ErrTransferNotPending)
+
+	posted := createRandomTransfer(t, account1, account2)
+	_, err = store.VoidTransferTx(context.Background(), posted.ID)
+	require.ErrorIs(t, err, ErrTransferNotPending)
+}
+
+func TestExpireHoldsTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	expiresAt := time.Now().Add(time.Hour)
+	pending, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		HoldExpiresAt: &expiresAt,
+	})
+	require.NoError(t, err)
+
+	// holds of other tests may expire in the same call
+	expired, err := store.ExpireHoldsTx(context.Background(), ExpireHoldsTxParams{
+		Now:  expiresAt,
+		Size: 1000,
+	})
+	require.NoError(t, err)
+
+	var found bool
+	for _, hold := range expired {
+		if hold.ID == pending.Hold.ID {
+			found = true
+			require.Equal(t, HoldStatusExpired, hold.Status)
+		}
+	}
+	require.True(t, found)
+
+	transfer, err := testQueries.GetTransfer(context.Background(), pending.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusExpired, transfer.Status)
+
+	account, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Zero(t, account.HeldBalance)
+	require.Equal(t, account1.Balance, account.AvailableBalance)
+}
+
+func TestStatementTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	from := time.Now()
+
+	amounts := []int64{10, 20, 30}
+	for _, amount := range amounts {
+		_, err := store.DepositTx(context.Background(), DepositTxParams{
+			AccountID: account.ID,
+			Amount:    amount,
+		})
+		require.NoError(t, err)
+	}
+
+	result, err := store.StatementTx(context.Background(), StatementTxParams{
+		AccountID: account.ID,
+		FromTime:  from,
+		ToTime:    time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	// the account was created with a balance but no entry
+	require.Equal(t, account.Balance, result.OpeningBalance)
+	require.Equal(t, account.Balance+60, result.Account.Balance)
+	require.Len(t, result.Entries, len(amounts))
+	for i, entry := range result.Entries {
+		require.Equal(t, amounts[i], entry.Amount)
+	}
+
+	result, err = store.StatementTx(context.Background(), StatementTxParams{
+		AccountID: account.ID,
+		FromTime:  time.Now().Add(time.Minute),
+		ToTime:    time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+60, result.OpeningBalance)
+	require.Empty(t, result.Entries)
+}
+
+func TestStatementTxDoesNotLock(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+
+	// hold the account lock like a transfer in flight
+	tx, err := testDB.Begin()
+	require.NoError(t, err)
+	defer tx.Rollback()
+	_, err = New(tx).GetAccountForUpdate(context.Background(), account.ID)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := store.StatementTx(ctx, StatementTxParams{
+		AccountID: account.ID,
+		FromTime:  time.Now().Add(-time.Hour),
+		ToTime:    time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, result.Account.Balance)
+}
+
+func TestTransferTxInactiveAccount(t *testing.T) {
+	store := NewStore(testDB)
+
+	testCases := []struct {
+		status string
+		err    error
+	}{
+		{status: AccountStatusFrozen, err: ErrAccountFrozen},
+		{status: AccountStatusClosed, err: ErrAccountClosed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.status, func(t *testing.T) {
+			account1 := createRandomAccount(t)
+			account2 := createRandomAccount(t)
+
+			_, err := testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+				ID:     account2.ID,
+				Status: tc.status,
+			})
+			require.NoError(t, err)
+
+			// both directions are rejected
+			_, err = store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        1,
+			})
+			require.ErrorIs(t, err, tc.err)
+
+			_, err = store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: account2.ID,
+				ToAccountID:   account1.ID,
+				Amount:        1,
+			})
+			require.ErrorIs(t, err, tc.err)
+
+			_, err = store.DepositTx(context.Background(), DepositTxParams{
+				AccountID: account2.ID,
+				Amount:    1,
+			})
+			require.ErrorIs(t, err, tc.err)
+
+			_, err = store.WithdrawTx(context.Background(), WithdrawTxParams{
+				AccountID: account2.ID,
+				Amount:    1,
+			})
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestCloseAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+
+	// the balance has to go somewhere
+	_, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{
+		AccountID: account.ID,
+	})
+	require.ErrorIs(t, err, ErrNonZeroBalance)
+
+	sweepAccount, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Name:     account.Name,
+		Balance:  0,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+
+	result, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{
+		AccountID:        account.ID,
+		SweepToAccountID: sweepAccount.ID,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, AccountStatusClosed, result.Account.Status)
+	require.Zero(t, result.Account.Balance)
+	require.NotNil(t, result.Sweep)
+	require.Equal(t, account.Balance, result.Sweep.Transfer.Amount)
+	require.Equal(t, account.Balance, result.Sweep.ToAccount.Balance)
+
+	_, err = store.CloseAccountTx(context.Background(), CloseAccountTxParams{
+		AccountID: account.ID,
+	})
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	// an empty account closes without a sweep
+	emptyAccount, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Name:     account.Name,
+		Balance:  0,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+
+	result, err = store.CloseAccountTx(context.Background(), CloseAccountTxParams{
+		AccountID: emptyAccount.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, result.Account.Status)
+	require.Nil(t, result.Sweep)
+}
+
+func TestUpdateAccountStatusTxClosed(t *testing.T) {
+	store := NewStore(testDB)
+
+	owner := createRandomAccount(t)
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Name:     owner.Name,
+		Balance:  0,
+		Currency: owner.Currency,
+	})
+	require.NoError(t, err)
+
+	_, err = store.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account.ID})
+	require.NoError(t, err)
+
+	// the locked read finds the account closed, it stays closed
+	for _, status := range []string{AccountStatusActive, AccountStatusFrozen} {
+		_, err = store.UpdateAccountStatusTx(context.Background(), UpdateAccountStatusParams{
+			ID:     account.ID,
+			Status: status,
+		})
+		require.ErrorIs(t, err, ErrAccountClosed)
+	}
+
+	account, err = testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, account.Status)
+}
+
+func TestUpdateAccountOverdraftLimitTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	requestID := util.RandomString(16)
+	ctx := WithAuditContext(context.Background(), AuditContext{RequestID: requestID})
+
+	updated, err := store.UpdateAccountOverdraftLimitTx(ctx, UpdateAccountOverdraftLimitParams{
+		ID:             account.ID,
+		OverdraftLimit: 500,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(500), updated.OverdraftLimit)
+
+	entries, err := testQueries.ListAuditLogByRequest(context.Background(), requestID)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, AuditAccountOverdraft, entries[0].Action)
+	require.Equal(t, []int64{account.ID}, entries[0].TargetIds)
+
+	// closed accounts keep their limit
+	closed, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Name:     account.Name,
+		Balance:  0,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+	_, err = store.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: closed.ID})
+	require.NoError(t, err)
+
+	_, err = store.UpdateAccountOverdraftLimitTx(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             closed.ID,
+		OverdraftLimit: 500,
+	})
+	require.ErrorIs(t, err, ErrAccountClosed)
+}
+
+func TestAdjustEntriesTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	// random accounts start with a balance and no entries
+	account := createRandomAccount(t)
+	entry := createRandomEntry(t, account.ID)
+
+	result, err := store.AdjustEntriesTx(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance-entry.Amount, result.Delta)
+	require.NotNil(t, result.Entry)
+	require.Equal(t, result.Delta, result.Entry.Amount)
+	require.Nil(t, result.Entry.TransferID)
+	require.Equal(t, account.Balance, result.Account.Balance)
+
+	total, err := testQueries.SumEntries(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, total)
+
+	// nothing left to adjust
+	result, err = store.AdjustEntriesTx(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Zero(t, result.Delta)
+	require.Nil(t, result.Entry)
+}
+
+func TestProcessScheduledTransfersTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// start in the past so the run is due, earlier runs of this test have
+	// already moved their schedules past now
+	startAt := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endAt := startAt.Add(60 * time.Hour)
+	scheduled, err := testQueries.CreateScheduledTransfer(context.Background(), CreateScheduledTransferParams{
+		Owner:         account1.Name,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      account1.Currency,
+		IntervalUnit:  IntervalDay,
+		IntervalCount: 1,
+		StartAt:       startAt,
+		EndAt:         &endAt,
+	})
+	require.NoError(t, err)
+
+	// two runs are due, the missed one is skipped
+	now := startAt.Add(36 * time.Hour)
+	runs, err := store.ProcessScheduledTransfersTx(context.Background(), ProcessScheduledTransfersTxParams{
+		Now:  now,
+		Size: 100,
+		Execute: func(ctx context.Context, due ScheduledTransfer, idempotency *IdempotencyParams) (TransferTxResult, error) {
+			if due.ID != scheduled.ID {
+				return TransferTxResult{}, fmt.Errorf("scheduled transfer %d is not part of the test", due.ID)
+			}
+			return store.TransferTx(ctx, TransferTxParams{
+				FromAccountID: due.FromAccountID,
+				ToAccountID:   due.ToAccountID,
+				Amount:        due.Amount,
+				Idempotency:   idempotency,
+			})
+		},
+	})
+	require.NoError(t, err)
+
+	var run *ScheduledTransferRun
+	for i := range runs {
+		if runs[i].ScheduledTransferID == scheduled.ID {
+			run = &runs[i]
+		}
+	}
+	require.NotNil(t, run)
+	require.Equal(t, ScheduledRunSucceeded, run.Status)
+	require.NotNil(t, run.TransferID)
+	require.WithinDuration(t, startAt, run.ScheduledFor, time.Second)
+
+	updated, err := testQueries.GetScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), updated.Occurrence)
+	require.WithinDuration(t, startAt.AddDate(0, 0, 2), updated.NextRunAt, time.Second)
+	require.Equal(t, ScheduledTransferCompleted, updated.Status)
+
+	fromAccount, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-10, fromAccount.Balance)
+}
+
+// createDueScheduledTransfer creates a daily transfer between two new
+// accounts whose first run has been due since 2000
+func createDueScheduledTransfer(t *testing.T, endAt *time.Time) ScheduledTransfer {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	scheduled, err := testQueries.CreateScheduledTransfer(context.Background(), CreateScheduledTransferParams{
+		Owner:         account1.Name,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      account1.Currency,
+		IntervalUnit:  IntervalDay,
+		IntervalCount: 1,
+		StartAt:       time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+		EndAt:         endAt,
+	})
+	require.NoError(t, err)
+
+	return scheduled
+}
+
+// findScheduledRun returns the run of scheduled among runs, nil if there is none
+func findScheduledRun(runs []ScheduledTransferRun, scheduled ScheduledTransfer) *ScheduledTransferRun {
+	for i := range runs {
+		if runs[i].ScheduledTransferID == scheduled.ID {
+			return &runs[i]
+		}
+	}
+	return nil
+}
+
+func TestProcessScheduledTransfersTxReplaysTransfer(t *testing.T) {
+	store := NewStore(testDB)
+	scheduled := createDueScheduledTransfer(t, nil)
+
+	// an earlier attempt moved the money but could not record the run
+	transferred, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: scheduled.FromAccountID,
+		ToAccountID:   scheduled.ToAccountID,
+		Amount:        scheduled.Amount,
+		Idempotency:   scheduled.Idempotency(),
+	})
+	require.NoError(t, err)
+
+	runs, err := store.ProcessScheduledTransfersTx(context.Background(), ProcessScheduledTransfersTxParams{
+		Now:  scheduled.StartAt.Add(time.Hour),
+		Size: 100,
+		Execute: func(ctx context.Context, due ScheduledTransfer, idempotency *IdempotencyParams) (TransferTxResult, error) {
+			if due.ID == scheduled.ID {
+				t.Errorf("scheduled transfer %d was executed twice", due.ID)
+			}
+			return TransferTxResult{}, fmt.Errorf("scheduled transfer %d is not part of the test", due.ID)
+		},
+	})
+	require.NoError(t, err)
+
+	run := findScheduledRun(runs, scheduled)
+	require.NotNil(t, run)
+	require.Equal(t, ScheduledRunSucceeded, run.Status)
+	require.Equal(t, transferred.Transfer.ID, *run.TransferID)
+
+	fromAccount, err := testQueries.GetAccount(context.Background(), scheduled.FromAccountID)
+	require.NoError(t, err)
+	require.Equal(t, transferred.FromAccount.Balance, fromAccount.Balance)
+}
+
+func TestProcessScheduledTransfersTxAfterEnd(t *testing.T) {
+	store := NewStore(testDB)
+	endAt := time.Date(2000, time.January, 10, 0, 0, 0, 0, time.UTC)
+	scheduled := createDueScheduledTransfer(t, &endAt)
+
+	// end_at moved before the due run
+	endAt = scheduled.StartAt.Add(-time.Hour)
+	_, err := testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:    scheduled.ID,
+		EndAt: &endAt,
+	})
+	require.NoError(t, err)
+
+	runs, err := store.ProcessScheduledTransfersTx(context.Background(), ProcessScheduledTransfersTxParams{
+		Now:  scheduled.StartAt.Add(time.Hour),
+		Size: 100,
+		Execute: func(ctx context.Context, due ScheduledTransfer, idempotency *IdempotencyParams) (TransferTxResult, error) {
+			if due.ID == scheduled.ID {
+				t.Errorf("scheduled transfer %d ran after its end", due.ID)
+			}
+			return TransferTxResult{}, fmt.Errorf("scheduled transfer %d is not part of the test", due.ID)
+		},
+	})
+	require.NoError(t, err)
+	require.Nil(t, findScheduledRun(runs, scheduled))
+
+	updated, err := testQueries.GetScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferCompleted, updated.Status)
+	require.Equal(t, scheduled.Occurrence, updated.Occurrence)
+}
@@ -7,26 +7,57 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  to_amount,
+  exchange_rate,
+  spread_bps,
+  reversal_of,
+  status,
+  description,
+  external_reference,
+  metadata
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, spread_bps, reversal_of, status, description, external_reference, metadata
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID     int64           `json:"from_account_id"`
+	ToAccountID       int64           `json:"to_account_id"`
+	Amount            int64           `json:"amount"`
+	ToAmount          int64           `json:"to_amount"`
+	ExchangeRate      string          `json:"exchange_rate"`
+	SpreadBps         int32           `json:"spread_bps"`
+	ReversalOf        *int64          `json:"reversal_of"`
+	Status            string          `json:"status"`
+	Description       string          `json:"description"`
+	ExternalReference string          `json:"external_reference"`
+	Metadata          json.RawMessage `json:"metadata"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.ExchangeRate,
+		arg.SpreadBps,
+		arg.ReversalOf,
+		arg.Status,
+		arg.Description,
+		arg.ExternalReference,
+		arg.Metadata,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,12 +65,20 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.SpreadBps,
+		&i.ReversalOf,
+		&i.Status,
+		&i.Description,
+		&i.ExternalReference,
+		&i.Metadata,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, spread_bps, reversal_of, status, description, external_reference, metadata FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -52,33 +91,93 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.SpreadBps,
+		&i.ReversalOf,
+		&i.Status,
+		&i.Description,
+		&i.ExternalReference,
+		&i.Metadata,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, spread_bps, reversal_of, status, description, external_reference, metadata FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.SpreadBps,
+		&i.ReversalOf,
+		&i.Status,
+		&i.Description,
+		&i.ExternalReference,
+		&i.Metadata,
 	)
 	return i, err
 }
 
-const getTransfers = `-- name: GetTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+const listTransfers = `-- name: ListTransfers :many
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, spread_bps, reversal_of, status, description, external_reference, metadata FROM transfers
 WHERE 
-    from_account_id = $1 OR
-    to_account_id = $2
-ORDER BY id
-LIMIT $3
-OFFSET $4
+    (from_account_id = $1 OR
+    to_account_id = $1)
+    AND (created_at, id) > ($2::timestamptz, $3::bigint)
+    AND ($4::timestamptz IS NULL OR created_at >= $4)
+    AND ($5::timestamptz IS NULL OR created_at <= $5)
+    AND ($6::bigint IS NULL OR amount >= $6)
+    AND ($7::bigint IS NULL OR amount <= $7)
+    AND ($8::text IS NULL
+        OR ($8 = 'outgoing' AND from_account_id = $1)
+        OR ($8 = 'incoming' AND to_account_id = $1))
+    AND ($9::bigint IS NULL
+        OR (from_account_id = $1 AND to_account_id = $9)
+        OR (to_account_id = $1 AND from_account_id = $9))
+    AND ($10::text IS NULL OR external_reference = $10)
+ORDER BY created_at, id
+LIMIT $11
 `
 
-type GetTransfersParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Limit         int32 `json:"limit"`
-	Offset        int32 `json:"offset"`
+type ListTransfersParams struct {
+	AccountID      int64          `json:"account_id"`
+	AfterCreatedAt time.Time      `json:"after_created_at"`
+	AfterID        int64          `json:"after_id"`
+	FromTime       sql.NullTime   `json:"from_time"`
+	ToTime         sql.NullTime   `json:"to_time"`
+	MinAmount      sql.NullInt64  `json:"min_amount"`
+	MaxAmount      sql.NullInt64  `json:"max_amount"`
+	Direction      sql.NullString `json:"direction"`
+	CounterpartyID sql.NullInt64  `json:"counterparty_id"`
+	Reference      sql.NullString `json:"reference"`
+	Size           int32          `json:"size"`
 }
 
-func (q *Queries) GetTransfers(ctx context.Context, arg GetTransfersParams) ([]Transfer, error) {
-	rows, err := q.db.QueryContext(ctx, getTransfers,
-		arg.FromAccountID,
-		arg.ToAccountID,
-		arg.Limit,
-		arg.Offset,
+func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listTransfers,
+		arg.AccountID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Direction,
+		arg.CounterpartyID,
+		arg.Reference,
+		arg.Size,
 	)
 	if err != nil {
 		return nil, err
@@ -93,6 +192,14 @@ func (q *Queries) GetTransfers(ctx context.Context, arg GetTransfersParams) ([]T
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.SpreadBps,
+			&i.ReversalOf,
+			&i.Status,
+			&i.Description,
+			&i.ExternalReference,
+			&i.Metadata,
 		); err != nil {
 			return nil, err
 		}
@@ -107,24 +214,75 @@ func (q *Queries) GetTransfers(ctx context.Context, arg GetTransfersParams) ([]T
 	return items, nil
 }
 
-const getTransfersByAccount = `-- name: GetTransfersByAccount :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
-WHERE 
-    from_account_id = $1 OR
-    to_account_id = $1
+const listTransferReversals = `-- name: ListTransferReversals :many
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, spread_bps, reversal_of, status, description, external_reference, metadata FROM transfers
+WHERE reversal_of = $1
 ORDER BY id
-LIMIT $3
-OFFSET $2
 `
 
-type GetTransfersByAccountParams struct {
-	ID   int64 `json:"id"`
-	Off  int32 `json:"off"`
-	Size int32 `json:"size"`
+func (q *Queries) ListTransferReversals(ctx context.Context, reversalOf *int64) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listTransferReversals, reversalOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.SpreadBps,
+			&i.ReversalOf,
+			&i.Status,
+			&i.Description,
+			&i.ExternalReference,
+			&i.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserTransfers = `-- name: ListUserTransfers :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.to_amount, t.exchange_rate, t.spread_bps, t.reversal_of, t.status, t.description, t.external_reference, t.metadata FROM transfers t
+WHERE EXISTS (
+        SELECT 1 FROM accounts a
+        WHERE a.name = $1
+            AND a.id IN (t.from_account_id, t.to_account_id)
+    )
+    AND (t.created_at, t.id) > ($2::timestamptz, $3::bigint)
+ORDER BY t.created_at, t.id
+LIMIT $4
+`
+
+type ListUserTransfersParams struct {
+	Username       string    `json:"username"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+	Size           int32     `json:"size"`
 }
 
-func (q *Queries) GetTransfersByAccount(ctx context.Context, arg GetTransfersByAccountParams) ([]Transfer, error) {
-	rows, err := q.db.QueryContext(ctx, getTransfersByAccount, arg.ID, arg.Off, arg.Size)
+func (q *Queries) ListUserTransfers(ctx context.Context, arg ListUserTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listUserTransfers,
+		arg.Username,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Size,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -138,6 +296,14 @@ func (q *Queries) GetTransfersByAccount(ctx context.Context, arg GetTransfersByA
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.SpreadBps,
+			&i.ReversalOf,
+			&i.Status,
+			&i.Description,
+			&i.ExternalReference,
+			&i.Metadata,
 		); err != nil {
 			return nil, err
 		}
@@ -151,3 +317,45 @@ func (q *Queries) GetTransfersByAccount(ctx context.Context, arg GetTransfersByA
 	}
 	return items, nil
 }
+
+const sumTransferReversals = `-- name: SumTransferReversals :one
+SELECT COALESCE(SUM(to_amount), 0)::bigint AS total FROM transfers
+WHERE reversal_of = $1
+`
+
+func (q *Queries) SumTransferReversals(ctx context.Context, reversalOf *int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumTransferReversals, reversalOf)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const updateTransferStatus = `-- name: UpdateTransferStatus :one
+UPDATE transfers SET status = $1 WHERE id = $2 RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, spread_bps, reversal_of, status, description, external_reference, metadata
+`
+
+type UpdateTransferStatusParams struct {
+	Status string `json:"status"`
+	ID     int64  `json:"id"`
+}
+
+func (q *Queries) UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, updateTransferStatus, arg.Status, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.SpreadBps,
+		&i.ReversalOf,
+		&i.Status,
+		&i.Description,
+		&i.ExternalReference,
+		&i.Metadata,
+	)
+	return i, err
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -10,10 +11,14 @@ import (
 )
 
 func createRandomTransfer(t *testing.T, account1, account2 Account) Transfer {
+	amount := util.RandomAmount()
 	arg := CreateTransferParams{
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
-		Amount:        util.RandomAmount(),
+		Amount:        amount,
+		ToAmount:      amount,
+		ExchangeRate:  "1",
+		Status:        TransferStatusPosted,
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
@@ -24,6 +29,9 @@ func createRandomTransfer(t *testing.T, account1, account2 Account) Transfer {
 	require.Equal(t, arg.FromAccountID, transfer.FromAccountID)
 	require.Equal(t, arg.ToAccountID, transfer.ToAccountID)
 	require.Equal(t, arg.Amount, transfer.Amount)
+	require.Equal(t, arg.ToAmount, transfer.ToAmount)
+	require.Equal(t, "1.00000000", transfer.ExchangeRate)
+	require.Equal(t, TransferStatusPosted, transfer.Status)
 
 	require.NotZero(t, transfer.ID)
 	require.NotZero(t, transfer.CreatedAt)
@@ -56,50 +64,115 @@ func TestGetTransfer(t *testing.T) {
 	require.WithinDuration(t, transfer1.CreatedAt, transfer2.CreatedAt, time.Second)
 }
 
-func TestGetTransfers(t *testing.T) {
+func TestListTransfers(t *testing.T) {
 	account1 := createRandomAccount(t)
 	account2 := createRandomAccount(t)
+	account3 := createRandomAccount(t)
 
-	for i := 0; i < 10; i++ {
+	for i := 0; i < 3; i++ {
 		createRandomTransfer(t, account1, account2)
+		createRandomTransfer(t, account3, account1)
 	}
 
-	arg := GetTransfersParams{
-		FromAccountID: account1.ID,
-		ToAccountID:   account2.ID,
-		Limit:  5,
-		Offset: 5,
+	testCases := []struct {
+		name  string
+		arg   ListTransfersParams
+		count int
+		check func(transfer Transfer)
+	}{
+		{
+			name:  "All",
+			arg:   ListTransfersParams{},
+			count: 6,
+		},
+		{
+			name:  "Outgoing",
+			arg:   ListTransfersParams{Direction: sql.NullString{String: "outgoing", Valid: true}},
+			count: 3,
+			check: func(transfer Transfer) {
+				require.Equal(t, account1.ID, transfer.FromAccountID)
+			},
+		},
+		{
+			name:  "Incoming",
+			arg:   ListTransfersParams{Direction: sql.NullString{String: "incoming", Valid: true}},
+			count: 3,
+			check: func(transfer Transfer) {
+				require.Equal(t, account1.ID, transfer.ToAccountID)
+			},
+		},
+		{
+			name:  "Counterparty",
+			arg:   ListTransfersParams{CounterpartyID: sql.NullInt64{Int64: account2.ID, Valid: true}},
+			count: 3,
+			check: func(transfer Transfer) {
+				require.Equal(t, account2.ID, transfer.ToAccountID)
+			},
+		},
+		{
+			name:  "AmountRange",
+			arg:   ListTransfersParams{
+				MinAmount: sql.NullInt64{Int64: 0, Valid: true},
+				MaxAmount: sql.NullInt64{Int64: 1000, Valid: true},
+			},
+			count: 6,
+		},
+		{
+			name:  "Future",
+			arg:   ListTransfersParams{FromTime: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}},
+			count: 0,
+		},
 	}
 
-	transfers, err := testQueries.GetTransfers(context.Background(), arg)
+	for i := range testCases {
+		tc := testCases[i]
 
-	require.NoError(t, err)
-	require.Len(t, transfers, 5)
+		t.Run(tc.name, func(t *testing.T) {
+			tc.arg.AccountID = account1.ID
+			tc.arg.Size = 10
 
-	for _, transfer := range transfers {
-		require.NotEmpty(t, transfer)
+			transfers, err := testQueries.ListTransfers(context.Background(), tc.arg)
+			require.NoError(t, err)
+			require.Len(t, transfers, tc.count)
+
+			for _, transfer := range transfers {
+				if tc.check != nil {
+					tc.check(transfer)
+				}
+			}
+		})
 	}
 }
 
-func TestGetTransfersByAccount(t *testing.T) {
-	account := createRandomAccount(t)
+func TestListUserTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
 	account2 := createRandomAccount(t)
+	account3 := createRandomAccount(t)
 
-	for i := 0; i < 10; i++ {
-		createRandomTransfer(t, account, account2)
+	for i := 0; i < 2; i++ {
+		createRandomTransfer(t, account1, account2)
+		createRandomTransfer(t, account3, account1)
+		createRandomTransfer(t, account2, account3)
 	}
 
-	arg := GetTransfersByAccountParams{
-		ID: account.ID,
-		Off: 1,
-		Size: 5,
-	}
-	
-	transfers, err := testQueries.GetTransfersByAccount(context.Background(), arg)
+	transfers, err := testQueries.ListUserTransfers(context.Background(), ListUserTransfersParams{
+		Username: account1.Name,
+		Size:     10,
+	})
 	require.NoError(t, err)
-	require.Len(t, transfers, 5)
+	require.Len(t, transfers, 4)
 
 	for _, transfer := range transfers {
-		require.NotEmpty(t, transfer)
+		require.True(t, transfer.FromAccountID == account1.ID || transfer.ToAccountID == account1.ID)
 	}
-}
\ No newline at end of file
+
+	last := transfers[1]
+	transfers, err = testQueries.ListUserTransfers(context.Background(), ListUserTransfersParams{
+		Username:       account1.Name,
+		AfterCreatedAt: last.CreatedAt,
+		AfterID:        last.ID,
+		Size:           10,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Webhook delivery statuses, dead deliveries ran out of attempts and are not
+// retried
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead"
+)
+
+// queueWebhooks queues a delivery of the event for every subscription of
+// its owners that asked for it
+func queueWebhooks(ctx context.Context, q *Queries, event Event, payload json.RawMessage) error {
+	for i, owner := range event.Owners {
+		// both sides of a transfer between one user's accounts get it once
+		if i > 0 && owner == event.Owners[0] {
+			continue
+		}
+
+		err := q.CreateWebhookDeliveries(ctx, CreateWebhookDeliveriesParams{
+			EventID:   event.ID,
+			EventType: event.Type,
+			Payload:   payload,
+			Owner:     owner,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,197 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomWebhook(t *testing.T, owner string, eventTypes ...string) WebhookSubscription {
+	arg := CreateWebhookSubscriptionParams{
+		Owner:      owner,
+		Url:        "https://example.com/hooks",
+		Secret:     util.RandomString(32),
+		EventTypes: eventTypes,
+	}
+
+	webhook, err := testQueries.CreateWebhookSubscription(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, webhook.ID)
+	require.Equal(t, arg.Owner, webhook.Owner)
+	require.Equal(t, arg.Url, webhook.Url)
+	require.Equal(t, arg.Secret, webhook.Secret)
+	require.Equal(t, arg.EventTypes, webhook.EventTypes)
+	require.NotZero(t, webhook.CreatedAt)
+
+	return webhook
+}
+
+func listDeliveries(t *testing.T, webhookID int64) []WebhookDelivery {
+	deliveries, err := testQueries.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+		SubscriptionID: webhookID,
+		Size:           100,
+	})
+	require.NoError(t, err)
+	return deliveries
+}
+
+func TestDeleteWebhookSubscription(t *testing.T) {
+	account := createRandomAccount(t)
+	webhook := createRandomWebhook(t, account.Name, EventTransferCreated)
+
+	err := testQueries.DeleteWebhookSubscription(context.Background(), webhook.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetWebhookSubscription(context.Background(), webhook.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestTransferTxWebhookOutbox(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	created := createRandomWebhook(t, account1.Name, EventTransferCreated)
+	balance := createRandomWebhook(t, account2.Name, EventAccountBalanceChanged)
+	frozen := createRandomWebhook(t, account2.Name, EventAccountFrozen)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	deliveries := listDeliveries(t, created.ID)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, EventTransferCreated, deliveries[0].EventType)
+	require.Equal(t, WebhookDeliveryPending, deliveries[0].Status)
+	require.Zero(t, deliveries[0].Attempts)
+
+	var event struct {
+		ID   string   `json:"id"`
+		Type string   `json:"type"`
+		Data Transfer `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(deliveries[0].Payload, &event))
+	require.Equal(t, deliveries[0].EventID.String(), event.ID)
+	require.Equal(t, EventTransferCreated, event.Type)
+	require.Equal(t, result.Transfer.ID, event.Data.ID)
+
+	// only the receiver's balance change goes to the receiver
+	deliveries = listDeliveries(t, balance.ID)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, EventAccountBalanceChanged, deliveries[0].EventType)
+
+	var changed struct {
+		Data BalanceChangedEvent `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(deliveries[0].Payload, &changed))
+	require.Equal(t, account2.ID, changed.Data.Account.ID)
+	require.Equal(t, int64(10), changed.Data.Amount)
+	require.Equal(t, result.Transfer.ID, *changed.Data.TransferID)
+
+	require.Empty(t, listDeliveries(t, frozen.ID))
+
+	account, err := store.UpdateAccountStatusTx(context.Background(), UpdateAccountStatusParams{
+		ID:     account2.ID,
+		Status: AccountStatusFrozen,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusFrozen, account.Status)
+
+	deliveries = listDeliveries(t, frozen.ID)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, EventAccountFrozen, deliveries[0].EventType)
+}
+
+func TestTransferTxWebhookOutboxRollback(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	webhook := createRandomWebhook(t, account1.Name, EventTransferCreated, EventAccountBalanceChanged)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + account1.OverdraftLimit + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	require.Empty(t, listDeliveries(t, webhook.ID))
+}
+
+func TestClaimWebhookDeliveries(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	webhook := createRandomWebhook(t, account.Name, EventAccountBalanceChanged)
+
+	_, err := store.DepositTx(context.Background(), DepositTxParams{
+		AccountID: account.ID,
+		Amount:    10,
+	})
+	require.NoError(t, err)
+
+	delivery := listDeliveries(t, webhook.ID)[0]
+
+	now := time.Now().Add(time.Second)
+	leaseUntil := now.Add(time.Minute)
+	claimed, err := testQueries.ClaimWebhookDeliveries(context.Background(), ClaimWebhookDeliveriesParams{
+		LeaseUntil: leaseUntil,
+		Now:        now,
+		Size:       1000,
+	})
+	require.NoError(t, err)
+
+	var found *ClaimWebhookDeliveriesRow
+	for i := range claimed {
+		if claimed[i].ID == delivery.ID {
+			found = &claimed[i]
+		}
+	}
+	require.NotNil(t, found)
+	require.Equal(t, webhook.Url, found.Url)
+	require.Equal(t, webhook.Secret, found.Secret)
+	require.WithinDuration(t, leaseUntil, found.NextAttemptAt, time.Second)
+
+	// leased deliveries are not claimed again
+	claimed, err = testQueries.ClaimWebhookDeliveries(context.Background(), ClaimWebhookDeliveriesParams{
+		LeaseUntil: leaseUntil,
+		Now:        now,
+		Size:       1000,
+	})
+	require.NoError(t, err)
+	for _, row := range claimed {
+		require.NotEqual(t, delivery.ID, row.ID)
+	}
+
+	recorded, err := testQueries.RecordWebhookDeliveryAttempt(context.Background(), RecordWebhookDeliveryAttemptParams{
+		ID:             delivery.ID,
+		Status:         WebhookDeliveryDead,
+		NextAttemptAt:  now,
+		LastAttemptAt:  &now,
+		ResponseStatus: 500,
+		LastError:      "unexpected status 500",
+	})
+	require.NoError(t, err)
+	require.Equal(t, WebhookDeliveryDead, recorded.Status)
+	require.Equal(t, int32(1), recorded.Attempts)
+	require.Equal(t, int32(500), recorded.ResponseStatus)
+	require.NotNil(t, recorded.LastAttemptAt)
+
+	dead, err := testQueries.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+		SubscriptionID: webhook.ID,
+		Status:         sql.NullString{String: WebhookDeliveryDead, Valid: true},
+		Size:           10,
+	})
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+}
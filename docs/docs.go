@@ -17,7 +17,12 @@ const docTemplate = `{
     "paths": {
         "/accounts": {
             "get": {
-                "description": "Get a list of accounts with pagination",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of the authenticated user's accounts oldest first, pass next_cursor as cursor to get the next page",
                 "produces": [
                     "application/json"
                 ],
@@ -27,15 +32,13 @@ const docTemplate = `{
                 "summary": "Get a list of accounts",
                 "parameters": [
                     {
-                        "minimum": 1,
-                        "type": "integer",
-                        "name": "page",
-                        "in": "query",
-                        "required": true
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
                     },
                     {
-                        "maximum": 10,
-                        "minimum": 5,
+                        "maximum": 100,
+                        "minimum": 1,
                         "type": "integer",
                         "name": "size",
                         "in": "query",
@@ -46,10 +49,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/db.Account"
-                            }
+                            "$ref": "#/definitions/api.listAccountsResponse"
                         }
                     }
                 }
@@ -57,7 +57,12 @@ const docTemplate = `{
         },
         "/accounts/deposit": {
             "post": {
-                "description": "Deposit money to an account by the specified ID",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deposit money to an account by the specified ID, retries with the same Idempotency-Key replay the first response",
                 "produces": [
                     "application/json"
                 ],
@@ -74,13 +79,61 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.depositRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Idempotency Key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.DepositTxResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/withdraw": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Withdraw money from an account by the specified ID, retries with the same Idempotency-Key replay the first response. Fails with 422 and code insufficient_funds when the balance plus overdraft limit does not cover the amount",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Withdraw money from an account",
+                "parameters": [
+                    {
+                        "description": "Withdraw Request",
+                        "name": "account",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.withdrawRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Idempotency Key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/db.Account"
+                            "$ref": "#/definitions/db.WithdrawTxResult"
                         }
                     }
                 }
@@ -88,6 +141,11 @@ const docTemplate = `{
         },
         "/accounts/{id}": {
             "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Get an account by the specified ID",
                 "produces": [
                     "application/json"
@@ -109,32 +167,81 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/db.Account"
+                            "$ref": "#/definitions/api.accountResponse"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete an account by the specified ID",
+            }
+        },
+        "/accounts/{id}/close": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Close an account for good, the balance must be zero or is moved to sweep_to_account_id, which must use the same currency. Fails with 422 and code non_zero_balance, held_balance (pending transfers still hold money), account_frozen or account_closed when the account cannot be closed",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "accounts"
                 ],
-                "summary": "Delete an account by ID",
+                "summary": "Close an account",
                 "parameters": [
                     {
-                        "minimum": 1,
                         "type": "integer",
+                        "description": "Account ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Close Account Request",
+                        "name": "account",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.closeAccountRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.closeAccountResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{id}/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Server-sent events for an account, one account.balance_changed event with the updated account whenever a transfer, deposit or withdrawal touches it and account.frozen when it is frozen. The id and data of an event are the id and JSON of the outbox event. A ping event is sent every heartbeat interval, the stream ends when the access token expires and clients reconnect with a renewed one",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Stream account events",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "event stream",
                         "schema": {
                             "type": "string"
                         }
@@ -142,8 +249,74 @@ const docTemplate = `{
                 }
             }
         },
+        "/accounts/{id}/statement": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Download the statement of an account from the start of from to the end of to (UTC dates) as csv, ofx or pdf, with the opening balance, every entry with the running balance and the closing balance",
+                "produces": [
+                    "text/csv",
+                    "application/x-ofx",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Download an account statement",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ofx",
+                            "pdf"
+                        ],
+                        "type": "string",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "date",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "date",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
         "/acounts": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
                 "description": "Create a new account with the specified name and currency",
                 "produces": [
                     "application/json"
@@ -167,90 +340,88 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/db.Account"
+                            "$ref": "#/definitions/api.accountResponse"
                         }
                     }
                 }
             }
         },
-        "/entry": {
+        "/admin/accounts": {
             "get": {
-                "description": "Get a list of entries by account with pagination",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the accounts of every user oldest first, optionally only the accounts of one owner or in one status. Pass next_cursor as cursor to get the next page. Bankers and admins only",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "entries"
+                    "admin"
                 ],
-                "summary": "Get a list of entries by account",
+                "summary": "List the accounts of every user",
                 "parameters": [
                     {
-                        "minimum": 1,
-                        "type": "integer",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
                     },
                     {
-                        "minimum": 1,
-                        "type": "integer",
-                        "name": "page",
-                        "in": "query",
-                        "required": true
+                        "type": "string",
+                        "name": "owner",
+                        "in": "query"
                     },
                     {
-                        "maximum": 10,
+                        "maximum": 100,
                         "minimum": 1,
                         "type": "integer",
                         "name": "size",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "frozen",
+                            "closed"
+                        ],
+                        "type": "string",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/db.Entry"
-                            }
+                            "$ref": "#/definitions/api.listAccountsResponse"
                         }
                     }
                 }
             }
         },
-        "/transfers": {
-            "get": {
-                "description": "Get transfers by the specified account ID",
+        "/admin/accounts/{id}/freeze": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stop all money movement in and out of an account until it is unfrozen, bankers and admins only. Fails with 422 and code account_closed for closed accounts",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transfers"
+                    "admin"
                 ],
-                "summary": "Get transfers by account ID",
+                "summary": "Freeze an account",
                 "parameters": [
                     {
-                        "minimum": 1,
                         "type": "integer",
+                        "description": "Account ID",
                         "name": "id",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "minimum": 1,
-                        "type": "integer",
-                        "name": "page",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "maximum": 10,
-                        "minimum": 1,
-                        "type": "integer",
-                        "name": "size",
-                        "in": "query",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -258,31 +429,42 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/db.Transfer"
-                            }
+                            "$ref": "#/definitions/api.accountResponse"
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Create a new transfer between two accounts",
+            }
+        },
+        "/admin/accounts/{id}/overdraft_limit": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set how far below zero the available balance of an account may go, admins only, bankers can freeze accounts but not change their limit. Fails with 422 and code account_closed for closed accounts",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transfers"
+                    "admin"
                 ],
-                "summary": "Create a new transfer",
+                "summary": "Set the overdraft limit of an account",
                 "parameters": [
                     {
-                        "description": "Create Transfer Request",
-                        "name": "transfer",
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Overdraft Limit Request",
+                        "name": "account",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.createTransferRequest"
+                            "$ref": "#/definitions/api.updateOverdraftLimitRequest"
                         }
                     }
                 ],
@@ -290,26 +472,31 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/db.Transfer"
+                            "$ref": "#/definitions/api.accountResponse"
                         }
                     }
                 }
             }
         },
-        "/transfers/{id}": {
-            "get": {
-                "description": "Get a transfer by the specified ID",
+        "/admin/accounts/{id}/unfreeze": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Make a frozen account active again, bankers and admins only. Fails with 422 and code account_closed for closed accounts",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transfers"
+                    "admin"
                 ],
-                "summary": "Get a transfer by ID",
+                "summary": "Unfreeze an account",
                 "parameters": [
                     {
-                        "minimum": 1,
                         "type": "integer",
+                        "description": "Account ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -319,30 +506,61 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/db.Transfer"
+                            "$ref": "#/definitions/api.accountResponse"
                         }
                     }
                 }
             }
         },
-        "/users/register": {
+        "/admin/currencies": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List every currency in the registry including the disabled ones, admin only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List currencies",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/db.Currency"
+                            }
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Create a new user with the specified username, full name, email and password",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add an ISO 4217 currency to the registry, admin only",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Create a new user",
+                "summary": "Add a currency",
                 "parameters": [
                     {
-                        "description": "Create User Request",
-                        "name": "user",
+                        "description": "Create Currency Request",
+                        "name": "currency",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.createUserRequest"
+                            "$ref": "#/definitions/api.createCurrencyRequest"
                         }
                     }
                 ],
@@ -350,33 +568,1449 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.createUserResponse"
+                            "$ref": "#/definitions/db.Currency"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.createAccountRequest": {
-            "type": "object",
-            "required": [
-                "currency",
-                "name"
-            ],
-            "properties": {
-                "currency": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                }
-            }
         },
-        "api.createTransferRequest": {
-            "type": "object",
-            "required": [
-                "amount",
+        "/admin/currencies/{code}": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Enable or disable a currency without a redeploy, admin only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Enable or disable a currency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency Code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Currency Request",
+                        "name": "currency",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.updateCurrencyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.Currency"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/exchange_rates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List every configured exchange rate, admin only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List exchange rates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/db.ExchangeRate"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create or replace the rate used to convert from one currency to another, admin only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set an exchange rate",
+                "parameters": [
+                    {
+                        "description": "Exchange Rate Request",
+                        "name": "rate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.upsertExchangeRateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.ExchangeRate"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/exchange_rates/{from}/{to}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove the rate between two currencies so transfers between them are rejected, admin only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete an exchange rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "From Currency",
+                        "name": "from",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "To Currency",
+                        "name": "to",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reconciliation_runs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the latest reconciliation runs, newest first, admin only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List reconciliation runs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page Size",
+                        "name": "size",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.reconciliationRunResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Check every account balance against the sum of its entries and store the run, admin only. With adjust set a correcting entry is written for every mismatch. The run is stored even when it fails",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Run a reconciliation",
+                "parameters": [
+                    {
+                        "description": "Reconciliation Run Request",
+                        "name": "run",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.createReconciliationRunRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.reconciliationRunResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reconciliation_runs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a reconciliation run with the account ids and deltas of its mismatches, admin only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a reconciliation run",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Run ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.reconciliationRunResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{username}/role": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Make the user a customer, banker or admin. The new role applies from the next access token the user gets. Admins only and not for their own role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Change the role of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update User Role Request",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.updateUserRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.userResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{username}/transfers": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the transfers from or to any account of the user oldest first, pass next_cursor as cursor to get the next page. Bankers and admins only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List the transfers of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "size",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listTransfersResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/entry": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a list of entries by account oldest first, pass next_cursor as cursor to get the next page. Entries can be filtered by created_at between from and to, the absolute amount between min_amount and max_amount, direction (credit or debit) and the counterparty account of the transfer that wrote them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "entries"
+                ],
+                "summary": "Get a list of entries by account",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "counterparty_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "credit",
+                            "debit"
+                        ],
+                        "type": "string",
+                        "name": "direction",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "max_amount",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "size",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listEntriesResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/fx/quotes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lock in the current exchange rate for a short time, pass the quote id as quote_id when creating the transfer",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fx"
+                ],
+                "summary": "Quote a currency conversion",
+                "parameters": [
+                    {
+                        "description": "Create FX Quote Request",
+                        "name": "quote",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.createFxQuoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.fxQuoteResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduled-transfers": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the scheduled transfers of the logged in user oldest first, pass next_cursor as cursor to get the next page",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled transfers"
+                ],
+                "summary": "List scheduled transfers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page Size",
+                        "name": "size",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listScheduledTransfersResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Schedule a transfer that repeats every interval_count days, weeks or months from start_at until end_at or until it is cancelled. Both accounts must use the currency, runs that fail are recorded and the schedule moves on",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled transfers"
+                ],
+                "summary": "Create a scheduled transfer",
+                "parameters": [
+                    {
+                        "description": "Create Scheduled Transfer Request",
+                        "name": "scheduled_transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.createScheduledTransferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/db.ScheduledTransfer"
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduled-transfers/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled transfers"
+                ],
+                "summary": "Get a scheduled transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled Transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.ScheduledTransfer"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stop a scheduled transfer for good, its runs are kept. Fails with 422 and code scheduled_transfer_finished when it is already completed or cancelled",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled transfers"
+                ],
+                "summary": "Cancel a scheduled transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled Transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.ScheduledTransfer"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the amount or end_at of a scheduled transfer, or pause and resume it with status. end_at must not be before next_run_at. A resumed transfer runs once for the occurrences it missed. Fails with 422 and code scheduled_transfer_finished once it is completed or cancelled",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled transfers"
+                ],
+                "summary": "Update a scheduled transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled Transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Scheduled Transfer Request",
+                        "name": "scheduled_transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.updateScheduledTransferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.ScheduledTransfer"
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduled-transfers/{id}/runs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List every execution of a scheduled transfer oldest first with the transfer it made or the error it failed with, pass next_cursor as cursor to get the next page",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled transfers"
+                ],
+                "summary": "List the runs of a scheduled transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Scheduled Transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page Size",
+                        "name": "size",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listScheduledTransferRunsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Block the session so its refresh token can no longer renew access tokens. Users can revoke their own sessions, bankers and admins any session",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Revoke a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.sessionResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tokens/renew_access": {
+            "post": {
+                "description": "Issue a new access token from a refresh token whose session is still active",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Renew an access token",
+                "parameters": [
+                    {
+                        "description": "Renew Access Token Request",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.renewAccessTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.renewAccessTokenResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfers": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get transfers by the specified account ID oldest first, pass next_cursor as cursor to get the next page. Transfers can be filtered by created_at between from and to, amount between min_amount and max_amount, direction (incoming or outgoing), the counterparty account and the external_reference given when the transfer was created",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfers"
+                ],
+                "summary": "Get transfers by account ID",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "counterparty_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "incoming",
+                            "outgoing"
+                        ],
+                        "type": "string",
+                        "name": "direction",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "max_amount",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "maxLength": 64,
+                        "type": "string",
+                        "name": "reference",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "size",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listTransfersResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new transfer between two accounts, retries with the same Idempotency-Key replay the first response. The currency is the currency of the sender, when the receiver uses another currency the amount is converted with the quote_id rate or the current exchange rate. With mode=pending the amount is only held on the sender's account until the transfer is captured or voided, the hold expires after the configured TTL. The optional description (at most 255 characters) is copied onto both entries, external_reference takes at most 64 characters and metadata is a JSON object of at most 4 KB. Fails with 422 and code insufficient_funds when the available balance plus overdraft limit does not cover the amount, and with code conversion_too_small when the converted amount rounds down to nothing",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfers"
+                ],
+                "summary": "Create a new transfer",
+                "parameters": [
+                    {
+                        "description": "Create Transfer Request",
+                        "name": "transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.createTransferRequest"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "pending"
+                        ],
+                        "type": "string",
+                        "description": "pending to hold the money until the transfer is captured",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Idempotency Key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.TransferTxResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfers/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a transfer by the specified ID with the reversals made against it and the amount they refunded, transfers created pending also come with their hold",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfers"
+                ],
+                "summary": "Get a transfer by ID",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.transferResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfers/{id}/capture": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Post a pending transfer, the held money moves to the receiver. The owner of either account can capture it. Fails with 422 and code transfer_not_pending when the transfer was already captured, voided or is not pending and hold_expired when the hold ran out",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfers"
+                ],
+                "summary": "Capture a pending transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.TransferTxResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfers/{id}/reverse": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Move money from the receiver of a transfer back to the sender, only the owner of the receiving account can reverse it. The amount is in the currency of the sender and defaults to everything not reversed yet, a transfer can be reversed in parts until the whole amount is refunded. Fails with 422 and code reversal_exceeds_transfer when the amount is more than what is left, reverse_reversal when the transfer is itself a reversal, transfer_not_posted when the transfer is pending or was never posted and insufficient_funds when the receiver cannot cover it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfers"
+                ],
+                "summary": "Reverse a transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reverse Transfer Request",
+                        "name": "reversal",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.reverseTransferRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.ReverseTransferTxResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfers/{id}/void": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cancel a pending transfer and release the held money back to the sender. The owner of either account can void it. Fails with 422 and code transfer_not_pending when the transfer was already captured, voided or is not pending",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfers"
+                ],
+                "summary": "Void a pending transfer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.VoidTransferTxResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login": {
+            "post": {
+                "description": "Verify the username and password, start a session and return an access token and a refresh token. An unknown username and a wrong password both fail with 401 and the same error",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Log in a user",
+                "parameters": [
+                    {
+                        "description": "Login User Request",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.loginUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.loginUserResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/register": {
+            "post": {
+                "description": "Create a new user with the specified username, full name, email and password",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create a new user",
+                "parameters": [
+                    {
+                        "description": "Create User Request",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.createUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.userResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the webhooks of the logged in user oldest first, pass next_cursor as cursor to get the next page",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhooks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page Size",
+                        "name": "size",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listWebhooksResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Subscribe a URL to transfer.created, account.balance_changed and account.frozen events of the logged in user's accounts. Every delivery is signed, X-Webhook-Signature is sha256= followed by the hex HMAC-SHA256 of \"\u003cX-Webhook-Timestamp\u003e.\u003cbody\u003e\" keyed with the secret. The secret is only returned here. URLs must point to a public address, private, loopback and link-local addresses fail with 400 and redirects are not followed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create a webhook",
+                "parameters": [
+                    {
+                        "description": "Create Webhook Request",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.createWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.webhookResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stop sending events to the webhook, its pending deliveries and delivery log are deleted with it",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List every event queued for the webhook oldest first with its status, attempts, next_attempt_at and the response_status and last_error of the last attempt. Failed deliveries are retried with an exponential backoff, dead ones ran out of attempts. Pass next_cursor as cursor to get the next page",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List the deliveries of a webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page Size",
+                        "name": "size",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pending, succeeded or dead",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listWebhookDeliveriesResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.accountResponse": {
+            "type": "object",
+            "properties": {
+                "available_balance": {
+                    "description": "Balance that is not held by a pending transfer",
+                    "type": "integer"
+                },
+                "balance": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "formatted_available_balance": {
+                    "type": "string"
+                },
+                "formatted_balance": {
+                    "type": "string"
+                },
+                "held_balance": {
+                    "description": "Sum of the active holds on the account",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "overdraft_limit": {
+                    "description": "How far below zero the balance may go",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "active, frozen (no money can move) or closed (final)",
+                    "type": "string"
+                }
+            }
+        },
+        "api.closeAccountRequest": {
+            "type": "object",
+            "properties": {
+                "sweep_to_account_id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "api.closeAccountResponse": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "$ref": "#/definitions/api.accountResponse"
+                },
+                "sweep": {
+                    "$ref": "#/definitions/db.TransferTxResult"
+                }
+            }
+        },
+        "api.createAccountRequest": {
+            "type": "object",
+            "required": [
+                "currency",
+                "name"
+            ],
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.createCurrencyRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "numeric_code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "exponent": {
+                    "type": "integer",
+                    "maximum": 4,
+                    "minimum": 0
+                },
+                "numeric_code": {
+                    "type": "integer",
+                    "maximum": 999,
+                    "minimum": 1
+                }
+            }
+        },
+        "api.createFxQuoteRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "from_currency",
+                "to_currency"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "from_currency": {
+                    "type": "string"
+                },
+                "to_currency": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.createReconciliationRunRequest": {
+            "type": "object",
+            "properties": {
+                "adjust": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.createScheduledTransferRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "currency",
+                "from_account_id",
+                "interval_unit",
+                "start_at",
+                "to_account_id"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "end_at": {
+                    "type": "string"
+                },
+                "from_account_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "interval_count": {
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 1
+                },
+                "interval_unit": {
+                    "type": "string",
+                    "enum": [
+                        "day",
+                        "week",
+                        "month"
+                    ]
+                },
+                "start_at": {
+                    "type": "string"
+                },
+                "to_account_id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "api.createTransferRequest": {
+            "type": "object",
+            "required": [
+                "amount",
                 "currency",
                 "from_account_id",
                 "to_account_id"
@@ -385,40 +2019,563 @@ const docTemplate = `{
                 "amount": {
                     "type": "integer"
                 },
-                "currency": {
+                "currency": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "external_reference": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "from_account_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "metadata": {
+                    "type": "object"
+                },
+                "quote_id": {
+                    "type": "string"
+                },
+                "to_account_id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "api.createUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "full_name",
+                "password",
+                "password_again",
+                "username"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 6
+                },
+                "password_again": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.createWebhookRequest": {
+            "type": "object",
+            "required": [
+                "event_types",
+                "url"
+            ],
+            "properties": {
+                "event_types": {
+                    "type": "array",
+                    "minItems": 1,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "description": "Secret signs the deliveries, one is generated when it is empty",
+                    "type": "string",
+                    "maxLength": 128,
+                    "minLength": 16
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048
+                }
+            }
+        },
+        "api.depositRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "currency",
+                "id"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "api.entryResponse": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "description": "Can be negative or positive value",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "formatted_amount": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "transfer_id": {
+                    "description": "Transfer that wrote the entry, null for deposits and withdrawals",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.fxQuoteResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "formatted_amount": {
+                    "type": "string"
+                },
+                "formatted_to_amount": {
+                    "type": "string"
+                },
+                "from_currency": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "string"
+                },
+                "spread_bps": {
+                    "type": "integer"
+                },
+                "to_amount": {
+                    "type": "integer"
+                },
+                "to_currency": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.listAccountsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.accountResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.listEntriesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.entryResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.listScheduledTransferRunsResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.ScheduledTransferRun"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.listScheduledTransfersResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.ScheduledTransfer"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.listTransfersResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.Transfer"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.listWebhookDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.WebhookDelivery"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.listWebhooksResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.webhookResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.loginUserRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "minLength": 6
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.loginUserResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "access_token_expires_at": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "refresh_token_expires_at": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/api.userResponse"
+                }
+            }
+        },
+        "api.reconciliationRunResponse": {
+            "type": "object",
+            "properties": {
+                "accounts_checked": {
+                    "type": "integer"
+                },
+                "adjust": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "mismatch_count": {
+                    "type": "integer"
+                },
+                "mismatches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/reconcile.Mismatch"
+                    }
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "triggered_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.renewAccessTokenRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.renewAccessTokenResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "access_token_expires_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.reverseTransferRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.sessionResponse": {
+            "type": "object",
+            "properties": {
+                "client_ip": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_blocked": {
+                    "type": "boolean"
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.transferResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Must be positive value",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Free text memo, copied onto both entries",
+                    "type": "string"
+                },
+                "exchange_rate": {
+                    "type": "string"
+                },
+                "external_reference": {
+                    "description": "Reference of the payment in the client's own system",
                     "type": "string"
                 },
                 "from_account_id": {
-                    "type": "integer",
-                    "minimum": 1
+                    "type": "integer"
+                },
+                "hold": {
+                    "description": "Hold is set for transfers that were created pending",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/db.Hold"
+                        }
+                    ]
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "metadata": {
+                    "description": "Client defined JSON object, at most 4 KB",
+                    "type": "object"
+                },
+                "reversal_of": {
+                    "description": "Transfer this one reverses, null for regular transfers",
+                    "type": "integer"
+                },
+                "reversals": {
+                    "description": "Reversals are the transfers that moved money back, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.Transfer"
+                    }
+                },
+                "reversed_amount": {
+                    "type": "integer"
+                },
+                "spread_bps": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "pending while the money is held, posted once it moved, voided or expired when the hold was released",
+                    "type": "string"
                 },
                 "to_account_id": {
+                    "type": "integer"
+                },
+                "to_amount": {
+                    "description": "Amount credited in the currency of the receiving account",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.updateCurrencyRequest": {
+            "type": "object",
+            "required": [
+                "enabled"
+            ],
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.updateOverdraftLimitRequest": {
+            "type": "object",
+            "required": [
+                "overdraft_limit"
+            ],
+            "properties": {
+                "overdraft_limit": {
                     "type": "integer",
-                    "minimum": 1
+                    "minimum": 0
+                }
+            }
+        },
+        "api.updateScheduledTransferRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "end_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "paused"
+                    ]
+                }
+            }
+        },
+        "api.updateUserRoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.upsertExchangeRateRequest": {
+            "type": "object",
+            "required": [
+                "from_currency",
+                "rate",
+                "to_currency"
+            ],
+            "properties": {
+                "from_currency": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "string"
+                },
+                "spread_bps": {
+                    "type": "integer",
+                    "maximum": 9999,
+                    "minimum": 0
+                },
+                "to_currency": {
+                    "type": "string"
                 }
             }
         },
-        "api.createUserRequest": {
+        "api.userResponse": {
             "type": "object",
-            "required": [
-                "email",
-                "full_name",
-                "password",
-                "password_again",
-                "username"
-            ],
             "properties": {
+                "created_at": {
+                    "type": "string"
+                },
                 "email": {
                     "type": "string"
                 },
                 "full_name": {
                     "type": "string"
                 },
-                "password": {
-                    "type": "string",
-                    "minLength": 6
+                "password_changed_at": {
+                    "type": "string"
                 },
-                "password_again": {
+                "role": {
                     "type": "string"
                 },
                 "username": {
@@ -426,30 +2583,43 @@ const docTemplate = `{
                 }
             }
         },
-        "api.createUserResponse": {
+        "api.webhookResponse": {
             "type": "object",
             "properties": {
-                "email": {
+                "created_at": {
                     "type": "string"
                 },
-                "full_name": {
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
                     "type": "string"
                 },
-                "username": {
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "api.depositRequest": {
+        "api.withdrawRequest": {
             "type": "object",
             "required": [
                 "amount",
+                "currency",
                 "id"
             ],
             "properties": {
                 "amount": {
                     "type": "integer"
                 },
+                "currency": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer",
                     "minimum": 1
@@ -459,6 +2629,10 @@ const docTemplate = `{
         "db.Account": {
             "type": "object",
             "properties": {
+                "available_balance": {
+                    "description": "Balance that is not held by a pending transfer",
+                    "type": "integer"
+                },
                 "balance": {
                     "type": "integer"
                 },
@@ -468,11 +2642,56 @@ const docTemplate = `{
                 "currency": {
                     "type": "string"
                 },
+                "held_balance": {
+                    "description": "Sum of the active holds on the account",
+                    "type": "integer"
+                },
                 "id": {
                     "type": "integer"
                 },
                 "name": {
                     "type": "string"
+                },
+                "overdraft_limit": {
+                    "description": "How far below zero the balance may go",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "active, frozen (no money can move) or closed (final)",
+                    "type": "string"
+                }
+            }
+        },
+        "db.Currency": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "ISO 4217 alphabetic code",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "exponent": {
+                    "description": "Number of minor unit digits, amounts are stored in minor units",
+                    "type": "integer"
+                },
+                "numeric_code": {
+                    "type": "integer"
+                }
+            }
+        },
+        "db.DepositTxResult": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "$ref": "#/definitions/db.Account"
+                },
+                "entry": {
+                    "$ref": "#/definitions/db.Entry"
                 }
             }
         },
@@ -489,8 +2708,168 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "transfer_id": {
+                    "description": "Transfer that wrote the entry, null for deposits and withdrawals",
+                    "type": "integer"
+                }
+            }
+        },
+        "db.ExchangeRate": {
+            "type": "object",
+            "properties": {
+                "from_currency": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "string"
+                },
+                "spread_bps": {
+                    "description": "Spread taken from the converted amount in basis points",
+                    "type": "integer"
+                },
+                "to_currency": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "db.Hold": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "transfer_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "db.ReverseTransferTxResult": {
+            "type": "object",
+            "properties": {
+                "from_account": {
+                    "$ref": "#/definitions/db.Account"
+                },
+                "from_entry": {
+                    "$ref": "#/definitions/db.Entry"
+                },
+                "hold": {
+                    "description": "Hold is set for pending transfers, which have no entries yet",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/db.Hold"
+                        }
+                    ]
+                },
+                "original": {
+                    "$ref": "#/definitions/db.Transfer"
+                },
+                "to_account": {
+                    "$ref": "#/definitions/db.Account"
+                },
+                "to_entry": {
+                    "$ref": "#/definitions/db.Entry"
+                },
+                "transfer": {
+                    "$ref": "#/definitions/db.Transfer"
+                }
+            }
+        },
+        "db.ScheduledTransfer": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "end_at": {
+                    "type": "string"
+                },
+                "from_account_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "interval_count": {
+                    "type": "integer"
+                },
+                "interval_unit": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "occurrence": {
+                    "description": "Index of next_run_at in the schedule, counted from start_at",
+                    "type": "integer"
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "start_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "to_account_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "db.ScheduledTransferRun": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
+                },
+                "scheduled_for": {
+                    "type": "string"
+                },
+                "scheduled_transfer_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "transfer_id": {
+                    "description": "Transfer made by the run, null when it failed",
+                    "type": "integer"
                 }
             }
         },
@@ -504,17 +2883,173 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "description": {
+                    "description": "Free text memo, copied onto both entries",
+                    "type": "string"
+                },
+                "exchange_rate": {
+                    "type": "string"
+                },
+                "external_reference": {
+                    "description": "Reference of the payment in the client's own system",
+                    "type": "string"
+                },
                 "from_account_id": {
                     "type": "integer"
                 },
                 "id": {
                     "type": "integer"
                 },
+                "metadata": {
+                    "description": "Client defined JSON object, at most 4 KB",
+                    "type": "object"
+                },
+                "reversal_of": {
+                    "description": "Transfer this one reverses, null for regular transfers",
+                    "type": "integer"
+                },
+                "spread_bps": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "pending while the money is held, posted once it moved, voided or expired when the hold was released",
+                    "type": "string"
+                },
                 "to_account_id": {
                     "type": "integer"
+                },
+                "to_amount": {
+                    "description": "Amount credited in the currency of the receiving account",
+                    "type": "integer"
+                }
+            }
+        },
+        "db.TransferTxResult": {
+            "type": "object",
+            "properties": {
+                "from_account": {
+                    "$ref": "#/definitions/db.Account"
+                },
+                "from_entry": {
+                    "$ref": "#/definitions/db.Entry"
+                },
+                "hold": {
+                    "description": "Hold is set for pending transfers, which have no entries yet",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/db.Hold"
+                        }
+                    ]
+                },
+                "to_account": {
+                    "$ref": "#/definitions/db.Account"
+                },
+                "to_entry": {
+                    "$ref": "#/definitions/db.Entry"
+                },
+                "transfer": {
+                    "$ref": "#/definitions/db.Transfer"
+                }
+            }
+        },
+        "db.VoidTransferTxResult": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "$ref": "#/definitions/db.Account"
+                },
+                "hold": {
+                    "$ref": "#/definitions/db.Hold"
+                },
+                "transfer": {
+                    "$ref": "#/definitions/db.Transfer"
+                }
+            }
+        },
+        "db.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "description": "Same for every delivery of one event so receivers can drop duplicates",
+                    "type": "string"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "response_status": {
+                    "description": "HTTP status of the last attempt, 0 when no response was received",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "pending until the receiver answers 2xx, dead once every attempt failed",
+                    "type": "string"
+                },
+                "subscription_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "db.WithdrawTxResult": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "$ref": "#/definitions/db.Account"
+                },
+                "entry": {
+                    "$ref": "#/definitions/db.Entry"
+                }
+            }
+        },
+        "reconcile.Mismatch": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "integer"
+                },
+                "adjustment_entry_id": {
+                    "description": "AdjustmentEntryID is the entry written to close the gap, only set\nwhen the run was asked to adjust",
+                    "type": "integer"
+                },
+                "balance": {
+                    "type": "integer"
+                },
+                "delta": {
+                    "type": "integer"
+                },
+                "entries_total": {
+                    "type": "integer"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Type \"Bearer\" followed by a space and the access token",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
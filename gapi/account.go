@@ -0,0 +1,200 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/banking"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/pb"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateAccount opens an account for the authenticated user
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	var v violations
+	v.check("currency", server.field(req.GetCurrency(), "required,currency"))
+	if len(v) > 0 {
+		return nil, invalidArgumentError(v)
+	}
+
+	account, err := server.store.CreateAccount(ctx, db.CreateAccountParams{
+		Name:     authPayload.Username,
+		Currency: req.GetCurrency(),
+		Balance:  0,
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	return &pb.CreateAccountResponse{Account: server.convertAccount(account)}, nil
+}
+
+func (server *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	var v violations
+	v.check("id", server.field(req.GetId(), "required,min=1"))
+	if len(v) > 0 {
+		return nil, invalidArgumentError(v)
+	}
+
+	account, err := server.ownedAccount(ctx, authPayload, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetAccountResponse{Account: server.convertAccount(account)}, nil
+}
+
+// ListAccounts pages through the authenticated user's accounts oldest first
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	var v violations
+	v.check("cursor", validateCursor(req.GetCursor()))
+	v.check("size", server.field(req.GetSize(), "required,min=1,max=100"))
+	if len(v) > 0 {
+		return nil, invalidArgumentError(v)
+	}
+
+	cursor, _ := util.DecodeCursor(req.GetCursor())
+	accounts, err := server.store.GetAccounts(ctx, db.GetAccountsParams{
+		Name:           authPayload.Username,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+		Size:           req.GetSize() + 1,
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.ListAccountsResponse{}
+	if len(accounts) > int(req.GetSize()) {
+		accounts = accounts[:req.GetSize()]
+		last := accounts[len(accounts)-1]
+		rsp.NextCursor = *util.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	rsp.Accounts = make([]*pb.Account, len(accounts))
+	for i, account := range accounts {
+		rsp.Accounts[i] = server.convertAccount(account)
+	}
+
+	return rsp, nil
+}
+
+func (server *Server) Deposit(ctx context.Context, req *pb.DepositRequest) (*pb.DepositResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if err := server.validateMoneyMovement(req.GetAccountId(), req.GetAmount(), req.GetCurrency()); err != nil {
+		return nil, err
+	}
+
+	if _, err := server.ownedAccountIn(ctx, authPayload, req.GetAccountId(), req.GetCurrency()); err != nil {
+		return nil, err
+	}
+
+	result, err := server.store.DepositTx(ctx, db.DepositTxParams{
+		AccountID: req.GetAccountId(),
+		Amount:    req.GetAmount(),
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	return &pb.DepositResponse{
+		Account: server.convertAccount(result.Account),
+		Entry:   server.convertEntry(result.Entry, result.Account.Currency),
+	}, nil
+}
+
+func (server *Server) Withdraw(ctx context.Context, req *pb.WithdrawRequest) (*pb.WithdrawResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if err := server.validateMoneyMovement(req.GetAccountId(), req.GetAmount(), req.GetCurrency()); err != nil {
+		return nil, err
+	}
+
+	if _, err := server.ownedAccountIn(ctx, authPayload, req.GetAccountId(), req.GetCurrency()); err != nil {
+		return nil, err
+	}
+
+	result, err := server.store.WithdrawTx(ctx, db.WithdrawTxParams{
+		AccountID: req.GetAccountId(),
+		Amount:    req.GetAmount(),
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	return &pb.WithdrawResponse{
+		Account: server.convertAccount(result.Account),
+		Entry:   server.convertEntry(result.Entry, result.Account.Currency),
+	}, nil
+}
+
+// validateMoneyMovement checks the fields shared by deposits and withdrawals
+func (server *Server) validateMoneyMovement(accountID, amount int64, currency string) error {
+	var v violations
+	v.check("account_id", server.field(accountID, "required,min=1"))
+	v.check("amount", server.field(amount, "required,gt=0"))
+	v.check("currency", server.field(currency, "required,currency"))
+	if len(v) > 0 {
+		return invalidArgumentError(v)
+	}
+	return nil
+}
+
+// ownedAccount loads the account and checks that it belongs to the
+// authenticated user
+func (server *Server) ownedAccount(ctx context.Context, authPayload *token.Payload, accountID int64) (db.Account, error) {
+	account, err := banking.OwnedAccount(ctx, server.store, authPayload.Username, accountID)
+	if err != nil {
+		return account, storeError(err)
+	}
+
+	return account, nil
+}
+
+// ownedAccountIn is ownedAccount for an account that must use currency
+func (server *Server) ownedAccountIn(ctx context.Context, authPayload *token.Payload, accountID int64, currency string) (db.Account, error) {
+	account, err := server.ownedAccount(ctx, authPayload, accountID)
+	if err != nil {
+		return account, err
+	}
+
+	if account.Currency != currency {
+		return account, status.Errorf(codes.InvalidArgument, "account %d has different currency", accountID)
+	}
+
+	return account, nil
+}
+
+// existingAccount loads an account of any user
+func (server *Server) existingAccount(ctx context.Context, accountID int64) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return account, storeError(err)
+	}
+	return account, nil
+}
@@ -0,0 +1,84 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	authorizationHeader = "authorization"
+	authorizationBearer = "bearer"
+	requestIDHeader     = "x-request-id"
+	maxRequestIDLength  = 128
+)
+
+// authorizeUser verifies the bearer access token in the authorization
+// metadata, the same token the HTTP API takes, refresh tokens are rejected
+func (server *Server) authorizeUser(ctx context.Context) (*token.Payload, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("missing metadata")
+	}
+
+	values := md.Get(authorizationHeader)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("authorization header is not provided")
+	}
+
+	fields := strings.Fields(values[0])
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid authorization header format")
+	}
+
+	authorizationType := strings.ToLower(fields[0])
+	if authorizationType != authorizationBearer {
+		return nil, fmt.Errorf("unsupported authorization type %s", authorizationType)
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(fields[1])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := payload.CheckType(token.TokenTypeAccess); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// auditInterceptor tags the call context with the caller and the request id
+// so the store transactions record their changes as theirs and log with the
+// request id. Calls without a valid token are recorded as anonymous, the
+// handlers still reject them
+func (server *Server) auditInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	audit := db.AuditContext{Actor: db.AuditActorAnonymous}
+	if payload, err := server.authorizeUser(ctx); err == nil {
+		audit.Actor = payload.Username
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDHeader); len(values) > 0 && len(values[0]) <= maxRequestIDLength {
+			audit.RequestID = values[0]
+		}
+	}
+	if audit.RequestID == "" {
+		audit.RequestID = uuid.NewString()
+	}
+
+	ctx = db.WithLogger(ctx, slog.Default().With("request_id", audit.RequestID))
+	return handler(db.WithAuditContext(ctx, audit), req)
+}
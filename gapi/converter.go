@@ -0,0 +1,89 @@
+package gapi
+
+import (
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func convertUser(user db.User) *pb.User {
+	return &pb.User{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
+		CreatedAt:         timestamppb.New(user.CreatedAt),
+		Role:              user.Role,
+	}
+}
+
+func (server *Server) convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:                        account.ID,
+		Name:                      account.Name,
+		Balance:                   account.Balance,
+		Currency:                  account.Currency,
+		CreatedAt:                 timestamppb.New(account.CreatedAt),
+		OverdraftLimit:            account.OverdraftLimit,
+		Status:                    account.Status,
+		HeldBalance:               account.HeldBalance,
+		AvailableBalance:          account.AvailableBalance,
+		FormattedBalance:          server.currencies.Format(account.Balance, account.Currency),
+		FormattedAvailableBalance: server.currencies.Format(account.AvailableBalance, account.Currency),
+	}
+}
+
+// convertEntry formats the amount with the currency of the entry's account
+func (server *Server) convertEntry(entry db.Entry, currency string) *pb.Entry {
+	return &pb.Entry{
+		Id:              entry.ID,
+		AccountId:       entry.AccountID,
+		Amount:          entry.Amount,
+		CreatedAt:       timestamppb.New(entry.CreatedAt),
+		TransferId:      entry.TransferID,
+		Description:     entry.Description,
+		FormattedAmount: server.currencies.Format(entry.Amount, currency),
+	}
+}
+
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	return &pb.Transfer{
+		Id:                transfer.ID,
+		FromAccountId:     transfer.FromAccountID,
+		ToAccountId:       transfer.ToAccountID,
+		Amount:            transfer.Amount,
+		CreatedAt:         timestamppb.New(transfer.CreatedAt),
+		ToAmount:          transfer.ToAmount,
+		ExchangeRate:      transfer.ExchangeRate,
+		SpreadBps:         transfer.SpreadBps,
+		ReversalOf:        transfer.ReversalOf,
+		Status:            transfer.Status,
+		Description:       transfer.Description,
+		ExternalReference: transfer.ExternalReference,
+		Metadata:          string(transfer.Metadata),
+	}
+}
+
+func convertTransfers(transfers []db.Transfer) []*pb.Transfer {
+	result := make([]*pb.Transfer, len(transfers))
+	for i, transfer := range transfers {
+		result[i] = convertTransfer(transfer)
+	}
+	return result
+}
+
+func convertHold(hold *db.Hold) *pb.Hold {
+	if hold == nil {
+		return nil
+	}
+
+	return &pb.Hold{
+		Id:         hold.ID,
+		TransferId: hold.TransferID,
+		AccountId:  hold.AccountID,
+		Amount:     hold.Amount,
+		Status:     hold.Status,
+		ExpiresAt:  timestamppb.New(hold.ExpiresAt),
+		CreatedAt:  timestamppb.New(hold.CreatedAt),
+	}
+}
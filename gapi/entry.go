@@ -0,0 +1,71 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/pb"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+)
+
+// ListEntries pages through the entries of an account of the authenticated
+// user oldest first, with the filters of the HTTP API
+func (server *Server) ListEntries(ctx context.Context, req *pb.ListEntriesRequest) (*pb.ListEntriesResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	from, to := optionalTime(req.From), optionalTime(req.To)
+
+	var v violations
+	v.check("account_id", server.field(req.GetAccountId(), "required,min=1"))
+	v.check("cursor", validateCursor(req.GetCursor()))
+	v.check("size", server.field(req.GetSize(), "required,min=1,max=100"))
+	v.check("min_amount", server.field(req.MinAmount, "omitempty,min=1"))
+	v.check("max_amount", server.field(req.MaxAmount, "omitempty,min=1"))
+	v.check("direction", server.field(req.GetDirection(), "omitempty,oneof=credit debit"))
+	v.check("counterparty_id", server.fieldNotEqual(req.CounterpartyId, req.GetAccountId(), "omitempty,min=1,nefield"))
+	if err := util.ValidateRange(from, to, req.MinAmount, req.MaxAmount); err != nil {
+		v.check("from", err)
+	}
+	if len(v) > 0 {
+		return nil, invalidArgumentError(v)
+	}
+
+	account, err := server.ownedAccount(ctx, authPayload, req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, _ := util.DecodeCursor(req.GetCursor())
+	entries, err := server.store.ListEntries(ctx, db.ListEntriesParams{
+		AccountID:      req.GetAccountId(),
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+		FromTime:       util.NullTime(from),
+		ToTime:         util.NullTime(to),
+		MinAmount:      util.NullInt64(req.MinAmount),
+		MaxAmount:      util.NullInt64(req.MaxAmount),
+		Direction:      util.NullString(req.GetDirection()),
+		CounterpartyID: util.NullInt64(req.CounterpartyId),
+		Size:           req.GetSize() + 1,
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.ListEntriesResponse{}
+	if len(entries) > int(req.GetSize()) {
+		entries = entries[:req.GetSize()]
+		last := entries[len(entries)-1]
+		rsp.NextCursor = *util.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	rsp.Entries = make([]*pb.Entry, len(entries))
+	for i, entry := range entries {
+		rsp.Entries[i] = server.convertEntry(entry, account.Currency)
+	}
+
+	return rsp, nil
+}
@@ -0,0 +1,129 @@
+package gapi
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/dbtest"
+	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/pb"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestListEntriesRPC(t *testing.T) {
+	user, _ := dbtest.RandomUser(t)
+	account := dbtest.RandomAccount(user.Username)
+	other := dbtest.RandomAccount("other")
+
+	n := 3
+	entries := make([]db.Entry, n)
+	for i := range entries {
+		entries[i] = db.Entry{
+			ID:        int64(i + 1),
+			AccountID: account.ID,
+			Amount:    util.RandomAmount(),
+			CreatedAt: time.Now().Add(time.Duration(i) * time.Second),
+		}
+	}
+
+	from := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	testCases := []struct {
+		name          string
+		req           *pb.ListEntriesRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.ListEntriesResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.ListEntriesRequest{
+				AccountId: account.ID,
+				Size:      int32(n - 1),
+				From:      timestamppb.New(from),
+				To:        timestamppb.New(to),
+				Direction: "credit",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				arg := db.ListEntriesParams{
+					AccountID: account.ID,
+					FromTime:  sql.NullTime{Time: from, Valid: true},
+					ToTime:    sql.NullTime{Time: to, Valid: true},
+					Direction: sql.NullString{String: "credit", Valid: true},
+					Size:      int32(n),
+				}
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Eq(arg)).Times(1).Return(entries, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListEntriesResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, res.Entries, n-1)
+				require.Equal(t, util.FormatAmount(entries[0].Amount, 2), res.Entries[0].FormattedAmount)
+
+				last := entries[n-2]
+				require.Equal(t, *util.EncodeCursor(last.CreatedAt, last.ID), res.NextCursor)
+			},
+		},
+		{
+			name: "PermissionDenied",
+			req:  &pb.ListEntriesRequest{AccountId: other.ID, Size: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(other.ID)).Times(1).Return(other, nil)
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListEntriesResponse, err error) {
+				requireCode(t, err, codes.PermissionDenied)
+			},
+		},
+		{
+			name: "InvalidRange",
+			req: &pb.ListEntriesRequest{
+				AccountId: account.ID,
+				Size:      5,
+				From:      timestamppb.New(to),
+				To:        timestamppb.New(from),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListEntriesResponse, err error) {
+				requireViolation(t, err, "from")
+			},
+		},
+		{
+			name: "InternalError",
+			req:  &pb.ListEntriesRequest{AccountId: account.ID, Size: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Any()).Times(1).Return([]db.Entry{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListEntriesResponse, err error) {
+				requireCode(t, err, codes.Internal)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username)
+			res, err := server.ListEntries(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package gapi
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/banking"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/lib/pq"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error codes sent as the ErrorInfo reason of FAILED_PRECONDITION errors,
+// the same codes the HTTP API returns with 422
+const (
+	errCodeInsufficientFunds       = "insufficient_funds"
+	errCodeConversionTooSmall      = "conversion_too_small"
+	errCodeAccountFrozen           = "account_frozen"
+	errCodeAccountClosed           = "account_closed"
+	errCodeExchangeRateUnavailable = "exchange_rate_unavailable"
+	errCodeQuoteExpired            = "quote_expired"
+)
+
+func fieldViolation(field string, err error) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: err.Error(),
+	}
+}
+
+func invalidArgumentError(violations []*errdetails.BadRequest_FieldViolation) error {
+	badRequest := &errdetails.BadRequest{FieldViolations: violations}
+	statusInvalid := status.New(codes.InvalidArgument, "invalid parameters")
+
+	statusDetails, err := statusInvalid.WithDetails(badRequest)
+	if err != nil {
+		return statusInvalid.Err()
+	}
+
+	return statusDetails.Err()
+}
+
+func unauthenticatedError(err error) error {
+	return status.Errorf(codes.Unauthenticated, "unauthorized: %s", err)
+}
+
+// failedPreconditionError carries a stable code next to the message so
+// clients can branch on the failure without parsing the text
+func failedPreconditionError(err error, code string) error {
+	statusFailed := status.New(codes.FailedPrecondition, err.Error())
+
+	statusDetails, detailsErr := statusFailed.WithDetails(&errdetails.ErrorInfo{Reason: code})
+	if detailsErr != nil {
+		return statusFailed.Err()
+	}
+
+	return statusDetails.Err()
+}
+
+// storeError turns the errors of the store and of the banking checks into
+// statuses, what is not a missing row, a duplicate, a denied access or a
+// rejected money movement is internal
+func storeError(err error) error {
+	if err == sql.ErrNoRows {
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+		return status.Error(codes.AlreadyExists, err.Error())
+	}
+
+	switch {
+	case errors.Is(err, banking.ErrAccountNotOwned),
+		errors.Is(err, banking.ErrTransferNotOwned),
+		errors.Is(err, banking.ErrQuoteNotOwned):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, banking.ErrQuoteCurrencies):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, banking.ErrQuoteExpired):
+		return failedPreconditionError(err, errCodeQuoteExpired)
+	case errors.Is(err, banking.ErrExchangeRateUnavailable):
+		return failedPreconditionError(err, errCodeExchangeRateUnavailable)
+	case errors.Is(err, db.ErrInsufficientFunds):
+		return failedPreconditionError(err, errCodeInsufficientFunds)
+	case errors.Is(err, db.ErrConversionTooSmall):
+		return failedPreconditionError(err, errCodeConversionTooSmall)
+	case errors.Is(err, db.ErrAccountFrozen):
+		return failedPreconditionError(err, errCodeAccountFrozen)
+	case errors.Is(err, db.ErrAccountClosed):
+		return failedPreconditionError(err, errCodeAccountClosed)
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,75 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/dbtest"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := util.Config{
+		TokenSymmetricKey:    util.RandomString(32),
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+		HoldTTL:              time.Hour,
+	}
+
+	server, err := NewServer(config, store, dbtest.NewCurrencyRegistry())
+	require.NoError(t, err)
+
+	return server
+}
+
+func newContextWithBearerToken(t *testing.T, tokenMaker token.Maker, username string) context.Context {
+	accessToken, _, err := tokenMaker.CreateToken(username, util.CustomerRole, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	md := metadata.MD{
+		authorizationHeader: []string{fmt.Sprintf("%s %s", authorizationBearer, accessToken)},
+	}
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func requireCode(t *testing.T, err error, code codes.Code) {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, code, st.Code(), st.Message())
+}
+
+// requireReason checks the ErrorInfo reason of a FAILED_PRECONDITION error
+func requireReason(t *testing.T, err error, reason string) {
+	requireCode(t, err, codes.FailedPrecondition)
+
+	st, _ := status.FromError(err)
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	require.Equal(t, reason, info.Reason)
+}
+
+// requireViolation checks that an INVALID_ARGUMENT error names the field
+func requireViolation(t *testing.T, err error, field string) {
+	requireCode(t, err, codes.InvalidArgument)
+
+	st, _ := status.FromError(err)
+	require.Len(t, st.Details(), 1)
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+
+	fields := make([]string, len(badRequest.FieldViolations))
+	for i, violation := range badRequest.FieldViolations {
+		fields[i] = violation.Field
+	}
+	require.Contains(t, fields, field)
+}
@@ -0,0 +1,72 @@
+// Package gapi serves the gRPC API, the same users, accounts, transfers and
+// entries as the HTTP API on top of the same store
+package gapi
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/banking"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/pb"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+type Server struct {
+	pb.UnimplementedUserServiceServer
+	pb.UnimplementedAccountServiceServer
+	pb.UnimplementedTransferServiceServer
+	pb.UnimplementedEntryServiceServer
+
+	config     util.Config
+	store      db.Store
+	tokenMaker token.Maker
+	currencies *util.CurrencyRegistry
+	validate   *validator.Validate
+}
+
+// NewServer serves store, currencies is the registry the HTTP API uses so an
+// admin change made there is seen here too
+func NewServer(config util.Config, store db.Store, currencies *util.CurrencyRegistry) (*Server, error) {
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	server := &Server{
+		config:     config,
+		store:      store,
+		tokenMaker: tokenMaker,
+		currencies: currencies,
+		validate:   banking.NewValidator(currencies),
+	}
+
+	return server, nil
+}
+
+// Register adds every service to the gRPC server
+func (server *Server) Register(grpcServer *grpc.Server) {
+	pb.RegisterUserServiceServer(grpcServer, server)
+	pb.RegisterAccountServiceServer(grpcServer, server)
+	pb.RegisterTransferServiceServer(grpcServer, server)
+	pb.RegisterEntryServiceServer(grpcServer, server)
+}
+
+// Start serves the gRPC API on address, reflection is enabled so tools like
+// grpcurl can list the services
+func (server *Server) Start(address string) error {
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(server.auditInterceptor))
+	server.Register(grpcServer)
+	reflection.Register(grpcServer)
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %w", address, err)
+	}
+
+	return grpcServer.Serve(listener)
+}
@@ -0,0 +1,104 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/dbtest"
+	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/pb"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerOverGRPC(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user, _ := dbtest.RandomUser(t)
+	account := dbtest.RandomAccount(user.Username)
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+	server := newTestServer(t, store)
+	grpcServer := grpc.NewServer()
+	server.Register(grpcServer)
+
+	listener := bufconn.Listen(1024 * 1024)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := pb.NewAccountServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// without a token the call never reaches the store
+	_, err = client.GetAccount(ctx, &pb.GetAccountRequest{Id: account.ID})
+	requireCode(t, err, codes.Unauthenticated)
+
+	// a refresh token only renews its session
+	refreshToken, _, err := server.tokenMaker.CreateToken(user.Username, user.Role, token.TokenTypeRefresh, time.Hour)
+	require.NoError(t, err)
+	refreshCtx := metadata.AppendToOutgoingContext(ctx, authorizationHeader, fmt.Sprintf("Bearer %s", refreshToken))
+	_, err = client.GetAccount(refreshCtx, &pb.GetAccountRequest{Id: account.ID})
+	requireCode(t, err, codes.Unauthenticated)
+
+	accessToken, _, err := server.tokenMaker.CreateToken(user.Username, user.Role, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	ctx = metadata.AppendToOutgoingContext(ctx, authorizationHeader, fmt.Sprintf("Bearer %s", accessToken))
+
+	res, err := client.GetAccount(ctx, &pb.GetAccountRequest{Id: account.ID})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, res.Account.Id)
+	require.Equal(t, user.Username, res.Account.Name)
+}
+
+func TestAuditInterceptor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+
+	accessToken, _, err := server.tokenMaker.CreateToken("alice", "customer", token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+
+	var seen db.AuditContext
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		seen = db.AuditContextFrom(ctx)
+		return nil, nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		authorizationHeader, fmt.Sprintf("Bearer %s", accessToken),
+		requestIDHeader, "req-1",
+	))
+	_, err = server.auditInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	require.Equal(t, db.AuditContext{Actor: "alice", RequestID: "req-1"}, seen)
+
+	// without a token or request id the call is anonymous and gets an id
+	_, err = server.auditInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	require.Equal(t, db.AuditActorAnonymous, seen.Actor)
+	require.Len(t, seen.RequestID, 36)
+}
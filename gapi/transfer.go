@@ -0,0 +1,228 @@
+package gapi
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/banking"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/pb"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateTransfer moves money from an account of the authenticated user,
+// converting it like the HTTP API when the receiver uses another currency
+func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	var metadata json.RawMessage
+	if req.GetMetadata() != "" {
+		metadata = json.RawMessage(req.GetMetadata())
+	}
+
+	var v violations
+	v.check("from_account_id", server.field(req.GetFromAccountId(), "required,min=1"))
+	v.check("to_account_id", server.field(req.GetToAccountId(), "required,min=1"))
+	v.check("amount", server.field(req.GetAmount(), "required,gt=0"))
+	v.check("currency", server.field(req.GetCurrency(), "required,currency"))
+	v.check("quote_id", server.field(req.GetQuoteId(), "omitempty,uuid"))
+	v.check("description", server.field(req.GetDescription(), "max=255"))
+	v.check("external_reference", server.field(req.GetExternalReference(), "max=64"))
+	v.check("metadata", util.ValidateMetadata(metadata))
+	if len(v) > 0 {
+		return nil, invalidArgumentError(v)
+	}
+
+	var holdExpiresAt *time.Time
+	if req.GetPending() {
+		expiresAt := time.Now().Add(server.config.HoldTTL)
+		holdExpiresAt = &expiresAt
+	}
+
+	fromAccount, err := server.existingAccount(ctx, req.GetFromAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Currency != req.GetCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "account %d has different currency", fromAccount.ID)
+	}
+
+	if fromAccount.Name != authPayload.Username {
+		return nil, storeError(banking.ErrAccountNotOwned)
+	}
+
+	toAccount, err := server.existingAccount(ctx, req.GetToAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	var result db.TransferTxResult
+	if toAccount.Currency == req.GetCurrency() && req.GetQuoteId() == "" {
+		result, err = server.store.TransferTx(ctx, db.TransferTxParams{
+			FromAccountID:     req.GetFromAccountId(),
+			ToAccountID:       req.GetToAccountId(),
+			Amount:            req.GetAmount(),
+			HoldExpiresAt:     holdExpiresAt,
+			Description:       req.GetDescription(),
+			ExternalReference: req.GetExternalReference(),
+			Metadata:          metadata,
+		})
+	} else {
+		arg := db.FXTransferTxParams{
+			FromAccountID:     req.GetFromAccountId(),
+			ToAccountID:       req.GetToAccountId(),
+			Amount:            req.GetAmount(),
+			HoldExpiresAt:     holdExpiresAt,
+			Description:       req.GetDescription(),
+			ExternalReference: req.GetExternalReference(),
+			Metadata:          metadata,
+		}
+
+		if req.GetQuoteId() != "" {
+			quote, err := banking.QuotedExchangeRate(ctx, server.store, authPayload.Username, uuid.MustParse(req.GetQuoteId()), req.GetCurrency(), toAccount.Currency)
+			if err != nil {
+				return nil, storeError(err)
+			}
+			arg.Rate, arg.SpreadBps = quote.Rate, quote.SpreadBps
+		} else {
+			rate, err := banking.CurrentExchangeRate(ctx, server.store, req.GetCurrency(), toAccount.Currency)
+			if err != nil {
+				return nil, storeError(err)
+			}
+			arg.Rate, arg.SpreadBps = rate.Rate, rate.SpreadBps
+		}
+
+		result, err = server.store.FXTransferTx(ctx, arg)
+	}
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: server.convertAccount(result.FromAccount),
+		ToAccount:   server.convertAccount(result.ToAccount),
+		Hold:        convertHold(result.Hold),
+	}
+	// pending transfers write their entries when they are captured
+	if result.Hold == nil {
+		rsp.FromEntry = server.convertEntry(result.FromEntry, result.FromAccount.Currency)
+		rsp.ToEntry = server.convertEntry(result.ToEntry, result.ToAccount.Currency)
+	}
+
+	return rsp, nil
+}
+
+// GetTransfer returns a transfer of the authenticated user with its
+// reversals and, for transfers created pending, its hold
+func (server *Server) GetTransfer(ctx context.Context, req *pb.GetTransferRequest) (*pb.GetTransferResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	var v violations
+	v.check("id", server.field(req.GetId(), "required,min=1"))
+	if len(v) > 0 {
+		return nil, invalidArgumentError(v)
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, req.GetId())
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	if err := banking.TransferVisible(ctx, server.store, authPayload.Username, transfer); err != nil {
+		return nil, storeError(err)
+	}
+
+	reversals, err := server.store.ListTransferReversals(ctx, &transfer.ID)
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.GetTransferResponse{
+		Transfer:  convertTransfer(transfer),
+		Reversals: convertTransfers(reversals),
+	}
+	for _, reversal := range reversals {
+		rsp.ReversedAmount += reversal.ToAmount
+	}
+
+	if transfer.Status != db.TransferStatusPosted {
+		hold, err := server.store.GetHoldByTransfer(ctx, transfer.ID)
+		if err != nil {
+			return nil, storeError(err)
+		}
+		rsp.Hold = convertHold(&hold)
+	}
+
+	return rsp, nil
+}
+
+// ListTransfers pages through the transfers of an account of the
+// authenticated user oldest first, with the filters of the HTTP API
+func (server *Server) ListTransfers(ctx context.Context, req *pb.ListTransfersRequest) (*pb.ListTransfersResponse, error) {
+	authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	from, to := optionalTime(req.From), optionalTime(req.To)
+
+	var v violations
+	v.check("account_id", server.field(req.GetAccountId(), "required,min=1"))
+	v.check("cursor", validateCursor(req.GetCursor()))
+	v.check("size", server.field(req.GetSize(), "required,min=1,max=100"))
+	v.check("min_amount", server.field(req.MinAmount, "omitempty,min=1"))
+	v.check("max_amount", server.field(req.MaxAmount, "omitempty,min=1"))
+	v.check("direction", server.field(req.GetDirection(), "omitempty,oneof=incoming outgoing"))
+	v.check("counterparty_id", server.fieldNotEqual(req.CounterpartyId, req.GetAccountId(), "omitempty,min=1,nefield"))
+	v.check("reference", server.field(req.GetReference(), "max=64"))
+	if err := util.ValidateRange(from, to, req.MinAmount, req.MaxAmount); err != nil {
+		v.check("from", err)
+	}
+	if len(v) > 0 {
+		return nil, invalidArgumentError(v)
+	}
+
+	if _, err := server.ownedAccount(ctx, authPayload, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	cursor, _ := util.DecodeCursor(req.GetCursor())
+	transfers, err := server.store.ListTransfers(ctx, db.ListTransfersParams{
+		AccountID:      req.GetAccountId(),
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+		FromTime:       util.NullTime(from),
+		ToTime:         util.NullTime(to),
+		MinAmount:      util.NullInt64(req.MinAmount),
+		MaxAmount:      util.NullInt64(req.MaxAmount),
+		Direction:      util.NullString(req.GetDirection()),
+		CounterpartyID: util.NullInt64(req.CounterpartyId),
+		Reference:      util.NullString(req.GetReference()),
+		Size:           req.GetSize() + 1,
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.ListTransfersResponse{}
+	if len(transfers) > int(req.GetSize()) {
+		transfers = transfers[:req.GetSize()]
+		last := transfers[len(transfers)-1]
+		rsp.NextCursor = *util.EncodeCursor(last.CreatedAt, last.ID)
+	}
+	rsp.Transfers = convertTransfers(transfers)
+
+	return rsp, nil
+}
@@ -0,0 +1,452 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/dbtest"
+	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/pb"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestCreateTransferRPC(t *testing.T) {
+	user1, _ := dbtest.RandomUser(t)
+	user2, _ := dbtest.RandomUser(t)
+
+	account1 := dbtest.RandomAccount(user1.Username)
+	account2 := dbtest.RandomAccount(user2.Username)
+	account2.ID = account1.ID + 1
+	euroAccount := dbtest.RandomAccount(user2.Username)
+	euroAccount.ID = account1.ID + 2
+	euroAccount.Currency = util.EUR
+
+	amount := int64(10)
+	transfer := dbtest.RandomTransfer(account1, account2)
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateTransferRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.CreateTransferResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+				Description:   "rent",
+				Metadata:      `{"invoice":"42"}`,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        amount,
+					Description:   "rent",
+					Metadata:      json.RawMessage(`{"invoice":"42"}`),
+				}
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferTxResult{
+						Transfer:    transfer,
+						FromAccount: account1,
+						ToAccount:   account2,
+						FromEntry:   db.Entry{ID: 1, AccountID: account1.ID, Amount: -amount},
+						ToEntry:     db.Entry{ID: 2, AccountID: account2.ID, Amount: amount},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, transfer.ID, res.Transfer.Id)
+				require.Equal(t, `{}`, res.Transfer.Metadata)
+				require.Equal(t, -amount, res.FromEntry.Amount)
+				require.Equal(t, amount, res.ToEntry.Amount)
+				require.Nil(t, res.Hold)
+			},
+		},
+		{
+			name: "Pending",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+				Pending:       true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+						require.NotNil(t, arg.HoldExpiresAt)
+
+						pending := transfer
+						pending.Status = db.TransferStatusPending
+						return db.TransferTxResult{
+							Transfer:    pending,
+							FromAccount: account1,
+							ToAccount:   account2,
+							Hold:        &db.Hold{ID: 1, TransferID: transfer.ID, Amount: amount, ExpiresAt: *arg.HoldExpiresAt},
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, db.TransferStatusPending, res.Transfer.Status)
+				require.NotNil(t, res.Hold)
+				require.Nil(t, res.FromEntry)
+				require.Nil(t, res.ToEntry)
+			},
+		},
+		{
+			name: "FX",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   euroAccount.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(euroAccount.ID)).Times(1).Return(euroAccount, nil)
+
+				rate := db.ExchangeRate{FromCurrency: util.USD, ToCurrency: util.EUR, Rate: "0.9", SpreadBps: 50}
+				store.EXPECT().
+					GetExchangeRate(gomock.Any(), gomock.Eq(db.GetExchangeRateParams{FromCurrency: util.USD, ToCurrency: util.EUR})).
+					Times(1).
+					Return(rate, nil)
+
+				arg := db.FXTransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   euroAccount.ID,
+					Amount:        amount,
+					Rate:          "0.9",
+					SpreadBps:     50,
+				}
+				store.EXPECT().
+					FXTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: transfer, FromAccount: account1, ToAccount: euroAccount}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, util.EUR, res.ToAccount.Currency)
+			},
+		},
+		{
+			name: "ConversionTooSmall",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   euroAccount.ID,
+				Amount:        1,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(euroAccount.ID)).Times(1).Return(euroAccount, nil)
+				store.EXPECT().
+					GetExchangeRate(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ExchangeRate{FromCurrency: util.USD, ToCurrency: util.EUR, Rate: "0.9", SpreadBps: 50}, nil)
+				store.EXPECT().
+					FXTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrConversionTooSmall)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireReason(t, err, errCodeConversionTooSmall)
+			},
+		},
+		{
+			name: "ExchangeRateUnavailable",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   euroAccount.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(euroAccount.ID)).Times(1).Return(euroAccount, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(1).Return(db.ExchangeRate{}, sql.ErrNoRows)
+				store.EXPECT().FXTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireReason(t, err, errCodeExchangeRateUnavailable)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireReason(t, err, errCodeInsufficientFunds)
+			},
+		},
+		{
+			name: "FromAccountNotOwned",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account2.ID,
+				ToAccountId:   account1.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireCode(t, err, codes.PermissionDenied)
+			},
+		},
+		{
+			name: "ToAccountNotFound",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireCode(t, err, codes.NotFound)
+			},
+		},
+		{
+			name: "InvalidFields",
+			req: &pb.CreateTransferRequest{
+				FromAccountId:     account1.ID,
+				ToAccountId:       account2.ID,
+				Amount:            0,
+				Currency:          util.USD,
+				QuoteId:           "not-a-uuid",
+				ExternalReference: strings.Repeat("a", 65),
+				Metadata:          `["not","an","object"]`,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireViolation(t, err, "amount")
+				requireViolation(t, err, "quote_id")
+				requireViolation(t, err, "external_reference")
+				requireViolation(t, err, "metadata")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user1.Username)
+			res, err := server.CreateTransfer(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestGetTransferRPC(t *testing.T) {
+	user1, _ := dbtest.RandomUser(t)
+	user2, _ := dbtest.RandomUser(t)
+
+	account1 := dbtest.RandomAccount(user1.Username)
+	account2 := dbtest.RandomAccount(user2.Username)
+	account2.ID = account1.ID + 1
+
+	transfer := dbtest.RandomTransfer(account1, account2)
+	reversal := dbtest.RandomTransfer(account2, account1)
+	reversal.ToAmount = 5
+	reversal.ReversalOf = &transfer.ID
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.GetTransferResponse, err error)
+	}{
+		{
+			name:     "OK",
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					ListTransferReversals(gomock.Any(), gomock.Eq(&transfer.ID)).
+					Times(1).
+					Return([]db.Transfer{reversal}, nil)
+				store.EXPECT().GetHoldByTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, transfer.ID, res.Transfer.Id)
+				require.Len(t, res.Reversals, 1)
+				require.Equal(t, transfer.ID, *res.Reversals[0].ReversalOf)
+				require.Equal(t, int64(5), res.ReversedAmount)
+				require.Nil(t, res.Hold)
+			},
+		},
+		{
+			name:     "NotVisible",
+			username: "outsider",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(account1, nil)
+				store.EXPECT().ListTransferReversals(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetTransferResponse, err error) {
+				requireCode(t, err, codes.PermissionDenied)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(db.Transfer{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetTransferResponse, err error) {
+				requireCode(t, err, codes.NotFound)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, tc.username)
+			res, err := server.GetTransfer(ctx, &pb.GetTransferRequest{Id: transfer.ID})
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestListTransfersRPC(t *testing.T) {
+	user, _ := dbtest.RandomUser(t)
+	account := dbtest.RandomAccount(user.Username)
+	other := dbtest.RandomAccount("other")
+	other.ID = account.ID + 1
+
+	transfers := []db.Transfer{dbtest.RandomTransfer(account, other), dbtest.RandomTransfer(other, account)}
+	minAmount, maxAmount := int64(10), int64(5)
+
+	testCases := []struct {
+		name          string
+		req           *pb.ListTransfersRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.ListTransfersResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.ListTransfersRequest{AccountId: account.ID, Size: 5, Direction: "incoming", Reference: "inv-42"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				arg := db.ListTransfersParams{
+					AccountID: account.ID,
+					Direction: sql.NullString{String: "incoming", Valid: true},
+					Reference: sql.NullString{String: "inv-42", Valid: true},
+					Size:      6,
+				}
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return(transfers, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, res.Transfers, len(transfers))
+				require.Empty(t, res.NextCursor)
+			},
+		},
+		{
+			name: "PermissionDenied",
+			req:  &pb.ListTransfersRequest{AccountId: other.ID, Size: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(other.ID)).Times(1).Return(other, nil)
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				requireCode(t, err, codes.PermissionDenied)
+			},
+		},
+		{
+			name: "InvalidFilters",
+			req: &pb.ListTransfersRequest{
+				AccountId:      account.ID,
+				Size:           5,
+				Direction:      "sideways",
+				CounterpartyId: &account.ID,
+				MinAmount:      &minAmount,
+				MaxAmount:      &maxAmount,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				requireViolation(t, err, "direction")
+				requireViolation(t, err, "counterparty_id")
+				requireViolation(t, err, "from")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username)
+			res, err := server.ListTransfers(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
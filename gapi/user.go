@@ -0,0 +1,125 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"net"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/pb"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/token"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const userAgentHeader = "user-agent"
+
+const errInvalidCredentials = "incorrect username or password"
+
+func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	var v violations
+	v.check("username", server.field(req.GetUsername(), "required,alphanum"))
+	v.check("full_name", server.field(req.GetFullName(), "required"))
+	v.check("email", server.field(req.GetEmail(), "required,email"))
+	v.check("password", server.field(req.GetPassword(), "required,min=6"))
+	if len(v) > 0 {
+		return nil, invalidArgumentError(v)
+	}
+
+	hashedPassword, err := util.HashPassword(req.GetPassword())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot hash password: %s", err)
+	}
+
+	user, err := server.store.CreateUser(ctx, db.CreateUserParams{
+		Username:       req.GetUsername(),
+		HashedPassword: hashedPassword,
+		FullName:       req.GetFullName(),
+		Email:          req.GetEmail(),
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	return &pb.CreateUserResponse{User: convertUser(user)}, nil
+}
+
+func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
+	var v violations
+	v.check("username", server.field(req.GetUsername(), "required,alphanum"))
+	v.check("password", server.field(req.GetPassword(), "required,min=6"))
+	if len(v) > 0 {
+		return nil, invalidArgumentError(v)
+	}
+
+	// the same error for an unknown username and a wrong password, like the
+	// HTTP API
+	user, err := server.store.GetUserByUsername(ctx, req.GetUsername())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			util.CheckUnknownUserPassword(req.GetPassword())
+			return nil, status.Error(codes.Unauthenticated, errInvalidCredentials)
+		}
+		return nil, storeError(err)
+	}
+
+	if err := util.CheckPassword(req.GetPassword(), user.HashedPassword); err != nil {
+		return nil, status.Error(codes.Unauthenticated, errInvalidCredentials)
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, token.TokenTypeAccess, server.config.AccessTokenDuration)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot create access token: %s", err)
+	}
+
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(user.Username, user.Role, token.TokenTypeRefresh, server.config.RefreshTokenDuration)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot create refresh token: %s", err)
+	}
+
+	userAgent, clientIP := clientInfo(ctx)
+	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
+		ID:           refreshPayload.ID,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		UserAgent:    userAgent,
+		ClientIp:     clientIP,
+		IsBlocked:    false,
+		ExpiresAt:    refreshPayload.ExpiredAt,
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	return &pb.LoginUserResponse{
+		User:                  convertUser(user),
+		SessionId:             session.ID.String(),
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  timestamppb.New(accessPayload.ExpiredAt),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(refreshPayload.ExpiredAt),
+	}, nil
+}
+
+// clientInfo returns the user agent and address of the caller for the
+// session, like the HTTP API records them
+func clientInfo(ctx context.Context) (userAgent, clientIP string) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(userAgentHeader); len(values) > 0 {
+			userAgent = values[0]
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		clientIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(clientIP); err == nil {
+			clientIP = host
+		}
+	}
+
+	return userAgent, clientIP
+}
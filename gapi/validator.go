@@ -0,0 +1,56 @@
+package gapi
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// violations collects the field violations of a request
+type violations []*errdetails.BadRequest_FieldViolation
+
+func (v *violations) check(field string, err error) {
+	if err != nil {
+		*v = append(*v, fieldViolation(field, err))
+	}
+}
+
+// field checks value against tag, the binding tag the HTTP API puts on the
+// same field
+func (server *Server) field(value interface{}, tag string) error {
+	return validationError(server.validate.Var(value, tag))
+}
+
+// fieldNotEqual is field for a tag that compares value with other, like
+// nefield
+func (server *Server) fieldNotEqual(value, other interface{}, tag string) error {
+	return validationError(server.validate.VarWithValue(value, other, tag))
+}
+
+// validationError names the failed tag, the error of a single value has no
+// field name for the message the validator builds
+func validationError(err error) error {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) && len(validationErrors) > 0 {
+		return fmt.Errorf("failed on the '%s' tag", validationErrors[0].Tag())
+	}
+	return err
+}
+
+func validateCursor(value string) error {
+	_, err := util.DecodeCursor(value)
+	return err
+}
+
+func optionalTime(value *timestamppb.Timestamp) *time.Time {
+	if value == nil {
+		return nil
+	}
+	t := value.AsTime()
+	return &t
+}
@@ -0,0 +1,49 @@
+// Package holds releases the holds of pending transfers once they expire
+package holds
+
+import (
+	"context"
+	"time"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+)
+
+// DefaultBatchSize is the number of expired holds released per
+// transaction
+const DefaultBatchSize int32 = 100
+
+type Worker struct {
+	store     db.Store
+	batchSize int32
+	now       func() time.Time
+}
+
+func NewWorker(store db.Store) *Worker {
+	return &Worker{
+		store:     store,
+		batchSize: DefaultBatchSize,
+		now:       time.Now,
+	}
+}
+
+// RunOnce releases every hold that has expired by now, batch by batch, and
+// returns them
+func (worker *Worker) RunOnce(ctx context.Context) ([]db.Hold, error) {
+	expired := []db.Hold{}
+	now := worker.now()
+
+	for {
+		batch, err := worker.store.ExpireHoldsTx(ctx, db.ExpireHoldsTxParams{
+			Now:  now,
+			Size: worker.batchSize,
+		})
+		if err != nil {
+			return expired, err
+		}
+
+		expired = append(expired, batch...)
+		if len(batch) < int(worker.batchSize) {
+			return expired, nil
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package holds
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunOnce(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	arg := db.ExpireHoldsTxParams{Now: now, Size: DefaultBatchSize}
+
+	testCases := []struct {
+		name         string
+		buildStubs   func(store *mockdb.MockStore)
+		checkExpired func(t *testing.T, expired []db.Hold, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ExpireHoldsTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.Hold{{ID: 1, Status: db.HoldStatusExpired}}, nil)
+			},
+			checkExpired: func(t *testing.T, expired []db.Hold, err error) {
+				require.NoError(t, err)
+				require.Len(t, expired, 1)
+			},
+		},
+		{
+			name: "NothingExpired",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ExpireHoldsTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.Hold{}, nil)
+			},
+			checkExpired: func(t *testing.T, expired []db.Hold, err error) {
+				require.NoError(t, err)
+				require.Empty(t, expired)
+			},
+		},
+		{
+			name: "Batches",
+			buildStubs: func(store *mockdb.MockStore) {
+				full := make([]db.Hold, DefaultBatchSize)
+				gomock.InOrder(
+					store.EXPECT().ExpireHoldsTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(full, nil),
+					store.EXPECT().ExpireHoldsTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(full[:1], nil),
+				)
+			},
+			checkExpired: func(t *testing.T, expired []db.Hold, err error) {
+				require.NoError(t, err)
+				require.Len(t, expired, int(DefaultBatchSize)+1)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ExpireHoldsTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkExpired: func(t *testing.T, expired []db.Hold, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+				require.Empty(t, expired)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			worker := NewWorker(store)
+			worker.now = func() time.Time { return now }
+
+			expired, err := worker.RunOnce(context.Background())
+			tc.checkExpired(t, expired, err)
+		})
+	}
+}
@@ -1,14 +1,28 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"log/slog"
+	"os"
+	"time"
 
 	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/api"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/banking"
 	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/gapi"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/holds"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/metrics"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/relay"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/scheduler"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/stream"
 	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/webhooks"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
 //	@title			Simple Bank API
@@ -17,6 +31,11 @@ import (
 
 //	@host		localhost:8080
 //	@BasePath	/api/v1
+
+//	@securityDefinitions.apikey	BearerAuth
+//	@in							header
+//	@name						Authorization
+//	@description				Type "Bearer" followed by a space and the access token
 func main() {
 
 	config, err := util.LoadConfig(".")
@@ -24,18 +43,87 @@ func main() {
 		log.Fatal("cannot load config: ", err)
 	}
 
+	logLevel, err := util.ParseLogLevel(config.LogLevel)
+	if err != nil {
+		log.Fatal("cannot load config: ", err)
+	}
+	// the log package writes through the default logger too, everything
+	// after this point logs JSON
+	logger := util.NewLogger(os.Stdout, logLevel)
+	slog.SetDefault(logger)
+
 	conn, err := sql.Open(config.DBDriver, config.DBSource)
 	if err != nil {
-		log.Fatal("cannot connect to db: ", err)
+		fatal("cannot connect to db", err)
 	}
 
-	store := db.NewStore(conn)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(conn, "bank"),
+	)
+
+	store := metrics.NewStore(db.NewStore(conn), registry)
+
+	ctx := context.Background()
+	go util.RunEvery(ctx, "scheduler", config.SchedulerInterval, util.Job(scheduler.NewWorker(store).RunOnce))
+	go util.RunEvery(ctx, "holds", config.HoldExpiryInterval, util.Job(holds.NewWorker(store).RunOnce))
+	go util.RunEvery(ctx, "webhooks", config.WebhookInterval, util.Job(webhooks.NewWorker(store).RunOnce))
+
+	// events carry customer data, they are only relayed to a configured file
+	// and never mixed into the logs on stdout
+	if config.OutboxFile != "" {
+		publisher, err := relay.NewFilePublisher(config.OutboxFile)
+		if err != nil {
+			fatal("cannot open outbox file", err)
+		}
+		go util.RunEvery(ctx, "relay", config.OutboxRelayInterval, util.Job(relay.NewWorker(store, publisher).RunOnce))
+	} else {
+		slog.Info("outbox relay disabled, OUTBOX_FILE is not set")
+	}
 
-	server := api.NewServer(store)
+	// account events are streamed from a dedicated LISTEN connection
+	listener := pq.NewListener(config.DBSource, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			slog.Error("account events listener failed", "event", int(event), "error", err)
+		}
+	})
+	broker := stream.NewBroker()
+	go func() {
+		if err := broker.Listen(ctx, listener); err != nil {
+			slog.Error("cannot listen for account events", "error", err)
+		}
+	}()
+
+	// one registry for both servers, a currency an admin changes over HTTP
+	// is seen by the gRPC API right away
+	currencies := banking.NewCurrencyRegistry(store, config.CurrencyCacheTTL)
+
+	grpcServer, err := gapi.NewServer(config, store, currencies)
+	if err != nil {
+		fatal("cannot create gRPC server", err)
+	}
+	go func() {
+		if err := grpcServer.Start(config.GRPCServerAddress); err != nil {
+			fatal("cannot start gRPC server", err)
+		}
+	}()
+
+	server, err := api.NewServer(config, store, currencies, broker, logger, registry)
+	if err != nil {
+		fatal("cannot create server", err)
+	}
 
 	err = server.Start(config.ServerAddress)
 	if err != nil {
-		log.Fatal("cannot start server: ", err)
+		fatal("cannot start server", err)
 	}
 	
-}
\ No newline at end of file
+}
+
+// fatal logs a startup failure and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
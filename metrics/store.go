@@ -0,0 +1,108 @@
+// Package metrics counts the ledger activity of the store for Prometheus
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Reasons a transfer fails with, the label values of bank_transfer_failures_total
+const (
+	ReasonInsufficientFunds = "insufficient_funds"
+	ReasonAccountFrozen     = "account_frozen"
+	ReasonAccountClosed     = "account_closed"
+	ReasonNotFound          = "not_found"
+	ReasonCanceled          = "canceled"
+	ReasonOther             = "other"
+)
+
+// Store counts the transfers made through the store it wraps, every caller
+// of TransferTx, FXTransferTx and CaptureTransferTx is counted, the API, the
+// gRPC API and the scheduler alike. A transfer is counted once it is posted,
+// so a pending transfer is counted when it is captured and never if it is
+// voided or expires
+type Store struct {
+	db.Store
+
+	transfers *prometheus.CounterVec
+	volume    *prometheus.CounterVec
+	failures  *prometheus.CounterVec
+}
+
+// NewStore wraps store and registers its metrics with registerer
+func NewStore(store db.Store, registerer prometheus.Registerer) *Store {
+	factory := promauto.With(registerer)
+
+	return &Store{
+		Store: store,
+		transfers: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "bank_transfers_total",
+			Help: "Transfers posted, by the currency of the source account.",
+		}, []string{"currency"}),
+		volume: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "bank_transfer_volume_total",
+			Help: "Amount of the posted transfers in minor units, by the currency of the source account.",
+		}, []string{"currency"}),
+		failures: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "bank_transfer_failures_total",
+			Help: "Transfers that were rolled back, by reason.",
+		}, []string{"reason"}),
+	}
+}
+
+func (store *Store) TransferTx(ctx context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+	result, err := store.Store.TransferTx(ctx, arg)
+	store.observe(result, err)
+	return result, err
+}
+
+func (store *Store) FXTransferTx(ctx context.Context, arg db.FXTransferTxParams) (db.TransferTxResult, error) {
+	result, err := store.Store.FXTransferTx(ctx, arg)
+	store.observe(result, err)
+	return result, err
+}
+
+func (store *Store) CaptureTransferTx(ctx context.Context, arg db.CaptureTransferTxParams) (db.TransferTxResult, error) {
+	result, err := store.Store.CaptureTransferTx(ctx, arg)
+	store.observe(result, err)
+	return result, err
+}
+
+func (store *Store) observe(result db.TransferTxResult, err error) {
+	if err != nil {
+		store.failures.WithLabelValues(FailureReason(err)).Inc()
+		return
+	}
+
+	if result.Transfer.Status != db.TransferStatusPosted {
+		return
+	}
+
+	currency := result.FromAccount.Currency
+	store.transfers.WithLabelValues(currency).Inc()
+	store.volume.WithLabelValues(currency).Add(float64(result.Transfer.Amount))
+}
+
+// FailureReason sorts a transfer error into one of the reasons, errors the
+// client cannot act on are all other
+func FailureReason(err error) string {
+	switch {
+	case errors.Is(err, db.ErrInsufficientFunds):
+		return ReasonInsufficientFunds
+	case errors.Is(err, db.ErrAccountFrozen):
+		return ReasonAccountFrozen
+	case errors.Is(err, db.ErrAccountClosed):
+		return ReasonAccountClosed
+	case errors.Is(err, sql.ErrNoRows):
+		return ReasonNotFound
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return ReasonCanceled
+	default:
+		return ReasonOther
+	}
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func transferResult(currency string, amount int64) db.TransferTxResult {
+	return db.TransferTxResult{
+		Transfer:    db.Transfer{Amount: amount, Status: db.TransferStatusPosted},
+		FromAccount: db.Account{Currency: currency},
+	}
+}
+
+func pendingTransferResult(currency string, amount int64) db.TransferTxResult {
+	result := transferResult(currency, amount)
+	result.Transfer.Status = db.TransferStatusPending
+	return result
+}
+
+func TestStoreTransferTx(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		mock.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(transferResult("USD", 100), nil),
+		mock.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(transferResult("USD", 50), nil),
+		mock.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds),
+	)
+	mock.EXPECT().FXTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(transferResult("EUR", 30), nil)
+
+	registry := prometheus.NewRegistry()
+	store := NewStore(mock, registry)
+
+	for i := 0; i < 3; i++ {
+		store.TransferTx(context.Background(), db.TransferTxParams{})
+	}
+	_, err := store.FXTransferTx(context.Background(), db.FXTransferTxParams{})
+	require.NoError(t, err)
+
+	require.Equal(t, 2.0, testutil.ToFloat64(store.transfers.WithLabelValues("USD")))
+	require.Equal(t, 150.0, testutil.ToFloat64(store.volume.WithLabelValues("USD")))
+	require.Equal(t, 1.0, testutil.ToFloat64(store.transfers.WithLabelValues("EUR")))
+	require.Equal(t, 30.0, testutil.ToFloat64(store.volume.WithLabelValues("EUR")))
+	require.Equal(t, 1.0, testutil.ToFloat64(store.failures.WithLabelValues(ReasonInsufficientFunds)))
+
+	count, err := testutil.GatherAndCount(registry, "bank_transfers_total", "bank_transfer_volume_total", "bank_transfer_failures_total")
+	require.NoError(t, err)
+	require.Equal(t, 5, count)
+}
+
+func TestStorePendingTransfer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		mock.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(pendingTransferResult("USD", 100), nil),
+		mock.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(pendingTransferResult("USD", 40), nil),
+	)
+	mock.EXPECT().CaptureTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(transferResult("USD", 100), nil)
+	mock.EXPECT().VoidTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.VoidTransferTxResult{}, nil)
+
+	store := NewStore(mock, prometheus.NewRegistry())
+
+	_, err := store.TransferTx(context.Background(), db.TransferTxParams{})
+	require.NoError(t, err)
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{})
+	require.NoError(t, err)
+	require.Equal(t, 0.0, testutil.ToFloat64(store.transfers.WithLabelValues("USD")))
+	require.Equal(t, 0.0, testutil.ToFloat64(store.volume.WithLabelValues("USD")))
+
+	// the first one is captured, the second one voided and never counted
+	_, err = store.CaptureTransferTx(context.Background(), db.CaptureTransferTxParams{})
+	require.NoError(t, err)
+	_, err = store.VoidTransferTx(context.Background(), 2)
+	require.NoError(t, err)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(store.transfers.WithLabelValues("USD")))
+	require.Equal(t, 100.0, testutil.ToFloat64(store.volume.WithLabelValues("USD")))
+	require.Equal(t, 0, testutil.CollectAndCount(store.failures))
+}
+
+func TestFailureReason(t *testing.T) {
+	testCases := []struct {
+		err    error
+		reason string
+	}{
+		{db.ErrInsufficientFunds, ReasonInsufficientFunds},
+		{fmt.Errorf("tx error: %w", db.ErrAccountFrozen), ReasonAccountFrozen},
+		{db.ErrAccountClosed, ReasonAccountClosed},
+		{sql.ErrNoRows, ReasonNotFound},
+		{context.DeadlineExceeded, ReasonCanceled},
+		{errors.New("connection reset"), ReasonOther},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.reason, FailureReason(tc.err), tc.err.Error())
+	}
+}
@@ -0,0 +1,146 @@
+// Package reconcile checks that the balance of every account matches the sum
+// of its entries and records the outcome of each check as a reconciliation run
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+)
+
+// Run statuses, a failed run still records the mismatches found before the
+// error
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// DefaultBatchSize is the number of accounts read per query
+const DefaultBatchSize int32 = 500
+
+// Mismatch is an account whose balance differs from the sum of its entries,
+// Delta is the balance minus the entries total
+type Mismatch struct {
+	AccountID    int64 `json:"account_id"`
+	Balance      int64 `json:"balance"`
+	EntriesTotal int64 `json:"entries_total"`
+	Delta        int64 `json:"delta"`
+	// AdjustmentEntryID is the entry written to close the gap, only set
+	// when the run was asked to adjust
+	AdjustmentEntryID *int64 `json:"adjustment_entry_id,omitempty"`
+}
+
+type Options struct {
+	BatchSize int32
+	// Adjust writes a correcting entry for every mismatch
+	Adjust      bool
+	TriggeredBy string
+}
+
+type Result struct {
+	Run        db.ReconciliationRun `json:"run"`
+	Mismatches []Mismatch           `json:"mismatches"`
+}
+
+// Unadjusted counts the mismatches that are still open after the run
+func (result Result) Unadjusted() int {
+	count := 0
+	for _, mismatch := range result.Mismatches {
+		if mismatch.AdjustmentEntryID == nil {
+			count++
+		}
+	}
+	return count
+}
+
+// Run scans all accounts in batches and stores the outcome as a
+// reconciliation run. The run is stored even when the scan fails, the
+// returned error is then the scan error
+func Run(ctx context.Context, store db.Store, opts Options) (Result, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	startedAt := time.Now()
+	checked, mismatches, scanErr := scan(ctx, store, opts)
+
+	encoded, err := json.Marshal(mismatches)
+	if err != nil {
+		return Result{}, err
+	}
+
+	arg := db.CreateReconciliationRunParams{
+		TriggeredBy:     opts.TriggeredBy,
+		Adjust:          opts.Adjust,
+		Status:          StatusSucceeded,
+		AccountsChecked: checked,
+		MismatchCount:   int64(len(mismatches)),
+		Mismatches:      encoded,
+		StartedAt:       startedAt,
+	}
+	if scanErr != nil {
+		arg.Status = StatusFailed
+		arg.Error = scanErr.Error()
+	}
+
+	run, err := store.CreateReconciliationRun(ctx, arg)
+	if err != nil {
+		if scanErr != nil {
+			return Result{}, fmt.Errorf("%w, cannot record run: %v", scanErr, err)
+		}
+		return Result{}, fmt.Errorf("cannot record run: %w", err)
+	}
+
+	return Result{Run: run, Mismatches: mismatches}, scanErr
+}
+
+func scan(ctx context.Context, store db.Store, opts Options) (int64, []Mismatch, error) {
+	var checked int64
+	mismatches := []Mismatch{}
+
+	var afterID int64
+	for {
+		totals, err := store.ListAccountEntryTotals(ctx, db.ListAccountEntryTotalsParams{
+			AfterID: afterID,
+			Size:    opts.BatchSize,
+		})
+		if err != nil {
+			return checked, mismatches, fmt.Errorf("cannot list accounts after %d: %w", afterID, err)
+		}
+
+		for _, total := range totals {
+			checked++
+			if total.Balance == total.EntriesTotal {
+				continue
+			}
+
+			mismatch := Mismatch{
+				AccountID:    total.ID,
+				Balance:      total.Balance,
+				EntriesTotal: total.EntriesTotal,
+				Delta:        total.Balance - total.EntriesTotal,
+			}
+
+			if opts.Adjust {
+				result, err := store.AdjustEntriesTx(ctx, total.ID)
+				if err != nil {
+					mismatches = append(mismatches, mismatch)
+					return checked, mismatches, fmt.Errorf("cannot adjust account %d: %w", total.ID, err)
+				}
+				if result.Entry != nil {
+					mismatch.AdjustmentEntryID = &result.Entry.ID
+				}
+			}
+
+			mismatches = append(mismatches, mismatch)
+		}
+
+		if len(totals) < int(opts.BatchSize) {
+			return checked, mismatches, nil
+		}
+		afterID = totals[len(totals)-1].ID
+	}
+}
@@ -0,0 +1,218 @@
+package reconcile
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordRun stubs CreateReconciliationRun and echoes the params back as the
+// stored run
+func recordRun(store *mockdb.MockStore, check func(arg db.CreateReconciliationRunParams)) {
+	store.EXPECT().
+		CreateReconciliationRun(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateReconciliationRunParams) (db.ReconciliationRun, error) {
+			check(arg)
+			return db.ReconciliationRun{
+				ID:              1,
+				TriggeredBy:     arg.TriggeredBy,
+				Adjust:          arg.Adjust,
+				Status:          arg.Status,
+				AccountsChecked: arg.AccountsChecked,
+				MismatchCount:   arg.MismatchCount,
+				Mismatches:      arg.Mismatches,
+				Error:           arg.Error,
+				StartedAt:       arg.StartedAt,
+			}, nil
+		})
+}
+
+func TestRun(t *testing.T) {
+	firstBatch := []db.ListAccountEntryTotalsRow{
+		{ID: 1, Balance: 100, EntriesTotal: 100},
+		{ID: 2, Balance: 50, EntriesTotal: 80},
+	}
+	secondBatch := []db.ListAccountEntryTotalsRow{
+		{ID: 5, Balance: 10, EntriesTotal: 0},
+	}
+
+	testCases := []struct {
+		name       string
+		opts       Options
+		buildStubs func(store *mockdb.MockStore)
+		checkRun   func(t *testing.T, result Result, err error)
+	}{
+		{
+			name: "Report",
+			opts: Options{BatchSize: 2, TriggeredBy: "cli"},
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().
+						ListAccountEntryTotals(gomock.Any(), gomock.Eq(db.ListAccountEntryTotalsParams{AfterID: 0, Size: 2})).
+						Times(1).
+						Return(firstBatch, nil),
+					store.EXPECT().
+						ListAccountEntryTotals(gomock.Any(), gomock.Eq(db.ListAccountEntryTotalsParams{AfterID: 2, Size: 2})).
+						Times(1).
+						Return(secondBatch, nil),
+				)
+				store.EXPECT().AdjustEntriesTx(gomock.Any(), gomock.Any()).Times(0)
+				recordRun(store, func(arg db.CreateReconciliationRunParams) {
+					require.Equal(t, StatusSucceeded, arg.Status)
+					require.Equal(t, "cli", arg.TriggeredBy)
+					require.Equal(t, int64(3), arg.AccountsChecked)
+					require.Equal(t, int64(2), arg.MismatchCount)
+					require.False(t, arg.StartedAt.IsZero())
+
+					var mismatches []Mismatch
+					require.NoError(t, json.Unmarshal(arg.Mismatches, &mismatches))
+					require.Len(t, mismatches, 2)
+				})
+			},
+			checkRun: func(t *testing.T, result Result, err error) {
+				require.NoError(t, err)
+				require.Equal(t, []Mismatch{
+					{AccountID: 2, Balance: 50, EntriesTotal: 80, Delta: -30},
+					{AccountID: 5, Balance: 10, EntriesTotal: 0, Delta: 10},
+				}, result.Mismatches)
+				require.Equal(t, 2, result.Unadjusted())
+			},
+		},
+		{
+			name: "Adjust",
+			opts: Options{BatchSize: 10, Adjust: true, TriggeredBy: "admin"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountEntryTotals(gomock.Any(), gomock.Eq(db.ListAccountEntryTotalsParams{AfterID: 0, Size: 10})).
+					Times(1).
+					Return(append(firstBatch, secondBatch...), nil)
+				store.EXPECT().
+					AdjustEntriesTx(gomock.Any(), gomock.Eq(int64(2))).
+					Times(1).
+					Return(db.AdjustEntriesTxResult{Delta: -30, Entry: &db.Entry{ID: 20, AccountID: 2, Amount: -30}}, nil)
+				store.EXPECT().
+					AdjustEntriesTx(gomock.Any(), gomock.Eq(int64(5))).
+					Times(1).
+					Return(db.AdjustEntriesTxResult{Delta: 10, Entry: &db.Entry{ID: 21, AccountID: 5, Amount: 10}}, nil)
+				recordRun(store, func(arg db.CreateReconciliationRunParams) {
+					require.Equal(t, StatusSucceeded, arg.Status)
+					require.True(t, arg.Adjust)
+				})
+			},
+			checkRun: func(t *testing.T, result Result, err error) {
+				require.NoError(t, err)
+				require.Len(t, result.Mismatches, 2)
+				require.Equal(t, int64(20), *result.Mismatches[0].AdjustmentEntryID)
+				require.Equal(t, int64(21), *result.Mismatches[1].AdjustmentEntryID)
+				require.Zero(t, result.Unadjusted())
+			},
+		},
+		{
+			name: "NoAccounts",
+			opts: Options{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountEntryTotals(gomock.Any(), gomock.Eq(db.ListAccountEntryTotalsParams{AfterID: 0, Size: DefaultBatchSize})).
+					Times(1).
+					Return([]db.ListAccountEntryTotalsRow{}, nil)
+				recordRun(store, func(arg db.CreateReconciliationRunParams) {
+					require.Equal(t, StatusSucceeded, arg.Status)
+					require.Zero(t, arg.AccountsChecked)
+					require.JSONEq(t, "[]", string(arg.Mismatches))
+				})
+			},
+			checkRun: func(t *testing.T, result Result, err error) {
+				require.NoError(t, err)
+				require.Empty(t, result.Mismatches)
+			},
+		},
+		{
+			name: "ScanError",
+			opts: Options{BatchSize: 2},
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().
+						ListAccountEntryTotals(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(firstBatch, nil),
+					store.EXPECT().
+						ListAccountEntryTotals(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(nil, sql.ErrConnDone),
+				)
+				recordRun(store, func(arg db.CreateReconciliationRunParams) {
+					require.Equal(t, StatusFailed, arg.Status)
+					require.Contains(t, arg.Error, sql.ErrConnDone.Error())
+					require.Equal(t, int64(1), arg.MismatchCount)
+				})
+			},
+			checkRun: func(t *testing.T, result Result, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+				require.Equal(t, StatusFailed, result.Run.Status)
+				require.Len(t, result.Mismatches, 1)
+			},
+		},
+		{
+			name: "AdjustError",
+			opts: Options{BatchSize: 10, Adjust: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountEntryTotals(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(firstBatch, nil)
+				store.EXPECT().
+					AdjustEntriesTx(gomock.Any(), gomock.Eq(int64(2))).
+					Times(1).
+					Return(db.AdjustEntriesTxResult{}, sql.ErrTxDone)
+				recordRun(store, func(arg db.CreateReconciliationRunParams) {
+					require.Equal(t, StatusFailed, arg.Status)
+				})
+			},
+			checkRun: func(t *testing.T, result Result, err error) {
+				require.ErrorIs(t, err, sql.ErrTxDone)
+				require.Len(t, result.Mismatches, 1)
+				require.Nil(t, result.Mismatches[0].AdjustmentEntryID)
+				require.Equal(t, 1, result.Unadjusted())
+			},
+		},
+		{
+			name: "RecordError",
+			opts: Options{BatchSize: 10},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountEntryTotals(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(firstBatch, nil)
+				store.EXPECT().
+					CreateReconciliationRun(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReconciliationRun{}, sql.ErrConnDone)
+			},
+			checkRun: func(t *testing.T, result Result, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			result, err := Run(context.Background(), store, tc.opts)
+			tc.checkRun(t, result, err)
+		})
+	}
+}
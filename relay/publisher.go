@@ -0,0 +1,78 @@
+package relay
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+)
+
+// EventPublisher sends the outbox events to other systems. Events are
+// delivered at least once and in outbox order, consumers should drop the
+// event ids they have already seen
+type EventPublisher interface {
+	Publish(ctx context.Context, events []db.OutboxEvent) error
+}
+
+// WriterPublisher writes every event payload as one line of JSON
+type WriterPublisher struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterPublisher(w io.Writer) *WriterPublisher {
+	return &WriterPublisher{w: w}
+}
+
+// NewFilePublisher appends the events to the file at path, creating it
+// when it does not exist
+func NewFilePublisher(path string) (*WriterPublisher, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWriterPublisher(file), nil
+}
+
+func (publisher *WriterPublisher) Publish(ctx context.Context, events []db.OutboxEvent) error {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	for _, event := range events {
+		line := append(append([]byte{}, event.Payload...), '\n')
+		if _, err := publisher.w.Write(line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MemoryPublisher keeps the published events in memory, for tests
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []db.OutboxEvent
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (publisher *MemoryPublisher) Publish(ctx context.Context, events []db.OutboxEvent) error {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	publisher.events = append(publisher.events, events...)
+	return nil
+}
+
+// Events returns the events published so far
+func (publisher *MemoryPublisher) Events() []db.OutboxEvent {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	return append([]db.OutboxEvent{}, publisher.events...)
+}
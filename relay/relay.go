@@ -0,0 +1,49 @@
+// Package relay publishes the events of the outbox table
+package relay
+
+import (
+	"context"
+	"time"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+)
+
+// DefaultBatchSize is the number of events published per transaction
+const DefaultBatchSize int32 = 100
+
+type Worker struct {
+	store     db.Store
+	publisher EventPublisher
+	batchSize int32
+	now       func() time.Time
+}
+
+func NewWorker(store db.Store, publisher EventPublisher) *Worker {
+	return &Worker{
+		store:     store,
+		publisher: publisher,
+		batchSize: DefaultBatchSize,
+		now:       time.Now,
+	}
+}
+
+// RunOnce publishes every unsent event, batch by batch, and returns them
+func (worker *Worker) RunOnce(ctx context.Context) ([]db.OutboxEvent, error) {
+	sent := []db.OutboxEvent{}
+
+	for {
+		batch, err := worker.store.RelayOutboxTx(ctx, db.RelayOutboxTxParams{
+			Now:     worker.now(),
+			Size:    worker.batchSize,
+			Publish: worker.publisher.Publish,
+		})
+		if err != nil {
+			return sent, err
+		}
+
+		sent = append(sent, batch...)
+		if len(batch) < int(worker.batchSize) {
+			return sent, nil
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package relay
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type failingPublisher struct{ err error }
+
+func (publisher failingPublisher) Publish(ctx context.Context, events []db.OutboxEvent) error {
+	return publisher.err
+}
+
+func randomOutboxEvent(id int64) db.OutboxEvent {
+	return db.OutboxEvent{
+		ID:            id,
+		EventID:       uuid.New(),
+		EventType:     db.EventTransferCreated,
+		AggregateType: db.AggregateTransfer,
+		AggregateID:   id,
+		Payload:       json.RawMessage(`{"type":"transfer.created"}`),
+	}
+}
+
+func TestRunOnce(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	events := []db.OutboxEvent{randomOutboxEvent(1), randomOutboxEvent(2)}
+	errPublish := errors.New("broker is down")
+
+	// relay publishes the claimed events and marks them sent like the store does
+	relay := func(t *testing.T, claimed []db.OutboxEvent) func(ctx context.Context, arg db.RelayOutboxTxParams) ([]db.OutboxEvent, error) {
+		return func(ctx context.Context, arg db.RelayOutboxTxParams) ([]db.OutboxEvent, error) {
+			require.Equal(t, now, arg.Now)
+			require.Equal(t, DefaultBatchSize, arg.Size)
+
+			if err := arg.Publish(ctx, claimed); err != nil {
+				return []db.OutboxEvent{}, err
+			}
+
+			sent := append([]db.OutboxEvent{}, claimed...)
+			for i := range sent {
+				sent[i].SentAt = &arg.Now
+			}
+			return sent, nil
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		publisher  func() EventPublisher
+		buildStubs func(t *testing.T, store *mockdb.MockStore)
+		checkSent  func(t *testing.T, publisher EventPublisher, sent []db.OutboxEvent, err error)
+	}{
+		{
+			name:      "OK",
+			publisher: func() EventPublisher { return NewMemoryPublisher() },
+			buildStubs: func(t *testing.T, store *mockdb.MockStore) {
+				store.EXPECT().
+					RelayOutboxTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(relay(t, events))
+			},
+			checkSent: func(t *testing.T, publisher EventPublisher, sent []db.OutboxEvent, err error) {
+				require.NoError(t, err)
+				require.Len(t, sent, len(events))
+				for _, event := range sent {
+					require.Equal(t, now, *event.SentAt)
+				}
+				require.Equal(t, events, publisher.(*MemoryPublisher).Events())
+			},
+		},
+		{
+			name:      "Batches",
+			publisher: func() EventPublisher { return NewMemoryPublisher() },
+			buildStubs: func(t *testing.T, store *mockdb.MockStore) {
+				full := make([]db.OutboxEvent, DefaultBatchSize)
+				for i := range full {
+					full[i] = randomOutboxEvent(int64(i + 1))
+				}
+				gomock.InOrder(
+					store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(relay(t, full)),
+					store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(relay(t, events)),
+				)
+			},
+			checkSent: func(t *testing.T, publisher EventPublisher, sent []db.OutboxEvent, err error) {
+				require.NoError(t, err)
+				require.Len(t, sent, int(DefaultBatchSize)+len(events))
+				require.Len(t, publisher.(*MemoryPublisher).Events(), int(DefaultBatchSize)+len(events))
+			},
+		},
+		{
+			name:      "PublishFailed",
+			publisher: func() EventPublisher { return failingPublisher{err: errPublish} },
+			buildStubs: func(t *testing.T, store *mockdb.MockStore) {
+				store.EXPECT().
+					RelayOutboxTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(relay(t, events))
+			},
+			checkSent: func(t *testing.T, publisher EventPublisher, sent []db.OutboxEvent, err error) {
+				require.ErrorIs(t, err, errPublish)
+				require.Empty(t, sent)
+			},
+		},
+		{
+			name:      "InternalError",
+			publisher: func() EventPublisher { return NewMemoryPublisher() },
+			buildStubs: func(t *testing.T, store *mockdb.MockStore) {
+				store.EXPECT().
+					RelayOutboxTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.OutboxEvent{}, sql.ErrConnDone)
+			},
+			checkSent: func(t *testing.T, publisher EventPublisher, sent []db.OutboxEvent, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+				require.Empty(t, sent)
+				require.Empty(t, publisher.(*MemoryPublisher).Events())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(t, store)
+
+			publisher := tc.publisher()
+			worker := NewWorker(store, publisher)
+			worker.now = func() time.Time { return now }
+
+			sent, err := worker.RunOnce(context.Background())
+			tc.checkSent(t, publisher, sent, err)
+		})
+	}
+}
+
+func TestWriterPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := NewWriterPublisher(&buf)
+
+	events := []db.OutboxEvent{randomOutboxEvent(1), randomOutboxEvent(2)}
+	events[1].Payload = json.RawMessage(`{"type":"account.frozen"}`)
+
+	require.NoError(t, publisher.Publish(context.Background(), events))
+	require.Equal(t, "{\"type\":\"transfer.created\"}\n{\"type\":\"account.frozen\"}\n", buf.String())
+}
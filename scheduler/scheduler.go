@@ -0,0 +1,61 @@
+// Package scheduler runs the due scheduled transfers in the background
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+)
+
+// DefaultBatchSize is the number of scheduled transfers claimed per
+// transaction
+const DefaultBatchSize int32 = 100
+
+type Worker struct {
+	store     db.Store
+	batchSize int32
+	now       func() time.Time
+}
+
+func NewWorker(store db.Store) *Worker {
+	return &Worker{
+		store:     store,
+		batchSize: DefaultBatchSize,
+		now:       time.Now,
+	}
+}
+
+// RunOnce executes every scheduled transfer that is due now, batch by batch,
+// and returns the recorded runs
+func (worker *Worker) RunOnce(ctx context.Context) ([]db.ScheduledTransferRun, error) {
+	runs := []db.ScheduledTransferRun{}
+	now := worker.now()
+
+	for {
+		batch, err := worker.store.ProcessScheduledTransfersTx(ctx, db.ProcessScheduledTransfersTxParams{
+			Now:     now,
+			Size:    worker.batchSize,
+			Execute: worker.execute,
+		})
+		if err != nil {
+			return runs, err
+		}
+
+		runs = append(runs, batch...)
+		if len(batch) < int(worker.batchSize) {
+			return runs, nil
+		}
+	}
+}
+
+// execute moves the money for one run, the store has already checked that
+// no transfer was stored under idempotency by an earlier attempt
+func (worker *Worker) execute(ctx context.Context, scheduled db.ScheduledTransfer, idempotency *db.IdempotencyParams) (db.TransferTxResult, error) {
+	return worker.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountID: scheduled.FromAccountID,
+		ToAccountID:   scheduled.ToAccountID,
+		Amount:        scheduled.Amount,
+		Idempotency:   idempotency,
+	})
+}
@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunOnce(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+	scheduled := db.ScheduledTransfer{
+		ID:            7,
+		Owner:         "jane",
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        1_000,
+		IntervalUnit:  db.IntervalMonth,
+		IntervalCount: 1,
+		NextRunAt:     now,
+		Occurrence:    3,
+	}
+
+	transfer := db.TransferTxResult{Transfer: db.Transfer{ID: 42, FromAccountID: 1, ToAccountID: 2, Amount: 1_000}}
+
+	// processDue runs Execute for the scheduled transfer like the store does
+	// when no transfer is stored under its idempotency key yet
+	processDue := func(t *testing.T, checkResult func(result db.TransferTxResult, err error)) func(ctx context.Context, arg db.ProcessScheduledTransfersTxParams) ([]db.ScheduledTransferRun, error) {
+		return func(ctx context.Context, arg db.ProcessScheduledTransfersTxParams) ([]db.ScheduledTransferRun, error) {
+			require.Equal(t, now, arg.Now)
+			require.Equal(t, DefaultBatchSize, arg.Size)
+
+			result, err := arg.Execute(ctx, scheduled, scheduled.Idempotency())
+			checkResult(result, err)
+			return []db.ScheduledTransferRun{{ScheduledTransferID: scheduled.ID}}, nil
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(t *testing.T, store *mockdb.MockStore)
+		checkRuns  func(t *testing.T, runs []db.ScheduledTransferRun, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(t *testing.T, store *mockdb.MockStore) {
+				store.EXPECT().
+					ProcessScheduledTransfersTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(processDue(t, func(result db.TransferTxResult, err error) {
+						require.NoError(t, err)
+						require.Equal(t, transfer, result)
+					}))
+				arg := db.TransferTxParams{
+					FromAccountID: scheduled.FromAccountID,
+					ToAccountID:   scheduled.ToAccountID,
+					Amount:        scheduled.Amount,
+					Idempotency: &db.IdempotencyParams{
+						Username:    "jane",
+						Key:         "scheduled_transfer:7:3",
+						RequestHash: "scheduled_transfer",
+					},
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(transfer, nil)
+			},
+			checkRuns: func(t *testing.T, runs []db.ScheduledTransferRun, err error) {
+				require.NoError(t, err)
+				require.Len(t, runs, 1)
+			},
+		},
+		{
+			name: "TransferFailed",
+			buildStubs: func(t *testing.T, store *mockdb.MockStore) {
+				store.EXPECT().
+					ProcessScheduledTransfersTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(processDue(t, func(result db.TransferTxResult, err error) {
+						require.ErrorIs(t, err, db.ErrInsufficientFunds)
+					}))
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkRuns: func(t *testing.T, runs []db.ScheduledTransferRun, err error) {
+				require.NoError(t, err)
+				require.Len(t, runs, 1)
+			},
+		},
+		{
+			name: "Batches",
+			buildStubs: func(t *testing.T, store *mockdb.MockStore) {
+				full := make([]db.ScheduledTransferRun, DefaultBatchSize)
+				gomock.InOrder(
+					store.EXPECT().ProcessScheduledTransfersTx(gomock.Any(), gomock.Any()).Times(1).Return(full, nil),
+					store.EXPECT().ProcessScheduledTransfersTx(gomock.Any(), gomock.Any()).Times(1).Return(full[:1], nil),
+				)
+			},
+			checkRuns: func(t *testing.T, runs []db.ScheduledTransferRun, err error) {
+				require.NoError(t, err)
+				require.Len(t, runs, int(DefaultBatchSize)+1)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(t *testing.T, store *mockdb.MockStore) {
+				store.EXPECT().
+					ProcessScheduledTransfersTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkRuns: func(t *testing.T, runs []db.ScheduledTransferRun, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+				require.Empty(t, runs)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(t, store)
+
+			worker := NewWorker(store)
+			worker.now = func() time.Time { return now }
+
+			runs, err := worker.RunOnce(context.Background())
+			tc.checkRuns(t, runs, err)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// WriteCSV writes one row per entry between an opening_balance and a
+// closing_balance row, amounts use the decimals of the account's currency
+func (statement Statement) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	rows := [][]string{
+		{"date", "type", "entry_id", "transfer_id", "amount", "balance"},
+		{statement.From.Format(dateLayout), "opening_balance", "", "", "", statement.formatAmount(statement.OpeningBalance)},
+	}
+
+	for _, line := range statement.Lines {
+		transferID := ""
+		if line.TransferID != nil {
+			transferID = strconv.FormatInt(*line.TransferID, 10)
+		}
+
+		rows = append(rows, []string{
+			line.CreatedAt.UTC().Format(time.RFC3339),
+			"entry",
+			strconv.FormatInt(line.ID, 10),
+			transferID,
+			statement.formatAmount(line.Amount),
+			statement.formatAmount(line.Balance),
+		})
+	}
+
+	rows = append(rows, []string{statement.LastDay().Format(dateLayout), "closing_balance", "", "", "", statement.formatAmount(statement.ClosingBalance)})
+
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
@@ -0,0 +1,129 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+`
+	ofxTimeLayout = "20060102150405"
+	// ofxBankID fills BANKID, the API does not have routing numbers
+	ofxBankID = "BANKAPI"
+)
+
+type ofxDocument struct {
+	XMLName xml.Name       `xml:"OFX"`
+	Signon  ofxSignon      `xml:"SIGNONMSGSRSV1>SONRS"`
+	Bank    ofxTransaction `xml:"BANKMSGSRSV1>STMTTRNRS"`
+}
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxSignon struct {
+	Status   ofxStatus `xml:"STATUS"`
+	DTServer string    `xml:"DTSERVER"`
+	Language string    `xml:"LANGUAGE"`
+}
+
+type ofxTransaction struct {
+	TrnUID    string       `xml:"TRNUID"`
+	Status    ofxStatus    `xml:"STATUS"`
+	Statement ofxStatement `xml:"STMTRS"`
+}
+
+type ofxStatement struct {
+	CurDef    string     `xml:"CURDEF"`
+	BankID    string     `xml:"BANKACCTFROM>BANKID"`
+	AcctID    string     `xml:"BANKACCTFROM>ACCTID"`
+	AcctType  string     `xml:"BANKACCTFROM>ACCTTYPE"`
+	DTStart   string     `xml:"BANKTRANLIST>DTSTART"`
+	DTEnd     string     `xml:"BANKTRANLIST>DTEND"`
+	Entries   []ofxEntry `xml:"BANKTRANLIST>STMTTRN"`
+	LedgerBal ofxBalance `xml:"LEDGERBAL"`
+}
+
+type ofxEntry struct {
+	TrnType  string `xml:"TRNTYPE"`
+	DTPosted string `xml:"DTPOSTED"`
+	TrnAmt   string `xml:"TRNAMT"`
+	FitID    string `xml:"FITID"`
+	Name     string `xml:"NAME"`
+}
+
+type ofxBalance struct {
+	BalAmt string `xml:"BALAMT"`
+	DTAsOf string `xml:"DTASOF"`
+}
+
+// WriteOFX writes an OFX 2.2 bank statement, the closing balance is the
+// ledger balance at the end of the period
+func (statement Statement) WriteOFX(w io.Writer) error {
+	accountID := strconv.FormatInt(statement.Account.ID, 10)
+	ok := ofxStatus{Code: 0, Severity: "INFO"}
+
+	document := ofxDocument{
+		Signon: ofxSignon{
+			Status:   ok,
+			DTServer: statement.GeneratedAt.UTC().Format(ofxTimeLayout),
+			Language: "ENG",
+		},
+		Bank: ofxTransaction{
+			TrnUID: fmt.Sprintf("%s-%s", accountID, statement.From.Format(ofxTimeLayout)),
+			Status: ok,
+			Statement: ofxStatement{
+				CurDef:   statement.Account.Currency,
+				BankID:   ofxBankID,
+				AcctID:   accountID,
+				AcctType: "CHECKING",
+				DTStart:  statement.From.UTC().Format(ofxTimeLayout),
+				DTEnd:    statement.To.UTC().Format(ofxTimeLayout),
+				Entries:  make([]ofxEntry, len(statement.Lines)),
+				LedgerBal: ofxBalance{
+					BalAmt: statement.formatAmount(statement.ClosingBalance),
+					DTAsOf: statement.To.UTC().Format(ofxTimeLayout),
+				},
+			},
+		},
+	}
+
+	for i, line := range statement.Lines {
+		entry := ofxEntry{
+			TrnType:  "CREDIT",
+			DTPosted: line.CreatedAt.UTC().Format(ofxTimeLayout),
+			TrnAmt:   statement.formatAmount(line.Amount),
+			FitID:    strconv.FormatInt(line.ID, 10),
+			Name:     "Deposit",
+		}
+		if line.Amount < 0 {
+			entry.TrnType = "DEBIT"
+			entry.Name = "Withdrawal"
+		}
+		if line.TransferID != nil {
+			entry.TrnType = "XFER"
+			entry.Name = fmt.Sprintf("Transfer %d", *line.TransferID)
+		}
+
+		document.Bank.Statement.Entries[i] = entry
+	}
+
+	if _, err := io.WriteString(w, ofxHeader); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
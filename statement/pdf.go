@@ -0,0 +1,121 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// The PDF is a plain A4 text document in Courier so the columns line up
+// without measuring glyphs
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 50
+	pdfFontSize     = 10
+	pdfLeading      = 14
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+)
+
+var pdfEscaper = strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+
+// WritePDF writes the statement as a PDF with a summary header followed by
+// a table of the entries
+func (statement Statement) WritePDF(w io.Writer) error {
+	return writePDF(w, statement.pdfLines())
+}
+
+func (statement Statement) pdfLines() []string {
+	row := func(date, entryID, transferID, amount, balance string) string {
+		return fmt.Sprintf("%-20s %8s %11s %16s %16s", date, entryID, transferID, amount, balance)
+	}
+
+	lines := []string{
+		"Account Statement",
+		"",
+		fmt.Sprintf("Account:  %d", statement.Account.ID),
+		fmt.Sprintf("Owner:    %s", statement.Account.Name),
+		fmt.Sprintf("Currency: %s", statement.Account.Currency),
+		fmt.Sprintf("Period:   %s to %s", statement.From.Format(dateLayout), statement.LastDay().Format(dateLayout)),
+		fmt.Sprintf("Created:  %s", statement.GeneratedAt.UTC().Format(time.RFC3339)),
+		"",
+		row("Date", "Entry", "Transfer", "Amount", "Balance"),
+		row(statement.From.Format(dateLayout), "", "", "Opening balance", statement.formatAmount(statement.OpeningBalance)),
+	}
+
+	for _, line := range statement.Lines {
+		transferID := ""
+		if line.TransferID != nil {
+			transferID = fmt.Sprint(*line.TransferID)
+		}
+
+		lines = append(lines, row(
+			line.CreatedAt.UTC().Format(time.RFC3339),
+			fmt.Sprint(line.ID),
+			transferID,
+			statement.formatAmount(line.Amount),
+			statement.formatAmount(line.Balance),
+		))
+	}
+
+	return append(lines, row(statement.LastDay().Format(dateLayout), "", "", "Closing balance", statement.formatAmount(statement.ClosingBalance)))
+}
+
+// writePDF lays the lines out over as many pages as needed. Objects 1 to 3
+// are the catalog, the page tree and the font, every page then adds a page
+// object and its content stream
+func writePDF(w io.Writer, lines []string) error {
+	var pages [][]string
+	for len(lines) > pdfLinesPerPage {
+		pages = append(pages, lines[:pdfLinesPerPage])
+		lines = lines[pdfLinesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>",
+	}
+
+	for i, page := range pages {
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin)
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscaper.Replace(line))
+		}
+		content.WriteString("ET")
+
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, 5+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err := out.WriteTo(w)
+	return err
+}
@@ -0,0 +1,107 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+)
+
+// Supported statement formats
+const (
+	FormatCSV = "csv"
+	FormatOFX = "ofx"
+	FormatPDF = "pdf"
+)
+
+const dateLayout = "2006-01-02"
+
+// Line is an entry of the statement with the balance right after it
+type Line struct {
+	db.Entry
+	Balance int64
+}
+
+// Statement lists the entries of an account over a period, starting from the
+// opening balance and ending at the closing balance
+type Statement struct {
+	Account        db.Account
+	Exponent       int32
+	From           time.Time
+	To             time.Time
+	GeneratedAt    time.Time
+	OpeningBalance int64
+	ClosingBalance int64
+	Lines          []Line
+}
+
+// New builds a statement for the period from (inclusive) to to (exclusive),
+// exponent is the number of decimals of the account's currency
+func New(account db.Account, exponent int32, from, to time.Time, openingBalance int64, entries []db.Entry) Statement {
+	statement := Statement{
+		Account:        account,
+		Exponent:       exponent,
+		From:           from,
+		To:             to,
+		GeneratedAt:    time.Now().UTC(),
+		OpeningBalance: openingBalance,
+		ClosingBalance: openingBalance,
+		Lines:          make([]Line, len(entries)),
+	}
+
+	for i, entry := range entries {
+		statement.ClosingBalance += entry.Amount
+		statement.Lines[i] = Line{Entry: entry, Balance: statement.ClosingBalance}
+	}
+
+	return statement
+}
+
+// LastDay is the last day covered by the statement
+func (statement Statement) LastDay() time.Time {
+	return statement.To.AddDate(0, 0, -1)
+}
+
+// Write renders the statement in the given format
+func (statement Statement) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatCSV:
+		return statement.WriteCSV(w)
+	case FormatOFX:
+		return statement.WriteOFX(w)
+	case FormatPDF:
+		return statement.WritePDF(w)
+	default:
+		return fmt.Errorf("unsupported statement format %q", format)
+	}
+}
+
+// ContentType is the MIME type of the format
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv"
+	case FormatOFX:
+		return "application/x-ofx"
+	case FormatPDF:
+		return "application/pdf"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// FileName is the name suggested for the downloaded statement
+func (statement Statement) FileName(format string) string {
+	return fmt.Sprintf("statement-%d-%s-%s.%s",
+		statement.Account.ID,
+		statement.From.Format(dateLayout),
+		statement.LastDay().Format(dateLayout),
+		format,
+	)
+}
+
+func (statement Statement) formatAmount(amount int64) string {
+	return util.FormatAmount(amount, statement.Exponent)
+}
@@ -0,0 +1,86 @@
+package statement
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/Just-A-NoobieDev/bankapi-gin-sqlc/util"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func testStatement() Statement {
+	account := db.Account{
+		ID:       42,
+		Name:     "jane (main)",
+		Balance:  12_345,
+		Currency: util.USD,
+	}
+
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)
+	transferID := int64(7)
+
+	entries := []db.Entry{
+		{ID: 1, AccountID: account.ID, Amount: 5_000, CreatedAt: time.Date(2024, time.January, 3, 9, 30, 0, 0, time.UTC)},
+		{ID: 2, AccountID: account.ID, Amount: -1_250, CreatedAt: time.Date(2024, time.January, 10, 14, 0, 0, 0, time.UTC), TransferID: &transferID},
+		{ID: 3, AccountID: account.ID, Amount: -999, CreatedAt: time.Date(2024, time.January, 31, 23, 59, 59, 0, time.UTC)},
+	}
+
+	statement := New(account, 2, from, to, 9_594, entries)
+	statement.GeneratedAt = time.Date(2024, time.February, 1, 8, 0, 0, 0, time.UTC)
+	return statement
+}
+
+func TestNew(t *testing.T) {
+	statement := testStatement()
+
+	require.Equal(t, int64(9_594), statement.OpeningBalance)
+	require.Equal(t, statement.Account.Balance, statement.ClosingBalance)
+	require.Len(t, statement.Lines, 3)
+	require.Equal(t, int64(14_594), statement.Lines[0].Balance)
+	require.Equal(t, int64(13_344), statement.Lines[1].Balance)
+	require.Equal(t, int64(12_345), statement.Lines[2].Balance)
+
+	require.Equal(t, "statement-42-2024-01-01-2024-01-31.pdf", statement.FileName(FormatPDF))
+}
+
+func TestWrite(t *testing.T) {
+	statement := testStatement()
+
+	for _, format := range []string{FormatCSV, FormatOFX, FormatPDF} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, statement.Write(&buf, format))
+
+			golden := filepath.Join("testdata", "statement."+format)
+			if *update {
+				require.NoError(t, os.WriteFile(golden, buf.Bytes(), 0644))
+			}
+
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			require.Equal(t, string(want), buf.String())
+		})
+	}
+}
+
+func TestWriteUnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	require.Error(t, testStatement().Write(&buf, "xls"))
+	require.Zero(t, buf.Len())
+}
+
+func TestWritePDFPages(t *testing.T) {
+	lines := make([]string, 2*pdfLinesPerPage+1)
+
+	var buf bytes.Buffer
+	require.NoError(t, writePDF(&buf, lines))
+	require.Contains(t, buf.String(), "/Count 3")
+}
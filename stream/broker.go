@@ -0,0 +1,157 @@
+// Package stream fans the account events notified by the store transactions
+// out to the connections streaming them
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/lib/pq"
+)
+
+const (
+	// BufferSize is the number of messages a subscriber can fall behind
+	// before it is dropped
+	BufferSize = 16
+	// PingInterval is how often Listen checks the listening connection
+	PingInterval = 90 * time.Second
+)
+
+// Message is an account event, Data is the event JSON as stored in the
+// outbox
+type Message struct {
+	ID   string
+	Type string
+	Data json.RawMessage
+}
+
+// Broker keeps the subscribers of every account and publishes the account
+// events to them
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[int64]map[chan Message]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[int64]map[chan Message]struct{})}
+}
+
+// Subscribe returns the messages of the account and the func that stops
+// them. The channel is closed when the subscriber is stopped or dropped for
+// falling behind, streams should end then so clients reconnect
+func (broker *Broker) Subscribe(accountID int64) (<-chan Message, func()) {
+	messages := make(chan Message, BufferSize)
+
+	broker.mu.Lock()
+	if broker.subscribers[accountID] == nil {
+		broker.subscribers[accountID] = make(map[chan Message]struct{})
+	}
+	broker.subscribers[accountID][messages] = struct{}{}
+	broker.mu.Unlock()
+
+	return messages, func() {
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		broker.remove(accountID, messages)
+	}
+}
+
+// Publish sends the message to the subscribers of the account without
+// blocking, a subscriber with a full buffer is dropped
+func (broker *Broker) Publish(accountID int64, message Message) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	for messages := range broker.subscribers[accountID] {
+		select {
+		case messages <- message:
+		default:
+			broker.remove(accountID, messages)
+		}
+	}
+}
+
+// Notify publishes the event JSON of a notification to the subscribers of
+// its account
+func (broker *Broker) Notify(payload string) error {
+	var event struct {
+		ID            string `json:"id"`
+		Type          string `json:"type"`
+		AggregateType string `json:"aggregate_type"`
+		AggregateID   int64  `json:"aggregate_id"`
+	}
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		return err
+	}
+
+	if event.AggregateType != db.AggregateAccount {
+		return nil
+	}
+
+	broker.Publish(event.AggregateID, Message{
+		ID:   event.ID,
+		Type: event.Type,
+		Data: json.RawMessage(payload),
+	})
+	return nil
+}
+
+// Listen publishes the notifications of the account events channel until
+// ctx is cancelled, then closes the listener and every subscriber. Events
+// committed while the listener reconnects are not streamed
+func (broker *Broker) Listen(ctx context.Context, listener *pq.Listener) error {
+	if err := listener.Listen(db.AccountEventsChannel); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			broker.Close()
+			return listener.Close()
+		case notification := <-listener.Notify:
+			// nil is sent after the connection was re-established
+			if notification == nil {
+				continue
+			}
+			if err := broker.Notify(notification.Extra); err != nil {
+				slog.Error("cannot stream account event", "error", err)
+			}
+		case <-ticker.C:
+			go listener.Ping()
+		}
+	}
+}
+
+// Close drops every subscriber
+func (broker *Broker) Close() {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	for accountID, subscribers := range broker.subscribers {
+		for messages := range subscribers {
+			broker.remove(accountID, messages)
+		}
+	}
+}
+
+// remove closes the channel of a subscriber once, the caller holds mu
+func (broker *Broker) remove(accountID int64, messages chan Message) {
+	subscribers := broker.subscribers[accountID]
+	if _, ok := subscribers[messages]; !ok {
+		return
+	}
+
+	delete(subscribers, messages)
+	close(messages)
+	if len(subscribers) == 0 {
+		delete(broker.subscribers, accountID)
+	}
+}
@@ -0,0 +1,73 @@
+package stream
+
+import (
+	"encoding/json"
+	"testing"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrokerNotify(t *testing.T) {
+	broker := NewBroker()
+
+	messages, unsubscribe := broker.Subscribe(1)
+	other, unsubscribeOther := broker.Subscribe(2)
+	defer unsubscribeOther()
+
+	payload := `{"id":"7b0a3c62-6b8e-4d4f-9a57-1d4f0bbf3a61","type":"account.balance_changed","aggregate_type":"account","aggregate_id":1,"data":{"amount":10}}`
+	require.NoError(t, broker.Notify(payload))
+
+	message := <-messages
+	require.Equal(t, "7b0a3c62-6b8e-4d4f-9a57-1d4f0bbf3a61", message.ID)
+	require.Equal(t, db.EventAccountBalanceChanged, message.Type)
+	require.JSONEq(t, payload, string(message.Data))
+	require.Empty(t, other)
+
+	// transfer events are not about a single account
+	require.NoError(t, broker.Notify(`{"type":"transfer.created","aggregate_type":"transfer","aggregate_id":1}`))
+	require.Empty(t, messages)
+
+	require.Error(t, broker.Notify("not json"))
+
+	unsubscribe()
+	_, open := <-messages
+	require.False(t, open)
+
+	// stopping twice is a no-op
+	unsubscribe()
+}
+
+func TestBrokerDropsSlowSubscriber(t *testing.T) {
+	broker := NewBroker()
+
+	messages, unsubscribe := broker.Subscribe(1)
+	defer unsubscribe()
+
+	for i := 0; i <= BufferSize; i++ {
+		broker.Publish(1, Message{Type: db.EventAccountBalanceChanged, Data: json.RawMessage(`{}`)})
+	}
+
+	received := 0
+	for range messages {
+		received++
+	}
+	require.Equal(t, BufferSize, received)
+}
+
+func TestBrokerClose(t *testing.T) {
+	broker := NewBroker()
+
+	messages1, _ := broker.Subscribe(1)
+	messages2, _ := broker.Subscribe(1)
+
+	broker.Close()
+
+	for _, messages := range []<-chan Message{messages1, messages2} {
+		_, open := <-messages
+		require.False(t, open)
+	}
+
+	// publishing to a closed broker does nothing
+	broker.Publish(1, Message{})
+}
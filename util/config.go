@@ -1,11 +1,35 @@
 package util
 
-import "github.com/spf13/viper"
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
 
 type Config struct {
-	DBDriver      string `mapstructure:"DB_DRIVER"`
-	DBSource      string `mapstructure:"DB_URI"`
-	ServerAddress string `mapstructure:"SERVER_ADDRESS"`
+	DBDriver             string        `mapstructure:"DB_DRIVER"`
+	DBSource             string        `mapstructure:"DB_URI"`
+	ServerAddress        string        `mapstructure:"SERVER_ADDRESS"`
+	GRPCServerAddress    string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	// AdminUsernames are given the admin role by cmd/promote-admins, admins
+	// were only configured here before the role was stored with the user
+	AdminUsernames       []string      `mapstructure:"ADMIN_USERNAMES"`
+	FXQuoteDuration      time.Duration `mapstructure:"FX_QUOTE_DURATION"`
+	CurrencyCacheTTL     time.Duration `mapstructure:"CURRENCY_CACHE_TTL"`
+	SchedulerInterval    time.Duration `mapstructure:"SCHEDULER_INTERVAL"`
+	HoldTTL              time.Duration `mapstructure:"HOLD_TTL"`
+	HoldExpiryInterval   time.Duration `mapstructure:"HOLD_EXPIRY_INTERVAL"`
+	WebhookInterval      time.Duration `mapstructure:"WEBHOOK_INTERVAL"`
+	OutboxRelayInterval  time.Duration `mapstructure:"OUTBOX_RELAY_INTERVAL"`
+	SSEHeartbeatInterval time.Duration `mapstructure:"SSE_HEARTBEAT_INTERVAL"`
+	// OutboxFile receives the relayed events, they are not relayed when it
+	// is empty
+	OutboxFile string `mapstructure:"OUTBOX_FILE"`
+	// LogLevel is debug, info, warn or error
+	LogLevel string `mapstructure:"LOG_LEVEL"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -23,4 +47,4 @@ func LoadConfig(path string) (config Config, err error) {
 
 	err = viper.Unmarshal(&config)
 	return
-}
\ No newline at end of file
+}
@@ -1,15 +1,141 @@
 package util
 
+import (
+	"log/slog"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
 const (
 	USD = "USD"
 	EUR = "EUR"
 	CAD = "CAD"
+	GBP = "GBP"
+	JPY = "JPY"
+	BHD = "BHD"
 )
 
-func IsSupportedCurrency(currency string) bool {
-	switch currency {
-	case USD, EUR, CAD:
-		return true
+// defaultExponent is used to render amounts in currencies the registry
+// does not know, it is the exponent of most ISO 4217 currencies
+const defaultExponent = 2
+
+type Currency struct {
+	Code        string
+	NumericCode int32
+	Exponent    int32
+	Enabled     bool
+}
+
+// CurrencyLoader returns every known currency, enabled or not
+type CurrencyLoader func() ([]Currency, error)
+
+// CurrencyRegistry caches the currencies returned by its loader and
+// reloads them once they are older than the ttl, so currencies enabled by
+// another instance are picked up without a redeploy
+type CurrencyRegistry struct {
+	load CurrencyLoader
+	ttl  time.Duration
+
+	mu         sync.RWMutex
+	currencies map[string]Currency
+	loadedAt   time.Time
+}
+
+func NewCurrencyRegistry(load CurrencyLoader, ttl time.Duration) *CurrencyRegistry {
+	return &CurrencyRegistry{
+		load: load,
+		ttl:  ttl,
+	}
+}
+
+// Get returns the currency with the given code, enabled or not
+func (registry *CurrencyRegistry) Get(code string) (Currency, bool) {
+	currency, ok := registry.snapshot()[code]
+	return currency, ok
+}
+
+// IsEnabled reports whether accounts and transfers may use the currency
+func (registry *CurrencyRegistry) IsEnabled(code string) bool {
+	currency, ok := registry.Get(code)
+	return ok && currency.Enabled
+}
+
+// Exponent returns the number of minor unit digits of the currency
+func (registry *CurrencyRegistry) Exponent(code string) int32 {
+	if currency, ok := registry.Get(code); ok {
+		return currency.Exponent
+	}
+	return defaultExponent
+}
+
+// Format renders an amount in minor units with the decimals of its currency
+func (registry *CurrencyRegistry) Format(amount int64, code string) string {
+	return FormatAmount(amount, registry.Exponent(code))
+}
+
+// Invalidate forces the next lookup to reload the currencies
+func (registry *CurrencyRegistry) Invalidate() {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.loadedAt = time.Time{}
+}
+
+func (registry *CurrencyRegistry) snapshot() map[string]Currency {
+	registry.mu.RLock()
+	currencies, loadedAt := registry.currencies, registry.loadedAt
+	registry.mu.RUnlock()
+
+	if !loadedAt.IsZero() && time.Since(loadedAt) < registry.ttl {
+		return currencies
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	// another goroutine may have reloaded while we waited for the lock
+	if !registry.loadedAt.IsZero() && time.Since(registry.loadedAt) < registry.ttl {
+		return registry.currencies
+	}
+
+	loaded, err := registry.load()
+	if err != nil {
+		// keep serving the stale cache rather than rejecting every currency,
+		// and wait for the next ttl before trying again so a database outage
+		// does not send every request through the write lock
+		slog.Error("cannot reload currencies", "error", err)
+		registry.loadedAt = time.Now()
+		return registry.currencies
+	}
+
+	registry.currencies = make(map[string]Currency, len(loaded))
+	for _, currency := range loaded {
+		registry.currencies[currency.Code] = currency
 	}
-	return false
-}
\ No newline at end of file
+	registry.loadedAt = time.Now()
+
+	return registry.currencies
+}
+
+// FormatAmount renders an amount stored in minor units with the number of
+// decimals of its currency, e.g. 1234 is "12.34" in USD and "1234" in JPY
+func FormatAmount(amount int64, exponent int32) string {
+	if exponent <= 0 {
+		return new(big.Int).SetInt64(amount).String()
+	}
+
+	sign := ""
+	digits := new(big.Int).Abs(big.NewInt(amount)).String()
+	if amount < 0 {
+		sign = "-"
+	}
+
+	if pad := int(exponent) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+
+	split := len(digits) - int(exponent)
+	return sign + digits[:split] + "." + digits[split:]
+}
@@ -0,0 +1,93 @@
+package util
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatAmount(t *testing.T) {
+	testCases := []struct {
+		amount   int64
+		exponent int32
+		expected string
+	}{
+		{amount: 1234, exponent: 2, expected: "12.34"},
+		{amount: 5, exponent: 2, expected: "0.05"},
+		{amount: -5, exponent: 2, expected: "-0.05"},
+		{amount: 1234, exponent: 0, expected: "1234"},
+		{amount: 1234, exponent: 3, expected: "1.234"},
+		{amount: 0, exponent: 3, expected: "0.000"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, FormatAmount(tc.amount, tc.exponent))
+	}
+}
+
+var testCurrencies = []Currency{
+	{Code: USD, NumericCode: 840, Exponent: 2, Enabled: true},
+	{Code: EUR, NumericCode: 978, Exponent: 2, Enabled: true},
+	{Code: CAD, NumericCode: 124, Exponent: 2, Enabled: true},
+	{Code: GBP, NumericCode: 826, Exponent: 2, Enabled: false},
+	{Code: JPY, NumericCode: 392, Exponent: 0, Enabled: false},
+	{Code: BHD, NumericCode: 48, Exponent: 3, Enabled: false},
+}
+
+func TestCurrencyRegistry(t *testing.T) {
+	loads := 0
+	currencies := testCurrencies
+
+	registry := NewCurrencyRegistry(func() ([]Currency, error) {
+		loads++
+		return currencies, nil
+	}, time.Minute)
+
+	require.True(t, registry.IsEnabled(USD))
+	require.False(t, registry.IsEnabled(JPY))
+	require.False(t, registry.IsEnabled("XXX"))
+	require.Equal(t, int32(0), registry.Exponent(JPY))
+	require.Equal(t, int32(3), registry.Exponent(BHD))
+	require.Equal(t, "1.234", registry.Format(1234, BHD))
+	require.Equal(t, "12.34", registry.Format(1234, "XXX"))
+	require.Equal(t, 1, loads)
+
+	// enabling a currency is only seen once the cache is invalidated
+	currencies = append([]Currency{}, testCurrencies...)
+	for i := range currencies {
+		if currencies[i].Code == JPY {
+			currencies[i].Enabled = true
+		}
+	}
+	require.False(t, registry.IsEnabled(JPY))
+
+	registry.Invalidate()
+	require.True(t, registry.IsEnabled(JPY))
+	require.Equal(t, 2, loads)
+}
+
+func TestCurrencyRegistryKeepsStaleCache(t *testing.T) {
+	fail := false
+	loads := 0
+
+	registry := NewCurrencyRegistry(func() ([]Currency, error) {
+		loads++
+		if fail {
+			return nil, errors.New("database is down")
+		}
+		return testCurrencies, nil
+	}, time.Minute)
+
+	require.True(t, registry.IsEnabled(USD))
+
+	fail = true
+	registry.Invalidate()
+	require.True(t, registry.IsEnabled(USD))
+	require.Equal(t, 2, loads)
+
+	// a failed reload is not retried before the ttl is up
+	require.True(t, registry.IsEnabled(EUR))
+	require.Equal(t, 2, loads)
+}
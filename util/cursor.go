@@ -0,0 +1,44 @@
+package util
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PageCursor is the position of the last row of a page, lists are ordered
+// by (created_at, id) so the next page starts right after it
+type PageCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        int64     `json:"i"`
+}
+
+// EncodeCursor makes the cursor opaque so clients do not build their own
+func EncodeCursor(createdAt time.Time, id int64) *string {
+	data, _ := json.Marshal(PageCursor{CreatedAt: createdAt, ID: id})
+	cursor := base64.RawURLEncoding.EncodeToString(data)
+	return &cursor
+}
+
+// DecodeCursor returns the zero cursor, which matches the first page,
+// when no cursor is given
+func DecodeCursor(cursor string) (PageCursor, error) {
+	var result PageCursor
+	if cursor == "" {
+		return result, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return result, ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(data, &result); err != nil || result.ID <= 0 {
+		return PageCursor{}, ErrInvalidCursor
+	}
+
+	return result, nil
+}
@@ -0,0 +1,69 @@
+package util
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxMetadataSize is the largest transfer metadata in bytes, the column
+// enforces the same limit
+const MaxMetadataSize = 4096
+
+var (
+	ErrInvalidTimeRange   = errors.New("from must not be after to")
+	ErrInvalidAmountRange = errors.New("min_amount must not be more than max_amount")
+)
+
+// ValidateRange checks the optional from/to and min/max_amount filters of
+// the list endpoints
+func ValidateRange(from, to *time.Time, minAmount, maxAmount *int64) error {
+	if from != nil && to != nil && from.After(*to) {
+		return ErrInvalidTimeRange
+	}
+
+	if minAmount != nil && maxAmount != nil && *minAmount > *maxAmount {
+		return ErrInvalidAmountRange
+	}
+
+	return nil
+}
+
+// ValidateMetadata checks that the metadata of a transfer is a JSON object
+// small enough for the column, it is optional
+func ValidateMetadata(metadata json.RawMessage) error {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	if len(metadata) > MaxMetadataSize {
+		return fmt.Errorf("metadata must be at most %d bytes", MaxMetadataSize)
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(metadata, &object); err != nil || object == nil {
+		return errors.New("metadata must be a JSON object")
+	}
+
+	return nil
+}
+
+func NullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+func NullInt64(n *int64) sql.NullInt64 {
+	if n == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *n, Valid: true}
+}
+
+func NullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,82 @@
+package util
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+)
+
+// MaxSpreadBps is the exclusive upper bound of a spread in basis points
+const MaxSpreadBps = 10000
+
+var rateFormat = regexp.MustCompile(`^[0-9]{1,10}(\.[0-9]{1,8})?$`)
+
+// ParseRate parses a positive decimal exchange rate with at most 8 decimals,
+// matching the numeric(18,8) columns the rates are stored in
+func ParseRate(rate string) (*big.Rat, error) {
+	if !rateFormat.MatchString(rate) {
+		return nil, fmt.Errorf("invalid exchange rate %q", rate)
+	}
+
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid exchange rate %q", rate)
+	}
+
+	return r, nil
+}
+
+// ConvertAmount converts amount from minor units of one currency to minor
+// units of another with rate and takes the spread off the converted amount,
+// rounding down so the bank never credits more than quoted
+func ConvertAmount(amount int64, rate string, spreadBps int32, fromExponent, toExponent int32) (int64, error) {
+	r, err := ParseRate(rate)
+	if err != nil {
+		return 0, err
+	}
+
+	if spreadBps < 0 || spreadBps >= MaxSpreadBps {
+		return 0, fmt.Errorf("invalid spread %d", spreadBps)
+	}
+
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), r)
+	converted.Mul(converted, big.NewRat(int64(MaxSpreadBps-spreadBps), MaxSpreadBps))
+
+	// rates are quoted in major units, shift by the difference in minor units
+	shift := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(toExponent-fromExponent))), nil)
+	if toExponent >= fromExponent {
+		converted.Mul(converted, new(big.Rat).SetInt(shift))
+	} else {
+		converted.Quo(converted, new(big.Rat).SetInt(shift))
+	}
+
+	result := new(big.Int).Quo(converted.Num(), converted.Denom())
+	if !result.IsInt64() {
+		return 0, fmt.Errorf("converted amount overflows")
+	}
+
+	return result.Int64(), nil
+}
+
+// InverseRate returns 1/rate with 8 decimals, the rate used to convert
+// money back the way it came
+func InverseRate(rate string) (string, error) {
+	r, err := ParseRate(rate)
+	if err != nil {
+		return "", err
+	}
+
+	inverse := new(big.Rat).Inv(r).FloatString(8)
+	if _, err := ParseRate(inverse); err != nil {
+		return "", fmt.Errorf("cannot invert exchange rate %q", rate)
+	}
+
+	return inverse, nil
+}
+
+func abs(n int32) int32 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
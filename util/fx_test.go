@@ -0,0 +1,68 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRate(t *testing.T) {
+	_, err := ParseRate("1.08500000")
+	require.NoError(t, err)
+
+	for _, rate := range []string{"", "0", "0.00000000", "-1", "1/3", "1.123456789", "abc"} {
+		_, err := ParseRate(rate)
+		require.Error(t, err, rate)
+	}
+}
+
+func TestConvertAmount(t *testing.T) {
+	testCases := []struct {
+		name         string
+		amount       int64
+		rate         string
+		spreadBps    int32
+		fromExponent int32
+		toExponent   int32
+		expected     int64
+	}{
+		{name: "NoSpread", amount: 1000, rate: "1.085", spreadBps: 0, fromExponent: 2, toExponent: 2, expected: 1085},
+		{name: "WithSpread", amount: 1000, rate: "1.085", spreadBps: 100, fromExponent: 2, toExponent: 2, expected: 1074},
+		{name: "RoundsDown", amount: 1, rate: "0.99999999", spreadBps: 0, fromExponent: 2, toExponent: 2, expected: 0},
+		{name: "Identity", amount: 12345, rate: "1", spreadBps: 0, fromExponent: 2, toExponent: 2, expected: 12345},
+		// 10.00 USD at 150 JPY per USD is 1500 JPY
+		{name: "FewerMinorUnits", amount: 1000, rate: "150", spreadBps: 0, fromExponent: 2, toExponent: 0, expected: 1500},
+		// 1500 JPY at 0.0025 BHD per JPY is 3.750 BHD
+		{name: "MoreMinorUnits", amount: 1500, rate: "0.0025", spreadBps: 0, fromExponent: 0, toExponent: 3, expected: 3750},
+		// 1 JPY at 0.00666667 USD per JPY is 0.67 cents
+		{name: "RoundsToNothing", amount: 1, rate: "0.00666667", spreadBps: 0, fromExponent: 0, toExponent: 2, expected: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			converted, err := ConvertAmount(tc.amount, tc.rate, tc.spreadBps, tc.fromExponent, tc.toExponent)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, converted)
+		})
+	}
+
+	_, err := ConvertAmount(1000, "1", MaxSpreadBps, 2, 2)
+	require.Error(t, err)
+}
+
+func TestInverseRate(t *testing.T) {
+	inverse, err := InverseRate("1.25")
+	require.NoError(t, err)
+	require.Equal(t, "0.80000000", inverse)
+
+	inverse, err = InverseRate("150")
+	require.NoError(t, err)
+	require.Equal(t, "0.00666667", inverse)
+
+	_, err = InverseRate("abc")
+	require.Error(t, err)
+
+	// too large to be inverted into 8 decimals
+	_, err = InverseRate("1000000000")
+	require.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Redacted replaces the value of a sensitive log attribute
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are left out of every log line, a key matches when it
+// contains one of them, so access_token and hashed_password match too
+var sensitiveKeys = []string{"password", "token", "secret", "authorization", "balance"}
+
+// NewLogger returns a JSON logger that redacts the values of sensitive keys,
+// including the fields of structs and maps logged as a single value
+func NewLogger(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactAttr,
+	}))
+}
+
+// ParseLogLevel reads a level like "debug", "info", "warn" or "error", an
+// empty level is info
+func ParseLogLevel(level string) (slog.Level, error) {
+	var parsed slog.Level
+	if level == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+	return parsed, nil
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactAttr(groups []string, attr slog.Attr) slog.Attr {
+	if isSensitiveKey(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+	if attr.Value.Kind() == slog.KindAny {
+		attr.Value = slog.AnyValue(redactValue(attr.Value.Any()))
+	}
+	return attr
+}
+
+// redactValue goes through the JSON form of structs, maps and slices so the
+// sensitive fields of e.g. an account are caught by their json names
+func redactValue(value interface{}) interface{} {
+	switch value.(type) {
+	case nil, error, fmt.Stringer, time.Time, json.RawMessage, []byte:
+		return value
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(value)).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return value
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return value
+	}
+	return redactJSON(decoded)
+}
+
+func redactJSON(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if isSensitiveKey(key) {
+				value[key] = Redacted
+			} else {
+				value[key] = redactJSON(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = redactJSON(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,59 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggerRedacts(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, slog.LevelInfo)
+
+	type account struct {
+		ID      int64  `json:"id"`
+		Owner   string `json:"owner"`
+		Balance int64  `json:"balance"`
+	}
+
+	logger.Info("test",
+		"username", "alice",
+		"password", "secret123",
+		"refresh_token", "v2.local.abc",
+		"Authorization", "Bearer v2.local.abc",
+		"account", account{ID: 1, Owner: "alice", Balance: 100},
+		"accounts", []account{{ID: 2, Balance: 5}},
+		slog.Group("user", "hashed_password", "$2a$10$abc", "email", "alice@example.com"),
+		"error", errors.New("boom"),
+	)
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+
+	require.Equal(t, "alice", line["username"])
+	require.Equal(t, Redacted, line["password"])
+	require.Equal(t, Redacted, line["refresh_token"])
+	require.Equal(t, Redacted, line["Authorization"])
+	require.Equal(t, map[string]interface{}{"id": 1.0, "owner": "alice", "balance": Redacted}, line["account"])
+	require.Equal(t, []interface{}{map[string]interface{}{"id": 2.0, "owner": "", "balance": Redacted}}, line["accounts"])
+	require.Equal(t, map[string]interface{}{"hashed_password": Redacted, "email": "alice@example.com"}, line["user"])
+	require.Equal(t, "boom", line["error"])
+	require.NotContains(t, buf.String(), "secret123")
+}
+
+func TestParseLogLevel(t *testing.T) {
+	level, err := ParseLogLevel("")
+	require.NoError(t, err)
+	require.Equal(t, slog.LevelInfo, level)
+
+	level, err = ParseLogLevel("debug")
+	require.NoError(t, err)
+	require.Equal(t, slog.LevelDebug, level)
+
+	_, err = ParseLogLevel("loud")
+	require.Error(t, err)
+}
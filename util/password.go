@@ -2,6 +2,7 @@ package util
 
 import (
 	"fmt"
+	"sync"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -17,4 +18,19 @@ func HashPassword(password string) (string, error) {
 
 func CheckPassword(password, hashedPassword string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+}
+
+var (
+	unknownUserHashOnce sync.Once
+	unknownUserHash     []byte
+)
+
+// CheckUnknownUserPassword spends the time of a CheckPassword call for a
+// username that does not exist, so a failed login takes as long whether the
+// username or the password was wrong
+func CheckUnknownUserPassword(password string) {
+	unknownUserHashOnce.Do(func() {
+		unknownUserHash, _ = bcrypt.GenerateFromPassword([]byte("unknown user"), bcrypt.DefaultCost)
+	})
+	bcrypt.CompareHashAndPassword(unknownUserHash, []byte(password))
 }
\ No newline at end of file
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// RunEvery runs job right away and then every interval until ctx is
+// cancelled. A failed run is logged under the name of the job and the next
+// run happens on time, the background workers are all run this way
+func RunEvery(ctx context.Context, name string, interval time.Duration, job func(context.Context) error) {
+	if interval <= 0 {
+		slog.Error("job not started, interval must be positive", "job", name, "interval", interval)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job(ctx); err != nil {
+			slog.Error("job run failed", "job", name, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Job drops the result of a worker's RunOnce so it can be run by RunEvery
+func Job[T any](run func(context.Context) (T, error)) func(context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := run(ctx)
+		return err
+	}
+}
@@ -0,0 +1,50 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunEvery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runs := 0
+	done := make(chan struct{})
+	go func() {
+		RunEvery(ctx, "test", time.Millisecond, func(context.Context) error {
+			runs++
+			if runs == 3 {
+				cancel()
+			}
+			// a failed run does not stop the next ones
+			return errors.New("boom")
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunEvery did not stop when ctx was cancelled")
+	}
+	require.Equal(t, 3, runs)
+}
+
+func TestRunEveryZeroInterval(t *testing.T) {
+	// returns right away instead of panicking in time.NewTicker
+	RunEvery(context.Background(), "test", 0, func(context.Context) error {
+		t.Fatal("job must not run")
+		return nil
+	})
+}
+
+func TestJob(t *testing.T) {
+	job := Job(func(context.Context) ([]int, error) {
+		return []int{1}, errors.New("boom")
+	})
+	require.EqualError(t, job(context.Background()), "boom")
+}
@@ -37,10 +37,10 @@ func RandomAmount() int64 {
 	return RandomInt(0, 1000)
 }
 
+// RandomCurrency picks one of the currencies the migrations seed enabled
 func RandomCurrency() string {
-	currencies := []string{"EUR", "USD", "CAD"}
-	n := len(currencies)
-	return currencies[rand.Intn(n)]
+	currencies := []string{USD, EUR, CAD}
+	return currencies[rand.Intn(len(currencies))]
 }
 
 func RandomEmail() string {
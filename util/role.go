@@ -0,0 +1,17 @@
+package util
+
+// Roles a user can have, every user registers as a customer
+const (
+	CustomerRole = "customer"
+	BankerRole   = "banker"
+	AdminRole    = "admin"
+)
+
+// IsSupportedRole returns true if the role is one of the roles above
+func IsSupportedRole(role string) bool {
+	switch role {
+	case CustomerRole, BankerRole, AdminRole:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package webhooks
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	testCases := []struct {
+		address string
+		public  bool
+	}{
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.0.0.1", false},
+		{"172.16.5.4", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fc00::1", false},
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"::", false},
+		{"224.0.0.1", false},
+		{"100.64.0.1", false},
+		{"100.127.255.254", false},
+		{"::ffff:100.100.1.1", false},
+		{"100.63.255.255", true},
+		{"100.128.0.1", true},
+		{"93.184.216.34", true},
+		{"8.8.8.8", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.public, IsPublicIP(net.ParseIP(tc.address)), tc.address)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	for _, rawURL := range []string{
+		"http://127.0.0.1/hooks",
+		"http://169.254.169.254/latest/meta-data",
+		"http://100.100.100.200/latest/meta-data",
+		"https://10.1.2.3:8443/hooks",
+		"http://[::1]/hooks",
+		"http://localhost:8080/hooks",
+		"http://api.localhost/hooks",
+	} {
+		require.ErrorIs(t, CheckURL(rawURL), ErrPrivateAddress, rawURL)
+	}
+
+	require.NoError(t, CheckURL("https://example.com/hooks"))
+	require.NoError(t, CheckURL("https://93.184.216.34/hooks"))
+}
+
+func TestClientRefusesPrivateAddress(t *testing.T) {
+	hit := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer receiver.Close()
+
+	// the name resolves to loopback, which is only found out when dialing
+	url := strings.Replace(receiver.URL, "127.0.0.1", "localhost", 1)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, nil)
+	require.NoError(t, err)
+
+	_, err = NewClient(time.Second).Do(req)
+	require.ErrorIs(t, err, ErrPrivateAddress)
+	require.False(t, hit)
+}
+
+func TestClientDoesNotFollowRedirects(t *testing.T) {
+	hit := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer target.Close()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer receiver.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, receiver.URL, nil)
+	require.NoError(t, err)
+
+	// allow loopback so the test servers can be reached at all
+	rsp, err := newClient(time.Second, func(net.IP) bool { return true }).Do(req)
+	require.NoError(t, err)
+	defer rsp.Body.Close()
+
+	require.Equal(t, http.StatusTemporaryRedirect, rsp.StatusCode)
+	require.False(t, hit)
+}
@@ -0,0 +1,175 @@
+// Package webhooks posts the queued webhook deliveries to the subscribers
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+)
+
+const (
+	// DefaultBatchSize is the number of deliveries claimed at once, they are
+	// sent one after the other so a batch must fit in DefaultLease
+	DefaultBatchSize int32 = 20
+	// DefaultTimeout is how long a subscriber has to answer
+	DefaultTimeout = 10 * time.Second
+	// DefaultLease keeps a claimed delivery from being picked up by another
+	// worker, a worker that dies mid batch has its deliveries retried after it
+	DefaultLease = 5 * time.Minute
+	// MaxAttempts is the number of attempts before a delivery is dead
+	MaxAttempts int32 = 10
+)
+
+// Retries back off exponentially from minBackoff up to maxBackoff
+const (
+	minBackoff = 30 * time.Second
+	maxBackoff = 6 * time.Hour
+)
+
+// Headers sent with every delivery
+const (
+	HeaderEventID   = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+type Worker struct {
+	store     db.Store
+	client    *http.Client
+	batchSize int32
+	now       func() time.Time
+}
+
+func NewWorker(store db.Store) *Worker {
+	return &Worker{
+		store:     store,
+		client:    NewClient(DefaultTimeout),
+		batchSize: DefaultBatchSize,
+		now:       time.Now,
+	}
+}
+
+// RunOnce sends every delivery that is due now, batch by batch, and returns
+// them with the outcome of the attempt
+func (worker *Worker) RunOnce(ctx context.Context) ([]db.WebhookDelivery, error) {
+	sent := []db.WebhookDelivery{}
+	now := worker.now()
+
+	for {
+		batch, err := worker.store.ClaimWebhookDeliveries(ctx, db.ClaimWebhookDeliveriesParams{
+			LeaseUntil: now.Add(DefaultLease),
+			Now:        now,
+			Size:       worker.batchSize,
+		})
+		if err != nil {
+			return sent, err
+		}
+
+		for _, delivery := range batch {
+			attempt := worker.send(ctx, delivery)
+
+			recorded, err := worker.store.RecordWebhookDeliveryAttempt(ctx, attempt)
+			if err != nil {
+				return sent, err
+			}
+			sent = append(sent, recorded)
+		}
+
+		if len(batch) < int(worker.batchSize) {
+			return sent, nil
+		}
+	}
+}
+
+// send posts one delivery and works out its next state, failed deliveries
+// are retried with a backoff until they run out of attempts
+func (worker *Worker) send(ctx context.Context, delivery db.ClaimWebhookDeliveriesRow) db.RecordWebhookDeliveryAttemptParams {
+	attemptedAt := worker.now()
+	arg := db.RecordWebhookDeliveryAttemptParams{
+		ID:            delivery.ID,
+		Status:        db.WebhookDeliverySucceeded,
+		NextAttemptAt: attemptedAt,
+		LastAttemptAt: &attemptedAt,
+	}
+
+	status, err := worker.post(ctx, delivery, attemptedAt)
+	arg.ResponseStatus = int32(status)
+	if err == nil {
+		return arg
+	}
+
+	arg.LastError = err.Error()
+	attempts := delivery.Attempts + 1
+	if attempts >= MaxAttempts {
+		arg.Status = db.WebhookDeliveryDead
+		return arg
+	}
+
+	arg.Status = db.WebhookDeliveryPending
+	arg.NextAttemptAt = attemptedAt.Add(Backoff(attempts))
+	return arg
+}
+
+// post sends the payload signed with the subscription's secret and returns
+// the status of the response, any status outside 2xx is an error
+func (worker *Worker) post(ctx context.Context, delivery db.ClaimWebhookDeliveriesRow, timestamp time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, delivery.EventID.String())
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	rsp, err := worker.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer rsp.Body.Close()
+
+	// drain the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(rsp.Body, 64<<10))
+
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		return rsp.StatusCode, fmt.Errorf("unexpected status %d", rsp.StatusCode)
+	}
+
+	return rsp.StatusCode, nil
+}
+
+// Sign returns the X-Webhook-Signature of a payload, the HMAC-SHA256 of
+// "<unix timestamp>.<payload>" keyed with the secret. Receivers recompute it
+// to check that the payload came from us and reject old timestamps to stop
+// replays
+func Sign(secret string, timestamp time.Time, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp.Unix())
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns how long to wait after the given number of failed
+// attempts, doubling from minBackoff up to maxBackoff
+func Backoff(attempts int32) time.Duration {
+	backoff := minBackoff
+	for i := int32(1); i < attempts; i++ {
+		backoff *= 2
+		if backoff >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,225 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	mockdb "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/mock"
+	db "github.com/Just-A-NoobieDev/bankapi-gin-sqlc/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunOnce(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC)
+	claim := db.ClaimWebhookDeliveriesParams{
+		LeaseUntil: now.Add(DefaultLease),
+		Now:        now,
+		Size:       DefaultBatchSize,
+	}
+	payload := []byte(`{"type":"transfer.created"}`)
+
+	testCases := []struct {
+		name       string
+		respond    int
+		attempts   int32
+		buildStubs func(store *mockdb.MockStore, delivery db.ClaimWebhookDeliveriesRow)
+		checkSent  func(t *testing.T, sent []db.WebhookDelivery, err error)
+	}{
+		{
+			name:    "OK",
+			respond: http.StatusNoContent,
+			buildStubs: func(store *mockdb.MockStore, delivery db.ClaimWebhookDeliveriesRow) {
+				store.EXPECT().
+					ClaimWebhookDeliveries(gomock.Any(), gomock.Eq(claim)).
+					Times(1).
+					Return([]db.ClaimWebhookDeliveriesRow{delivery}, nil)
+
+				arg := db.RecordWebhookDeliveryAttemptParams{
+					ID:             delivery.ID,
+					Status:         db.WebhookDeliverySucceeded,
+					NextAttemptAt:  now,
+					LastAttemptAt:  &now,
+					ResponseStatus: http.StatusNoContent,
+				}
+				store.EXPECT().
+					RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.WebhookDelivery{ID: delivery.ID, Status: db.WebhookDeliverySucceeded}, nil)
+			},
+			checkSent: func(t *testing.T, sent []db.WebhookDelivery, err error) {
+				require.NoError(t, err)
+				require.Len(t, sent, 1)
+				require.Equal(t, db.WebhookDeliverySucceeded, sent[0].Status)
+			},
+		},
+		{
+			name:     "Retry",
+			respond:  http.StatusInternalServerError,
+			attempts: 2,
+			buildStubs: func(store *mockdb.MockStore, delivery db.ClaimWebhookDeliveriesRow) {
+				store.EXPECT().
+					ClaimWebhookDeliveries(gomock.Any(), gomock.Eq(claim)).
+					Times(1).
+					Return([]db.ClaimWebhookDeliveriesRow{delivery}, nil)
+
+				arg := db.RecordWebhookDeliveryAttemptParams{
+					ID:             delivery.ID,
+					Status:         db.WebhookDeliveryPending,
+					NextAttemptAt:  now.Add(2 * time.Minute),
+					LastAttemptAt:  &now,
+					ResponseStatus: http.StatusInternalServerError,
+					LastError:      "unexpected status 500",
+				}
+				store.EXPECT().
+					RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.WebhookDelivery{ID: delivery.ID, Status: db.WebhookDeliveryPending}, nil)
+			},
+			checkSent: func(t *testing.T, sent []db.WebhookDelivery, err error) {
+				require.NoError(t, err)
+				require.Len(t, sent, 1)
+				require.Equal(t, db.WebhookDeliveryPending, sent[0].Status)
+			},
+		},
+		{
+			name:     "DeadLetter",
+			respond:  http.StatusBadGateway,
+			attempts: MaxAttempts - 1,
+			buildStubs: func(store *mockdb.MockStore, delivery db.ClaimWebhookDeliveriesRow) {
+				store.EXPECT().
+					ClaimWebhookDeliveries(gomock.Any(), gomock.Eq(claim)).
+					Times(1).
+					Return([]db.ClaimWebhookDeliveriesRow{delivery}, nil)
+
+				arg := db.RecordWebhookDeliveryAttemptParams{
+					ID:             delivery.ID,
+					Status:         db.WebhookDeliveryDead,
+					NextAttemptAt:  now,
+					LastAttemptAt:  &now,
+					ResponseStatus: http.StatusBadGateway,
+					LastError:      "unexpected status 502",
+				}
+				store.EXPECT().
+					RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.WebhookDelivery{ID: delivery.ID, Status: db.WebhookDeliveryDead}, nil)
+			},
+			checkSent: func(t *testing.T, sent []db.WebhookDelivery, err error) {
+				require.NoError(t, err)
+				require.Len(t, sent, 1)
+				require.Equal(t, db.WebhookDeliveryDead, sent[0].Status)
+			},
+		},
+		{
+			name: "Batches",
+			buildStubs: func(store *mockdb.MockStore, delivery db.ClaimWebhookDeliveriesRow) {
+				full := make([]db.ClaimWebhookDeliveriesRow, DefaultBatchSize)
+				for i := range full {
+					full[i] = delivery
+				}
+				gomock.InOrder(
+					store.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Eq(claim)).Times(1).Return(full, nil),
+					store.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Eq(claim)).Times(1).Return([]db.ClaimWebhookDeliveriesRow{}, nil),
+				)
+				store.EXPECT().
+					RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).
+					Times(int(DefaultBatchSize)).
+					Return(db.WebhookDelivery{}, nil)
+			},
+			checkSent: func(t *testing.T, sent []db.WebhookDelivery, err error) {
+				require.NoError(t, err)
+				require.Len(t, sent, int(DefaultBatchSize))
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore, delivery db.ClaimWebhookDeliveriesRow) {
+				store.EXPECT().
+					ClaimWebhookDeliveries(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+				store.EXPECT().RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkSent: func(t *testing.T, sent []db.WebhookDelivery, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+				require.Empty(t, sent)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			delivery := db.ClaimWebhookDeliveriesRow{
+				ID:        1,
+				EventID:   uuid.New(),
+				EventType: db.EventTransferCreated,
+				Payload:   payload,
+				Attempts:  tc.attempts,
+				Secret:    "secret",
+			}
+
+			respond := tc.respond
+			if respond == 0 {
+				respond = http.StatusOK
+			}
+
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.Equal(t, payload, body)
+
+				require.Equal(t, delivery.EventID.String(), r.Header.Get(HeaderEventID))
+				require.Equal(t, delivery.EventType, r.Header.Get(HeaderEvent))
+				require.Equal(t, strconv.FormatInt(now.Unix(), 10), r.Header.Get(HeaderTimestamp))
+				require.Equal(t, Sign("secret", now, payload), r.Header.Get(HeaderSignature))
+
+				w.WriteHeader(respond)
+			}))
+			defer receiver.Close()
+			delivery.Url = receiver.URL
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, delivery)
+
+			worker := NewWorker(store)
+			worker.now = func() time.Time { return now }
+			// the receiver listens on loopback, which the default client refuses
+			worker.client = newClient(DefaultTimeout, func(net.IP) bool { return true })
+
+			sent, err := worker.RunOnce(context.Background())
+			tc.checkSent(t, sent, err)
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+	payload := []byte(`{"id":1}`)
+
+	signature := Sign("secret", timestamp, payload)
+	require.Equal(t, "sha256=3dd1b9aef568d75f6790a84bd2e5dfa1f44409eef3cbdbd3f10b837376100c11", signature)
+	require.NotEqual(t, signature, Sign("other", timestamp, payload))
+	require.NotEqual(t, signature, Sign("secret", timestamp.Add(time.Second), payload))
+}
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, 30*time.Second, Backoff(1))
+	require.Equal(t, time.Minute, Backoff(2))
+	require.Equal(t, 8*time.Minute, Backoff(5))
+	require.Equal(t, 256*time.Minute, Backoff(10))
+	require.Equal(t, maxBackoff, Backoff(100))
+}